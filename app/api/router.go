@@ -3,8 +3,12 @@ package api
 import (
     "fmt"
     "net/http"
+    "regexp"
+    "sort"
 
     "store-api/internal/base/handler"
+
+    "github.com/gorilla/mux"
 )
 
 func (h *HttpServe) setupRouter() {
@@ -15,14 +19,72 @@ func (h *HttpServe) setupRouter() {
     h.Route("POST", "/cart/add", h.store.AddToCart)
     h.Route("POST", "/cart/view", h.store.ViewCart)
     h.Route("POST", "/cart/delete", h.store.DeleteProductInCart)
+    h.Route("POST", "/cart/move-to-wishlist", h.store.MoveCartToWishlist)
+    h.Route("POST", "/wishlist/add", h.store.AddWishlist)
+    h.Route("POST", "/wishlist/view", h.store.ListWishlist)
+    h.Route("POST", "/wishlist/delete", h.store.DeleteWishlist)
+    h.Route("POST", "/wishlist/move-to-cart", h.store.MoveWishlistToCart)
     h.Route("POST", "/transaction/create", h.store.CreateTransaction)
     h.Route("POST", "/login", h.store.Login)
+    h.Route("POST", "/media/upload-url", h.store.CreateUploadURL)
+    h.Route("POST", "/media/download-url", h.store.CreateDownloadURL)
+    h.Route("POST", "/device/register", h.store.RegisterDevice)
+    h.Route("POST", "/device/unregister", h.store.UnregisterDevice)
+    h.Route("POST", "/notification/opt-out", h.store.SetNotificationOptOut)
+
+    // Resource style route, member from session. httpStatus is the same as status field
+    h.v2 = h.router.PathPrefix("/api/v2/").Subrouter()
+
+    h.RouteV2("GET", "/products", h.store.GetProducts)
+    h.RouteV2("GET", "/products/{id:[0-9]+}", h.store.GetProduct)
+    h.RouteV2("GET", "/cart", h.store.GetCart)
+    h.RouteV2("POST", "/cart/items", h.store.AddCartItem)
+    h.RouteV2("DELETE", "/cart/items/{productId:[0-9]+}", h.store.DeleteCartItem)
+    h.RouteV2("POST", "/orders", h.store.CreateOrder)
+
+    // Admin route, web response format, require X-Admin-Key header
+    h.admin = h.router.PathPrefix("/api/web/admin/").Subrouter()
 
-    // assign method not allowed handler
-    h.v1.MethodNotAllowedHandler = h.base.MethodNotAllowedHandler()
+    h.AdminRoute("POST", "/product/image/upload", h.store.UploadProductImage)
+    h.AdminRoute("POST", "/product/image/reorder", h.store.ReorderProductImage)
+    h.AdminRoute("POST", "/product/image/delete", h.store.DeleteProductImage)
+    h.AdminRoute("POST", "/product/import", h.store.ImportProduct)
+    h.AdminRoute("GET", "/product/export", h.store.ExportProduct)
+    h.AdminRoute("POST", "/product/stock/history", h.store.StockHistory)
+    h.AdminRoute("POST", "/product/stock/adjust", h.store.AdjustStock)
+    h.AdminRoute("POST", "/product/stock/threshold", h.store.SetReorderThreshold)
+
+    // Load balancer and orchestrator probe, outside the api prefix and without RunAction
+    h.ProbeRoute("/healthz", h.health.LivenessHandler())
+    h.ProbeRoute("/readyz", h.health.ReadinessHandler())
+
+    // assign method not allowed handler, it also answer OPTIONS request
+    notAllowed := h.base.MethodNotAllowedHandler(h.methods.Allowed)
+    h.v1.MethodNotAllowedHandler = notAllowed
+    h.v2.MethodNotAllowedHandler = notAllowed
+    h.admin.MethodNotAllowedHandler = notAllowed
+    h.router.MethodNotAllowedHandler = notAllowed
+    h.router.NotFoundHandler = h.base.NotFoundHandler()
 }
 
 func (h *HttpServe) Route(method string, path string, f handler.HandlerFn) {
+    h.handle(h.v1, method, path, h.base.RunAction(f))
+}
+
+func (h *HttpServe) RouteV2(method string, path string, f handler.HandlerFn) {
+    h.handle(h.v2, method, path, h.base.RunAction(f))
+}
+
+func (h *HttpServe) AdminRoute(method string, path string, f handler.HandlerFn) {
+    h.handle(h.admin, method, path, h.base.RunAction(h.base.RequireAdmin(f)))
+}
+
+// ProbeRoute GET route on the root router, the handler write its own response
+func (h *HttpServe) ProbeRoute(path string, f http.Handler) {
+    h.handle(h.router, http.MethodGet, path, f.ServeHTTP)
+}
+
+func (h *HttpServe) handle(router *mux.Router, method string, path string, f http.HandlerFunc) {
     if method != http.MethodGet &&
             method != http.MethodPost &&
             method != http.MethodDelete &&
@@ -30,5 +92,45 @@ func (h *HttpServe) Route(method string, path string, f handler.HandlerFn) {
         panic(fmt.Sprintf(":%s method not allow", method))
     }
 
-    h.v1.HandleFunc(path, h.base.RunAction(f)).Methods(method)
+    route := router.HandleFunc(path, f).Methods(method)
+    pathRegexp, err := route.GetPathRegexp()
+    if err != nil {
+        panic(fmt.Sprintf(":%s %v", path, err))
+    }
+    h.methods.add(pathRegexp, method)
+}
+
+// routeMethods registered methods of each route path, for Allow header of 405 and OPTIONS response
+type routeMethods struct {
+    paths   []*regexp.Regexp
+    methods map[string][]string // Path regexp: methods
+}
+
+func (m *routeMethods) add(pathRegexp string, method string) {
+    if m.methods == nil {
+        m.methods = make(map[string][]string)
+    }
+    if _, exists := m.methods[pathRegexp]; !exists {
+        m.paths = append(m.paths, regexp.MustCompile(pathRegexp))
+    }
+    m.methods[pathRegexp] = append(m.methods[pathRegexp], method)
+}
+
+// Allowed methods of the request path, sorted and OPTIONS included
+func (m *routeMethods) Allowed(r *http.Request) []string {
+    unique := map[string]bool{http.MethodOptions: true}
+    for _, path := range m.paths {
+        if path.MatchString(r.URL.Path) {
+            for _, method := range m.methods[path.String()] {
+                unique[method] = true
+            }
+        }
+    }
+
+    allowed := make([]string, 0, len(unique))
+    for method := range unique {
+        allowed = append(allowed, method)
+    }
+    sort.Strings(allowed)
+    return allowed
 }
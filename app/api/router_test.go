@@ -0,0 +1,142 @@
+package api
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "store-api/internal/base/handler"
+    storeModule "store-api/internal/store/handler"
+    "store-api/pkg/metric"
+    "store-api/pkg/server"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func newTestServe() *HttpServe {
+    h := New("store-api-test", Config{}, &handler.BaseHTTPHandler{}, &storeModule.HTTPHandler{}).(*HttpServe)
+    h.setupRouter()
+    return h
+}
+
+func TestRouter_MethodNotAllowed(t *testing.T) {
+    h := newTestServe()
+
+    tests := []struct {
+        name   string
+        method string
+        path   string
+        allow  string
+    }{
+        {"v1 post route", http.MethodGet, "/api/v1/product/list", "OPTIONS, POST"},
+        {"v2 route with path var", http.MethodPut, "/api/v2/cart/items/12", "DELETE, OPTIONS"},
+        {"v2 route", http.MethodGet, "/api/v2/orders", "OPTIONS, POST"},
+        {"admin route", http.MethodPost, "/api/web/admin/product/export", "GET, OPTIONS"},
+        {"liveness probe", http.MethodPost, "/healthz", "GET, OPTIONS"},
+        {"readiness probe", http.MethodDelete, "/readyz", "GET, OPTIONS"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            rw := httptest.NewRecorder()
+            h.router.ServeHTTP(rw, httptest.NewRequest(tt.method, tt.path, nil))
+
+            assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+            assert.Equal(t, tt.allow, rw.Header().Get("Allow"))
+
+            body := server.NotAllowedMethod{}
+            assert.Nil(t, json.Unmarshal(rw.Body.Bytes(), &body))
+            assert.Equal(t, http.StatusMethodNotAllowed, body.Status)
+            assert.Contains(t, body.Message, tt.allow)
+        })
+    }
+}
+
+func TestRouter_Options(t *testing.T) {
+    h := newTestServe()
+
+    rw := httptest.NewRecorder()
+    h.router.ServeHTTP(rw, httptest.NewRequest(http.MethodOptions, "/api/v2/products", nil))
+
+    assert.Equal(t, http.StatusNoContent, rw.Code)
+    assert.Equal(t, "GET, OPTIONS", rw.Header().Get("Allow"))
+    assert.Empty(t, rw.Body.String())
+}
+
+func TestRouter_NotFound(t *testing.T) {
+    h := newTestServe()
+
+    for _, path := range []string{"/api/v1/unknown", "/api/v2/products/abc", "/unknown"} {
+        rw := httptest.NewRecorder()
+        h.router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+
+        assert.Equal(t, http.StatusNotFound, rw.Code, path)
+
+        body := server.NotAllowedMethod{}
+        assert.Nil(t, json.Unmarshal(rw.Body.Bytes(), &body))
+        assert.Equal(t, http.StatusNotFound, body.Status)
+    }
+}
+
+func TestRouter_Health(t *testing.T) {
+    h := newTestServe()
+
+    for _, path := range []string{"/healthz", "/readyz"} {
+        rw := httptest.NewRecorder()
+        h.router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, path, nil))
+
+        assert.Equal(t, http.StatusOK, rw.Code, path)
+        assert.JSONEq(t, `{"status":"up","checks":{}}`, rw.Body.String(), path)
+    }
+}
+
+func TestRouter_Metrics(t *testing.T) {
+    assert.Nil(t, newTestServe().metrics, "No listener without prometheus")
+
+    monitoring := metric.NewPrometheusMonitoring("store")
+    base := &handler.BaseHTTPHandler{StatsdMonitoring: monitoring}
+    h := New("store-api-test", Config{Metrics: monitoring.Handler()}, base, &storeModule.HTTPHandler{App: base}).(*HttpServe)
+    h.setupRouter()
+    assert.Equal(t, ":"+DefaultMetricsPort, h.metrics.Addr)
+
+    r := httptest.NewRequest(http.MethodPost, "/api/v2/orders", strings.NewReader(`{}`)) // v2 require session, 401
+    r.Header.Set("Content-Type", "application/json")
+    h.router.ServeHTTP(httptest.NewRecorder(), r)
+
+    rw := httptest.NewRecorder()
+    h.router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+    assert.Equal(t, http.StatusNotFound, rw.Code, "Not served on the public port")
+
+    rw = httptest.NewRecorder()
+    h.metrics.Handler.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+    assert.Equal(t, http.StatusOK, rw.Code)
+    assert.Contains(t, rw.Body.String(), `store_http_request_total{method="POST",route="/api/v2/orders",status="401"} 1`)
+    assert.Contains(t, rw.Body.String(), `store_http_request_latency_seconds_count{method="POST",route="/api/v2/orders",status="401"} 1`)
+}
+
+func TestRouter_InvalidToken(t *testing.T) {
+    h := newTestServe()
+
+    // Guest route, the token is not ignored
+    for _, route := range [][2]string{{http.MethodPost, "/api/v1/product/list"}, {http.MethodGet, "/api/v2/products"}} {
+        path := route[1]
+        r := httptest.NewRequest(route[0], path, strings.NewReader(`{}`))
+        r.Header.Set("Content-Type", "application/json")
+        r.Header.Set("Authorization", "Bearer expired-token")
+        rw := httptest.NewRecorder()
+        h.router.ServeHTTP(rw, r)
+
+        assert.Equal(t, http.StatusUnauthorized, rw.Code, path)
+
+        body := server.MobileResponse{}
+        assert.Nil(t, json.Unmarshal(rw.Body.Bytes(), &body), path)
+        assert.Equal(t, http.StatusUnauthorized, body.Status, path)
+    }
+
+    // Next request without token still run the handler as guest
+    rw := httptest.NewRecorder()
+    h.router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/v2/products", nil))
+    assert.NotEqual(t, http.StatusUnauthorized, rw.Code)
+}
@@ -1,45 +1,164 @@
 package api
 
 import (
+    "context"
     "fmt"
     "net/http"
     "os"
+    "time"
 
     storeModule "store-api/internal/store/handler"
 
     "store-api/internal/base/handler"
+    "store-api/pkg/health"
+    "store-api/pkg/middleware"
     "store-api/pkg/server"
 
     "github.com/gorilla/mux"
-    muxtrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/gorilla/mux"
+    "github.com/sirupsen/logrus"
 )
 
+// Default timeout of http.Server, see Config
+const (
+    DefaultReadTimeout       = 15 * time.Second
+    DefaultReadHeaderTimeout = 5 * time.Second
+    DefaultWriteTimeout      = 60 * time.Second // Product export is streamed in one response
+    DefaultIdleTimeout       = 120 * time.Second
+
+    DefaultMetricsPort = "9090"
+)
+
+// Config timeout of http.Server, zero use the default
+type Config struct {
+    ReadTimeout       time.Duration
+    ReadHeaderTimeout time.Duration
+    WriteTimeout      time.Duration
+    IdleTimeout       time.Duration
+
+    Health      *health.Health // Dependency checks of /readyz, nil has no check
+    AccessLog   *logrus.Logger // Request log, nil use the logrus standard logger
+    Metrics     http.Handler   // Prometheus /metrics, nil has no listener
+    MetricsPort string         // Internal listener of /metrics, apart from the public port. DefaultMetricsPort when empty
+}
+
 // HttpServe is a http server implementation
 type HttpServe struct {
-    router *muxtrace.Router
+    router *mux.Router
+    server *http.Server
 
     base  *handler.BaseHTTPHandler
     store *storeModule.HTTPHandler
 
-    v1 *mux.Router
+    v1    *mux.Router
+    v2    *mux.Router
+    admin *mux.Router
+
+    methods routeMethods // Registered methods of each path
+    health  *health.Health
+    log     *logrus.Logger
+    metrics *http.Server // Nil without /metrics
 }
 
 //Run runs the HTTP server application
 func (h *HttpServe) Run() error {
     h.setupRouter()
     h.base.Handlers = h
+    // Span of the matched route, the service name is set by the tracer
+    h.router.Use(middleware.Tracing())
 
-    return http.ListenAndServe(fmt.Sprintf(":%s", os.Getenv("HTTP_SERVER_PORT")), h.router)
+    corsConfig, err := h.corsConfig()
+    if err != nil {
+        return err
+    }
+
+    // Request log is the outermost, the request rejected by CORS is logged with its request ID
+    cors := middleware.CORS(corsConfig)
+    requestLogger := middleware.RequestLogger(middleware.LoggingConfigFromParams(h.base.Params, middleware.DefaultLoggingConfig()), h.log)
+    h.server.Handler = requestLogger(cors(h.router))
+
+    if h.metrics != nil {
+        go func() {
+            // Metric is not worth stopping the API, the scrape failure is alerted by prometheus
+            if err := h.metrics.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+                h.log.Errorln("Metrics server", err)
+            }
+        }()
+    }
+
+    err = h.server.ListenAndServe()
+    if err == http.ErrServerClosed {
+        return nil // Shutdown is called
+    }
+    return err
+}
+
+// Shutdown stop accepting request and wait for the request in progress until ctx is done
+func (h *HttpServe) Shutdown(ctx context.Context) error {
+    if h.metrics != nil {
+        if err := h.metrics.Shutdown(ctx); err != nil {
+            h.log.Errorln("Shutdown metrics server", err)
+        }
+    }
+    return h.server.Shutdown(ctx)
+}
+
+// corsConfig CORS_* env override the defaults, any origin is allowed on development and none on production.
+// Outside development, including unknown APP_ENV, the origins must be listed.
+func (h *HttpServe) corsConfig() (middleware.CORSConfig, error) {
+    development := h.base.IsStaging() && !h.base.IsProd()
+    defaults := middleware.ProductionCORSConfig()
+    if development {
+        defaults = middleware.DevelopmentCORSConfig()
+    }
+    config := middleware.CORSConfigFromParams(h.base.Params, defaults)
+    return config, config.Validate(development)
 }
 
 //New creates new API server application
 func New(appName string,
+        config Config,
         base *handler.BaseHTTPHandler,
         store *storeModule.HTTPHandler,
 ) server.App {
+    if config.Health == nil {
+        config.Health = health.New()
+    }
+    if config.AccessLog == nil {
+        config.AccessLog = logrus.StandardLogger()
+    }
+    var metrics *http.Server
+    if config.Metrics != nil {
+        if config.MetricsPort == "" {
+            config.MetricsPort = DefaultMetricsPort
+        }
+        metricsRouter := http.NewServeMux()
+        metricsRouter.Handle("/metrics", config.Metrics)
+        metrics = &http.Server{
+            Addr:              fmt.Sprintf(":%s", config.MetricsPort),
+            Handler:           metricsRouter,
+            ReadHeaderTimeout: DefaultReadHeaderTimeout,
+        }
+    }
     return &HttpServe{
-        base:   base,
-        store:  store,
-        router: muxtrace.NewRouter(muxtrace.WithServiceName(appName)),
+        base:    base,
+        store:   store,
+        health:  config.Health,
+        log:     config.AccessLog,
+        metrics: metrics,
+        router:  mux.NewRouter(),
+        server:  &http.Server{
+            Addr:              fmt.Sprintf(":%s", os.Getenv("HTTP_SERVER_PORT")),
+            ReadTimeout:       durationOrDefault(config.ReadTimeout, DefaultReadTimeout),
+            ReadHeaderTimeout: durationOrDefault(config.ReadHeaderTimeout, DefaultReadHeaderTimeout),
+            WriteTimeout:      durationOrDefault(config.WriteTimeout, DefaultWriteTimeout),
+            IdleTimeout:       durationOrDefault(config.IdleTimeout, DefaultIdleTimeout),
+        },
+    }
+}
+
+func durationOrDefault(value, defaultValue time.Duration) time.Duration {
+    if value <= 0 {
+        return defaultValue
     }
+    return value
 }
@@ -0,0 +1,69 @@
+package api
+
+import (
+    "context"
+    "net/http"
+    "testing"
+    "time"
+
+    "store-api/internal/base/handler"
+    storeModule "store-api/internal/store/handler"
+    "store-api/pkg/middleware"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestHttpServe_Shutdown(t *testing.T) {
+    t.Setenv("HTTP_SERVER_PORT", "0")
+    app := New("store-api-test", Config{WriteTimeout: 5 * time.Second, Metrics: http.NotFoundHandler(), MetricsPort: "0"},
+        &handler.BaseHTTPHandler{}, &storeModule.HTTPHandler{})
+
+    h := app.(*HttpServe)
+    assert.Equal(t, DefaultReadTimeout, h.server.ReadTimeout)
+    assert.Equal(t, 5*time.Second, h.server.WriteTimeout)
+
+    echan := make(chan error, 1)
+    go func() {
+        echan <- app.Run()
+    }()
+    time.Sleep(50 * time.Millisecond)
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+    defer cancel()
+    assert.Nil(t, app.Shutdown(ctx))
+
+    select {
+    case err := <-echan:
+        assert.Nil(t, err)
+    case <-time.After(time.Second):
+        t.Fatal("Run is not stopped by Shutdown")
+    }
+}
+
+func TestHttpServe_CORSConfig(t *testing.T) {
+    tests := []struct {
+        name    string
+        params  map[string]string
+        origins []string
+        isErr   bool
+    }{
+        {"Any origin on development", map[string]string{"APP_ENV": "development"}, []string{"*"}, false},
+        {"No origin on production", map[string]string{"APP_ENV": "production"}, nil, false},
+        {"Listed origin on production", map[string]string{"APP_ENV": "production",
+            middleware.ParamCORSAllowedOrigins: "https://shop.example.com"}, []string{"https://shop.example.com"}, false},
+        {"Any origin on production", map[string]string{"APP_ENV": "production",
+            middleware.ParamCORSAllowedOrigins: "*"}, []string{"*"}, true},
+        {"Any origin on unknown env", map[string]string{middleware.ParamCORSAllowedOrigins: "*"}, []string{"*"}, true},
+        {"Credentials with any origin on development", map[string]string{"APP_ENV": "development",
+            middleware.ParamCORSAllowCredentials: "true"}, []string{"*"}, true},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            h := New("store-api-test", Config{}, &handler.BaseHTTPHandler{Params: tt.params}, &storeModule.HTTPHandler{}).(*HttpServe)
+
+            config, err := h.corsConfig()
+            assert.Equal(t, tt.isErr, err != nil)
+            assert.Equal(t, tt.origins, config.AllowedOrigins)
+        })
+    }
+}
@@ -0,0 +1,289 @@
+package consumer
+
+import (
+    "context"
+    "errors"
+    "strconv"
+    "sync"
+    "time"
+
+    "store-api/pkg/messaging"
+    "store-api/pkg/server"
+    "store-api/pkg/tracing"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    DefaultConcurrency = 1
+    DefaultMaxRetry    = 3
+    DefaultBackoff     = time.Second
+    DefaultMaxBackoff  = 30 * time.Second
+
+    DeadLetterSuffix = ".dlq"
+
+    HeaderError     = "x-error"
+    HeaderTopic     = "x-original-topic"
+    HeaderPartition = "x-original-partition"
+    HeaderOffset    = "x-original-offset"
+    HeaderAttempts  = "x-attempts"
+)
+
+// Handler process one message. Error is retried with backoff, then the message goes to the dead letter topic.
+type Handler func(ctx context.Context, msg kafka.Message) error
+
+// Reader subset of kafka.Reader used by the consumer
+type Reader interface {
+    FetchMessage(ctx context.Context) (kafka.Message, error)
+    CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+    Close() error
+}
+
+// Writer subset of kafka.Writer used to write dead letter
+type Writer interface {
+    WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+    Close() error
+}
+
+type Config struct {
+    Brokers     []string
+    GroupID     string
+    Concurrency int           // Group member per topic, each member process its partitions one message at a time
+    MaxRetry    int           // Retry after the first attempt
+    Backoff     time.Duration // First retry delay, doubled on every retry
+    MaxBackoff  time.Duration
+}
+
+type permanentError struct {
+    err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent wrap error which must not be retried, ex: invalid payload. Message goes to dead letter directly.
+func Permanent(err error) error {
+    return permanentError{err: err}
+}
+
+// ConsumerServe is a kafka consumer implementation, every registered topic is consumed by Config.Concurrency
+// members of the consumer group. Offset is committed once message is handled or sent to dead letter.
+type ConsumerServe struct {
+    config   Config
+    handlers map[string]Handler
+
+    newReader  func(topic string) Reader
+    deadLetter Writer
+
+    ctx    context.Context
+    cancel context.CancelFunc
+    wg     sync.WaitGroup
+}
+
+// New creates new consumer application
+func New(config Config) *ConsumerServe {
+    if config.Concurrency <= 0 {
+        config.Concurrency = DefaultConcurrency
+    }
+    if config.MaxRetry < 0 {
+        config.MaxRetry = 0
+    }
+    if config.Backoff <= 0 {
+        config.Backoff = DefaultBackoff
+    }
+    if config.MaxBackoff < config.Backoff {
+        config.MaxBackoff = DefaultMaxBackoff
+    }
+
+    c := &ConsumerServe{
+        config:   config,
+        handlers: make(map[string]Handler),
+        newReader: func(topic string) Reader {
+            return kafka.NewReader(kafka.ReaderConfig{
+                Brokers: config.Brokers,
+                GroupID: config.GroupID,
+                Topic:   topic,
+            })
+        },
+        deadLetter: &kafka.Writer{
+            Addr:     kafka.TCP(config.Brokers...),
+            Balancer: &kafka.Hash{},
+        },
+    }
+    c.ctx, c.cancel = context.WithCancel(context.Background())
+    return c
+}
+
+var _ server.App = (*ConsumerServe)(nil)
+
+// Register handler of the topic, must be called before Run
+func (c *ConsumerServe) Register(topic string, handler Handler) {
+    c.handlers[topic] = handler
+}
+
+// Run consume every registered topic until Close is called
+func (c *ConsumerServe) Run() error {
+    if len(c.handlers) == 0 {
+        return errors.New("consumer has no handler")
+    }
+
+    for topic, handler := range c.handlers {
+        for i := 0; i < c.config.Concurrency; i++ {
+            c.wg.Add(1)
+            go func(topic string, handler Handler) {
+                defer c.wg.Done()
+                c.consume(topic, handler)
+            }(topic, handler)
+        }
+        logrus.Infof("Consume topic %s, group %s, concurrency %d", topic, c.config.GroupID, c.config.Concurrency)
+    }
+
+    c.wg.Wait()
+    return nil
+}
+
+// Close stop fetching, wait for message in progress then close the dead letter writer.
+// Message whose retry is interrupted is not committed, it is consumed again after restart.
+func (c *ConsumerServe) Close() error {
+    c.cancel()
+    c.wg.Wait()
+    return c.deadLetter.Close()
+}
+
+// Shutdown Close, return ctx error when message in progress is not finished before ctx is done
+func (c *ConsumerServe) Shutdown(ctx context.Context) error {
+    done := make(chan error, 1)
+    go func() {
+        done <- c.Close()
+    }()
+
+    select {
+    case err := <-done:
+        return err
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+func (c *ConsumerServe) consume(topic string, handler Handler) {
+    reader := c.newReader(topic)
+    defer reader.Close()
+
+    for {
+        msg, err := reader.FetchMessage(c.ctx)
+        if err != nil {
+            if c.ctx.Err() != nil {
+                return
+            }
+            logrus.Errorln("Consumer: fetch", topic, err)
+            if !c.sleep(c.config.Backoff) {
+                return
+            }
+            continue
+        }
+
+        if !c.process(handler, msg) {
+            return
+        }
+
+        // Commit is retried by the next message of the partition when it fails
+        if err = reader.CommitMessages(c.ctx, msg); err != nil && c.ctx.Err() == nil {
+            logrus.Errorln("Consumer: commit", topic, msg.Partition, msg.Offset, err)
+        }
+    }
+}
+
+// process return false when it is interrupted by Close before the message is handled or sent to dead letter
+func (c *ConsumerServe) process(handler Handler, msg kafka.Message) bool {
+    var (
+        err      error
+        attempts int
+        delay    = c.config.Backoff
+    )
+    // Span is child of the publish span propagated in the message headers
+    span, ctx := tracing.StartSpan(tracing.Extract(context.Background(), messaging.MessageCarrier{Message: &msg}),
+        "kafka.consume", tracing.WithKind(tracing.KindConsumer), tracing.WithResource(msg.Topic),
+        tracing.WithTag(tracing.TagMessagingTopic, msg.Topic), tracing.WithTag("messaging.kafka.partition", msg.Partition))
+    defer func() {
+        span.SetTag("attempts", attempts)
+        span.Finish()
+    }()
+
+    for {
+        attempts++
+        // Handler is not cancelled by Close, message in progress is finished
+        err = handler(ctx, msg)
+        if err == nil {
+            return true
+        }
+
+        var permanent permanentError
+        if errors.As(err, &permanent) || attempts > c.config.MaxRetry {
+            break
+        }
+
+        logrus.Warnln("Consumer: retry", msg.Topic, msg.Partition, msg.Offset, "attempt", attempts, err)
+        if !c.sleep(delay) {
+            return false
+        }
+        delay *= 2
+        if delay > c.config.MaxBackoff {
+            delay = c.config.MaxBackoff
+        }
+    }
+
+    span.SetError(err)
+    logrus.Errorln("Consumer: dead letter", msg.Topic, msg.Partition, msg.Offset, "attempt", attempts, err)
+    return c.writeDeadLetter(msg, err, attempts)
+}
+
+// writeDeadLetter retry until the message is written, message must not be lost
+func (c *ConsumerServe) writeDeadLetter(msg kafka.Message, cause error, attempts int) bool {
+    headers := append([]kafka.Header{}, msg.Headers...)
+    headers = append(headers,
+        kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+        kafka.Header{Key: HeaderTopic, Value: []byte(msg.Topic)},
+        kafka.Header{Key: HeaderPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+        kafka.Header{Key: HeaderOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+        kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+    )
+    deadLetter := kafka.Message{
+        Topic:   msg.Topic + DeadLetterSuffix,
+        Key:     msg.Key,
+        Value:   msg.Value,
+        Headers: headers,
+    }
+
+    delay := c.config.Backoff
+    for {
+        err := c.deadLetter.WriteMessages(c.ctx, deadLetter)
+        if err == nil {
+            return true
+        }
+        if c.ctx.Err() != nil {
+            return false
+        }
+
+        logrus.Errorln("Consumer: write dead letter", deadLetter.Topic, err)
+        if !c.sleep(delay) {
+            return false
+        }
+        if delay *= 2; delay > c.config.MaxBackoff {
+            delay = c.config.MaxBackoff
+        }
+    }
+}
+
+// sleep return false when consumer is closed before the delay
+func (c *ConsumerServe) sleep(delay time.Duration) bool {
+    timer := time.NewTimer(delay)
+    defer timer.Stop()
+
+    select {
+    case <-c.ctx.Done():
+        return false
+    case <-timer.C:
+        return true
+    }
+}
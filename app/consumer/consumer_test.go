@@ -0,0 +1,217 @@
+package consumer
+
+import (
+    "context"
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "store-api/pkg/messaging"
+    "store-api/pkg/tracing"
+
+    "github.com/segmentio/kafka-go"
+    "github.com/stretchr/testify/assert"
+)
+
+type fakeReader struct {
+    mu        sync.Mutex
+    messages  chan kafka.Message
+    committed []int64
+    closed    bool
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+    select {
+    case <-ctx.Done():
+        return kafka.Message{}, ctx.Err()
+    case msg := <-r.messages:
+        return msg, nil
+    }
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    for _, msg := range msgs {
+        r.committed = append(r.committed, msg.Offset)
+    }
+    return nil
+}
+
+func (r *fakeReader) Close() error {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    r.closed = true
+    return nil
+}
+
+func (r *fakeReader) Committed() []int64 {
+    r.mu.Lock()
+    defer r.mu.Unlock()
+    return append([]int64{}, r.committed...)
+}
+
+type fakeWriter struct {
+    mu       sync.Mutex
+    messages []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    w.messages = append(w.messages, msgs...)
+    return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func newTestConsumer(config Config, messages ...kafka.Message) (*ConsumerServe, *fakeReader, *fakeWriter) {
+    reader := &fakeReader{messages: make(chan kafka.Message, len(messages))}
+    for _, msg := range messages {
+        reader.messages <- msg
+    }
+    writer := &fakeWriter{}
+
+    c := New(config)
+    c.newReader = func(topic string) Reader { return reader }
+    c.deadLetter = writer
+    return c, reader, writer
+}
+
+// runUntil run the consumer until offsets are committed, then close it
+func runUntil(t *testing.T, c *ConsumerServe, reader *fakeReader, committed int) {
+    done := make(chan error, 1)
+    go func() { done <- c.Run() }()
+
+    assert.Eventually(t, func() bool { return len(reader.Committed()) == committed }, time.Second, time.Millisecond)
+    assert.Nil(t, c.Close())
+    assert.Nil(t, <-done)
+    assert.True(t, reader.closed)
+}
+
+func TestConsumerServe_Run(t *testing.T) {
+    config := Config{MaxRetry: 2, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+
+    t.Run("Handle and commit", func(t *testing.T) {
+        c, reader, writer := newTestConsumer(config,
+            kafka.Message{Topic: "store.payments", Offset: 1}, kafka.Message{Topic: "store.payments", Offset: 2})
+
+        var handled []int64
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            handled = append(handled, msg.Offset)
+            return nil
+        })
+
+        runUntil(t, c, reader, 2)
+        assert.Equal(t, []int64{1, 2}, handled)
+        assert.Equal(t, []int64{1, 2}, reader.Committed())
+        assert.Len(t, writer.messages, 0)
+    })
+
+    t.Run("Retry then succeed", func(t *testing.T) {
+        c, reader, writer := newTestConsumer(config, kafka.Message{Topic: "store.payments", Offset: 1})
+
+        attempts := 0
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            attempts++
+            if attempts < 3 {
+                return errors.New("database down")
+            }
+            return nil
+        })
+
+        runUntil(t, c, reader, 1)
+        assert.Equal(t, 3, attempts)
+        assert.Len(t, writer.messages, 0)
+    })
+
+    t.Run("Dead letter after max retry", func(t *testing.T) {
+        c, reader, writer := newTestConsumer(config,
+            kafka.Message{Topic: "store.payments", Partition: 2, Offset: 7, Key: []byte("TRX-1"), Value: []byte(`{}`)})
+
+        attempts := 0
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            attempts++
+            return errors.New("database down")
+        })
+
+        runUntil(t, c, reader, 1)
+        assert.Equal(t, 3, attempts)
+        assert.Len(t, writer.messages, 1)
+
+        deadLetter := writer.messages[0]
+        assert.Equal(t, "store.payments.dlq", deadLetter.Topic)
+        assert.Equal(t, []byte("TRX-1"), deadLetter.Key)
+        assert.Equal(t, []kafka.Header{
+            {Key: HeaderError, Value: []byte("database down")},
+            {Key: HeaderTopic, Value: []byte("store.payments")},
+            {Key: HeaderPartition, Value: []byte("2")},
+            {Key: HeaderOffset, Value: []byte("7")},
+            {Key: HeaderAttempts, Value: []byte("3")},
+        }, deadLetter.Headers)
+    })
+
+    t.Run("Permanent error is not retried", func(t *testing.T) {
+        c, reader, writer := newTestConsumer(config, kafka.Message{Topic: "store.payments", Offset: 1})
+
+        attempts := 0
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            attempts++
+            return Permanent(errors.New("invalid payload"))
+        })
+
+        runUntil(t, c, reader, 1)
+        assert.Equal(t, 1, attempts)
+        assert.Len(t, writer.messages, 1)
+    })
+
+    t.Run("Close interrupt retry without commit", func(t *testing.T) {
+        c, reader, _ := newTestConsumer(Config{MaxRetry: 5, Backoff: time.Hour}, kafka.Message{Topic: "store.payments", Offset: 1})
+
+        handled := make(chan struct{}, 1)
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            handled <- struct{}{}
+            return errors.New("database down")
+        })
+
+        done := make(chan error, 1)
+        go func() { done <- c.Run() }()
+        <-handled
+
+        assert.Nil(t, c.Close())
+        assert.Nil(t, <-done)
+        assert.Len(t, reader.Committed(), 0)
+    })
+
+    t.Run("Span child of the publish span", func(t *testing.T) {
+        tracer := tracing.NewMemoryTracer()
+        tracing.SetTracer(tracer)
+        defer tracing.SetTracer(nil)
+
+        msg := kafka.Message{Topic: "store.payments", Offset: 1}
+        publish, ctx := tracing.StartSpan(context.Background(), "kafka.publish")
+        tracing.Inject(ctx, messaging.MessageCarrier{Message: &msg})
+        publish.Finish()
+
+        c, reader, _ := newTestConsumer(config, msg)
+        var traceID string
+        c.Register("store.payments", func(ctx context.Context, msg kafka.Message) error {
+            traceID = tracing.TraceID(ctx)
+            return nil
+        })
+
+        runUntil(t, c, reader, 1)
+        assert.Equal(t, publish.TraceID(), traceID)
+        spans := tracer.Spans("kafka.consume")
+        assert.Len(t, spans, 1)
+        assert.Equal(t, tracing.KindConsumer, spans[0].Kind)
+        assert.Equal(t, "store.payments", spans[0].Resource)
+        assert.Equal(t, tracer.Spans("kafka.publish")[0].SpanID, spans[0].ParentID)
+    })
+
+    t.Run("No handler", func(t *testing.T) {
+        c, _, _ := newTestConsumer(config)
+        assert.NotNil(t, c.Run())
+    })
+}
@@ -1,30 +1,48 @@
 package cmd
 
 import (
+    "context"
     "flag"
     "fmt"
     "io"
+    "net"
+    "net/http"
     "os"
     "strconv"
+    "time"
 
     storeModule "store-api/internal/store/handler"
     storeRepo "store-api/internal/store/repository"
     storeService "store-api/internal/store/service"
 
+    firebase "firebase.google.com/go"
+    "google.golang.org/api/option"
     "gopkg.in/Graylog2/go-gelf.v2/gelf"
 
     fcmToken "store-api/internal/base/service/firebase"
+    "store-api/internal/base/service/notification"
     cache "store-api/internal/base/service/redisser"
 
+    "github.com/go-redis/redis/v8"
     gelfFormatter "github.com/seatgeek/logrus-gelf-formatter"
     "github.com/sirupsen/logrus"
     "github.com/spf13/cast"
 
     "store-api/internal/base/handler"
 
+    "store-api/pkg/awsutil"
     "store-api/pkg/db"
     "store-api/pkg/httpclient"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/flockhook"
     "store-api/pkg/metric"
+    "store-api/pkg/tracing"
+)
+
+const (
+    defaultMetricNamespace = "store"
+    defaultDatadogPort     = "8126"
+    tracingShutdownTimeout = 5 * time.Second
 )
 
 var (
@@ -38,6 +56,11 @@ var (
     redisClient      cache.RedisClient
     firebaseClient   fcmToken.FirebaseClient
     statsdMonitoring metric.StatsdMonitoring
+    awsService       *awsutil.AWSService
+    kafkaPublisher   messaging.KafkaPublisher
+    notifier         *notification.Dispatcher
+    accessLogger     *logrus.Logger
+    metricsHandler   http.Handler // Prometheus /metrics, nil with statsd backend
 )
 
 func initMySQL() {
@@ -50,11 +73,248 @@ func initMySQL() {
     mysqlClientRepo, _ = db.NewMySQLRepository(host, uname, pass, dbname, port)
 }
 
+// initRedis REDIS_ADDR host:port, redis is disabled when it is empty
+func initRedis() {
+    addr := os.Getenv("REDIS_ADDR")
+    if addr == "" {
+        logrus.Warning("REDIS_ADDR not set, redis disabled")
+        return
+    }
+
+    redisClient = cache.NewRedisClient(redis.NewClient(&redis.Options{
+        Addr:     addr,
+        Password: os.Getenv("REDIS_PASSWORD"),
+        DB:       cast.ToInt(os.Getenv("REDIS_DB")),
+    }))
+}
+
+func initAWS() {
+    var err error
+    awsService, err = awsutil.NewAWSService(os.Getenv("AWS_ACCESS_KEY"), os.Getenv("AWS_SECRET_KEY"),
+        os.Getenv("AWS_REGION"), os.Getenv("AWS_BUCKET"))
+    if err != nil {
+        logrus.Errorln("Cannot init AWS service", err)
+        return
+    }
+
+    if expiry := cast.ToInt(os.Getenv("AWS_PRESIGN_EXPIRY")); expiry > 0 {
+        if err = awsService.SetPresignExpiry(time.Duration(expiry) * time.Second); err != nil {
+            logrus.Errorln("Invalid AWS_PRESIGN_EXPIRY", err)
+        }
+    }
+}
+
+// initFirebase push notification is disabled when FIREBASE_AUTH_KEY is empty
+func initFirebase() {
+    if os.Getenv("FIREBASE_AUTH_KEY") == "" {
+        logrus.Warning("FIREBASE_AUTH_KEY not set, push notification disabled")
+        return
+    }
+
+    key, err := messaging.GetDecodedFireBaseKey()
+    if err != nil {
+        logrus.Errorln("Invalid FIREBASE_AUTH_KEY", err)
+        return
+    }
+
+    ctx := context.Background()
+    firebaseApp, err := firebase.NewApp(ctx, nil, option.WithCredentialsJSON(key))
+    if err != nil {
+        logrus.Errorln("Cannot init firebase app", err)
+        return
+    }
+    fcmClient, err := firebaseApp.Messaging(ctx)
+    if err != nil {
+        logrus.Errorln("Cannot init firebase messaging", err)
+        return
+    }
+    firebaseClient = fcmToken.NewFirebaseClient(fcmClient)
+}
+
+func initKafka() {
+    kafkaPublisher = newKafkaPublisher(cast.ToBool(os.Getenv("KAFKA_ASYNC")))
+}
+
+// newKafkaPublisher nil when KAFKA_BROKERS is empty
+func newKafkaPublisher(async bool) messaging.KafkaPublisher {
+    brokers := os.Getenv("KAFKA_BROKERS")
+    if brokers == "" {
+        logrus.Warning("KAFKA_BROKERS not set, kafka publisher disabled")
+        return nil
+    }
+
+    opts := []messaging.Option{
+        messaging.WithBatch(cast.ToInt(os.Getenv("KAFKA_BATCH_SIZE")), messaging.DefaultBatchTimeout),
+    }
+    if async {
+        opts = append(opts, messaging.WithAsync())
+    }
+    return messaging.NewKafkaPublisher(brokers, os.Getenv("KAFKA_LOW_STOCK_TOPIC"), opts...)
+}
+
+// closeKafka write pending message of async publisher
+func closeKafka() {
+    if kafkaPublisher == nil {
+        return
+    }
+    if err := kafkaPublisher.Close(); err != nil {
+        logrus.Errorln("Close kafka publisher", err)
+    }
+}
+
+// initNotification dispatcher send to the channel configured in env, nil when there is none
+func initNotification() {
+    var channels []notification.Channel
+    headers := map[string]string{}
+    if key := os.Getenv("NOTIFICATION_API_KEY"); key != "" {
+        headers["Authorization"] = "Bearer " + key
+    }
+    if url := os.Getenv("NOTIFICATION_INAPP_URL"); url != "" {
+        channels = append(channels, notification.NewInAppChannel(url, headers, httpClient))
+    }
+    if emailEnabled() {
+        channels = append(channels, notification.NewEmailChannel(os.Getenv("NOTIFICATION_EMAIL_URL"),
+            os.Getenv("NOTIFICATION_EMAIL_SENDER"), headers, httpClient))
+    }
+    if firebaseClient != nil {
+        channels = append(channels, notification.PushChannel{Client: firebaseClient})
+    }
+    if len(channels) == 0 {
+        logrus.Warning("NOTIFICATION_INAPP_URL, NOTIFICATION_EMAIL_URL and FIREBASE_AUTH_KEY not set, notification disabled")
+        return
+    }
+
+    var recorder notification.Recorder
+    if mysqlClientRepo != nil {
+        recorder = storeRepo.NewStoreRepository(mysqlClientRepo.DB)
+    }
+    notifier = notification.NewDispatcher(notification.Config{
+        Workers:     cast.ToInt(os.Getenv("NOTIFICATION_WORKERS")),
+        MaxAttempts: cast.ToInt(os.Getenv("NOTIFICATION_MAX_ATTEMPTS")),
+    }, storeService.NotificationTemplates(), recorder, channels...)
+}
+
+// emailEnabled email channel need both the provider url and the sender address
+func emailEnabled() bool {
+    return os.Getenv("NOTIFICATION_EMAIL_URL") != "" && os.Getenv("NOTIFICATION_EMAIL_SENDER") != ""
+}
+
+// closeNotification send queued notification
+func closeNotification() {
+    if notifier != nil {
+        notifier.Close()
+    }
+}
+
+// closeInfrastructure on shutdown, after the app stop. Notification is sent before DB is closed, its log is saved to DB
+func closeInfrastructure() {
+    closeNotification()
+    closeMySQL()
+    closeRedis()
+    closeKafka()
+    closeTracing()
+}
+
+func closeMySQL() {
+    if mysqlClientRepo == nil || mysqlClientRepo.DB == nil {
+        return
+    }
+    if err := mysqlClientRepo.DB.Close(); err != nil {
+        logrus.Errorln("Close mysql", err)
+    }
+}
+
+func closeRedis() {
+    if redisClient == nil {
+        return
+    }
+    if err := redisClient.Close(); err != nil {
+        logrus.Errorln("Close redis", err)
+    }
+}
+
+// initMetric METRIC_BACKEND statsd send to STATSD_ADDR, prometheus is scraped on /metrics of METRICS_PORT.
+// Metric is disabled when it is empty
+func initMetric() {
+    namespace := os.Getenv("METRIC_NAMESPACE")
+    if namespace == "" {
+        namespace = defaultMetricNamespace
+    }
+
+    switch backend := os.Getenv("METRIC_BACKEND"); backend {
+    case "statsd":
+        monitoring, err := metric.NewStatsdMonitoring(os.Getenv("STATSD_ADDR"), namespace)
+        if err != nil {
+            logrus.Errorln("Cannot init statsd", err)
+            return
+        }
+        statsdMonitoring = monitoring
+    case "prometheus":
+        monitoring := metric.NewPrometheusMonitoring(namespace)
+        statsdMonitoring = monitoring
+        metricsHandler = monitoring.Handler()
+    case "":
+        logrus.Warning("METRIC_BACKEND not set, metric disabled")
+    default:
+        logrus.Errorln("Unknown METRIC_BACKEND", backend)
+    }
+}
+
+// initTracing TRACING_BACKEND datadog send to the agent on DD_AGENT_ADDR, otel export with OTLP to
+// OTEL_EXPORTER_OTLP_ENDPOINT. Tracing is disabled when it is empty
+func initTracing() {
+    config := tracing.Config{
+        ServiceName: os.Getenv("APP_NAME"),
+        Environment: os.Getenv("APP_ENV"),
+        Version:     os.Getenv("APP_VERSION"),
+        SampleRate:  cast.ToFloat64(os.Getenv("TRACING_SAMPLE_RATE")),
+    }
+
+    switch backend := os.Getenv("TRACING_BACKEND"); backend {
+    case "datadog":
+        if env := os.Getenv("DD_ENV"); env != "" {
+            config.Environment = env
+        }
+        var agentAddr string // Empty use the dd-trace default
+        if host := os.Getenv("DD_AGENT_ADDR"); host != "" {
+            agentAddr = net.JoinHostPort(host, envOrDefault("DD_AGENT_PORT", defaultDatadogPort))
+        }
+        tracing.SetTracer(tracing.NewDatadogTracer(agentAddr, config))
+    case "otel":
+        exporter, err := tracing.NewOTLPExporter(context.Background(), os.Getenv("OTEL_EXPORTER_OTLP_PROTOCOL"))
+        if err != nil {
+            logrus.Errorln("Cannot init OTLP exporter", err)
+            return
+        }
+        tracing.SetTracer(tracing.NewOTelTracer(exporter, config))
+    case "":
+        logrus.Warning("TRACING_BACKEND not set, tracing disabled")
+    default:
+        logrus.Errorln("Unknown TRACING_BACKEND", backend)
+    }
+}
+
+// closeTracing flush the finished span, last to keep the span of the other close
+func closeTracing() {
+    ctx, cancel := context.WithTimeout(context.Background(), tracingShutdownTimeout)
+    defer cancel()
+    if err := tracing.GetTracer().Shutdown(ctx); err != nil {
+        logrus.Errorln("Close tracing", err)
+    }
+}
+
 func initInfrastructure() {
+    initTracing() // First, the client below is traced
+    initMetric()
     initMySQL()
+    initRedis()
+    initAWS()
+    initKafka()
+    initFirebase()
     initLog() // Init log after baseHandler
     httpClientFactory := httpclient.New()
     httpClient = httpClientFactory.CreateClient()
+    initNotification()
 
     var err error
     if err != nil {
@@ -74,7 +334,7 @@ func initHTTP() {
 
     storeRepo := storeRepo.NewStoreRepository(mysqlClientRepo.DB)
 
-    storeService := storeService.NewService(storeRepo)
+    storeService := storeService.NewService(storeRepo, awsService, initServiceConfig())
 
     baseHandler = handler.NewBaseHTTPHandler(mysqlClientRepo.DB, httpClient, params, statsdMonitoring, storeService)
 
@@ -83,6 +343,31 @@ func initHTTP() {
     fmt.Println("INFO: Init and load module completed. Server started.\n---")
 }
 
+func initServiceConfig() storeService.Config {
+    config := storeService.Config{
+        ReservationEnabled: cast.ToBool(os.Getenv("CART_RESERVATION_ENABLED")),
+        ReservationTTL:     time.Duration(cast.ToInt(os.Getenv("CART_RESERVATION_TTL"))) * time.Second,
+    }
+
+    // Low stock alert, publisher and http client are only set by initInfrastructure
+    config.Publisher = kafkaPublisher
+    config.LowStockTopic = os.Getenv("KAFKA_LOW_STOCK_TOPIC")
+    config.EventSource = os.Getenv("APP_NAME")
+    if channel := os.Getenv("FLOCK_LOW_STOCK_CHANNEL"); channel != "" && httpClient != nil {
+        config.Flock = &flockhook.Hook{Channel: channel, HTTPClient: httpClient}
+    }
+    if notifier != nil {
+        config.Notifier = notifier
+        config.Email = emailEnabled()
+        // Push is sent through the dispatcher so it is recorded, the push channel is only registered with firebase
+        if firebaseClient != nil {
+            config.Push = notifier
+        }
+    }
+    config.Metric = statsdMonitoring
+    return config
+}
+
 func initLog() {
     logrus.SetFormatter(&gelfFormatter.GelfFormatter{})
 
@@ -105,6 +390,11 @@ func initLog() {
         }
     }
 
+    // Request log share the GELF output, it is logged on production whose level is only Warn
+    accessLogger = logrus.New()
+    accessLogger.SetFormatter(&gelfFormatter.GelfFormatter{})
+    accessLogger.SetOutput(logrus.StandardLogger().Out)
+
     lv := os.Getenv("LOG_LEVEL_DEV")
     level := logrus.InfoLevel
     switch lv {
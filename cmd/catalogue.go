@@ -0,0 +1,63 @@
+package cmd
+
+import (
+    "context"
+    "fmt"
+    "os"
+    "path/filepath"
+
+    presenterProduct "store-api/internal/store/presenter/product"
+    storeRepo "store-api/internal/store/repository"
+    storeService "store-api/internal/store/service"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/cobra"
+)
+
+var importProductCmd = &cobra.Command{
+    Use:   "import-product",
+    Short: "Import product catalogue from csv or xlsx",
+    Long:  "Create or update products (by name and category) and variants (by sku) from csv or xlsx file",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        filePath, _ := cmd.Flags().GetString("file")
+        dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+        file, err := os.Open(filePath)
+        if err != nil {
+            return errors.Wrap(err, "open import file")
+        }
+        defer file.Close()
+
+        params = initParams()
+        initMySQL()
+        defer closeMySQL()
+        if mysqlClientRepo == nil {
+            return errors.New("cannot connect to database")
+        }
+
+        service := storeService.NewService(storeRepo.NewStoreRepository(mysqlClientRepo.DB), nil, storeService.Config{})
+        result, _, err := service.ImportProduct(context.Background(), presenterProduct.ImportRequest{
+            Format: filepath.Ext(filePath),
+            DryRun: dryRun,
+            File:   file,
+        })
+        for _, rowError := range result.Errors {
+            fmt.Printf("row %d, %s: %s\n", rowError.Row, rowError.Column, rowError.Message)
+        }
+        logrus.Infof("Import product: total %d, imported %d, failed %d, dry run %t",
+            result.TotalRow, result.Imported, result.Failed, result.DryRun)
+        if err != nil {
+            return errors.Wrap(err, "import product")
+        }
+        return nil
+    },
+}
+
+func init() {
+    rootCmd.AddCommand(importProductCmd)
+
+    importProductCmd.Flags().StringP("file", "f", "", "csv or xlsx file path")
+    importProductCmd.Flags().Bool("dry-run", false, "only validate the file, nothing is saved")
+    importProductCmd.MarkFlagRequired("file")
+}
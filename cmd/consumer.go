@@ -0,0 +1,86 @@
+package cmd
+
+import (
+    "context"
+    "os"
+    "strings"
+
+    "store-api/app/consumer"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    storeModule "store-api/internal/store/handler"
+    storeRepo "store-api/internal/store/repository"
+    storeService "store-api/internal/store/service"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/cast"
+    "github.com/spf13/cobra"
+)
+
+const defaultPaymentTopic = "store.payments"
+
+var ConsumerCmd = &cobra.Command{
+    Use:   "consumer",
+    Short: "Run Kafka consumer",
+    Long:  "Consume KAFKA_BROKERS topics with consumer group KAFKA_CONSUMER_GROUP until terminated",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        params = initParams()
+        initInfrastructure()
+        defer closeInfrastructure()
+        if mysqlClientRepo == nil {
+            return errors.New("cannot connect to database")
+        }
+        if kafkaPublisher == nil {
+            return errors.New("KAFKA_BROKERS is required")
+        }
+
+        service := storeService.NewService(storeRepo.NewStoreRepository(mysqlClientRepo.DB), awsService, initServiceConfig())
+        consumerHandler := storeModule.NewConsumerHandler(service)
+
+        app := consumer.New(initConsumerConfig())
+        app.Register(envOrDefault("KAFKA_PAYMENT_TOPIC", defaultPaymentTopic), consumerHandler.PaymentStatus)
+        if firebaseClient != nil || notifier != nil {
+            app.Register(envOrDefault("KAFKA_ORDER_TOPIC", modelOutbox.TopicOrders), consumerHandler.OrderNotification)
+            app.Register(envOrDefault("KAFKA_STOCK_TOPIC", modelOutbox.TopicStock), consumerHandler.BackInStock)
+        }
+
+        echan := make(chan error, 1)
+        go func() {
+            echan <- app.Run()
+        }()
+
+        select {
+        case <-terminated():
+            logrus.Infoln("signal terminated detected, waiting message in progress")
+            ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+            defer cancel()
+
+            if err := app.Shutdown(ctx); err != nil {
+                return errors.Wrap(err, "consumer shutdown")
+            }
+            return nil
+        case err := <-echan:
+            return errors.Wrap(err, "consumer runtime error")
+        }
+    },
+}
+
+func initConsumerConfig() consumer.Config {
+    config := consumer.Config{
+        Brokers:     strings.Split(os.Getenv("KAFKA_BROKERS"), ","),
+        GroupID:     envOrDefault("KAFKA_CONSUMER_GROUP", os.Getenv("APP_NAME")),
+        Concurrency: cast.ToInt(os.Getenv("KAFKA_CONSUMER_CONCURRENCY")),
+        MaxRetry:    consumer.DefaultMaxRetry,
+    }
+    if maxRetry := os.Getenv("KAFKA_CONSUMER_MAX_RETRY"); maxRetry != "" {
+        config.MaxRetry = cast.ToInt(maxRetry)
+    }
+    return config
+}
+
+func envOrDefault(key, value string) string {
+    if env := os.Getenv(key); env != "" {
+        return env
+    }
+    return value
+}
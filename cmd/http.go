@@ -1,40 +1,121 @@
 package cmd
 
 import (
+    "context"
     "os"
-    "os/signal"
-    "syscall"
+    "strings"
+    "time"
 
     "store-api/app/api"
+    "store-api/pkg/health"
+    "store-api/pkg/security"
 
     "github.com/pkg/errors"
     "github.com/sirupsen/logrus"
+    "github.com/spf13/cast"
     "github.com/spf13/cobra"
 )
 
+const (
+    defaultShutdownTimeout   = 20 * time.Second
+    defaultHealthNonCritical = "redis,kafka" // Cart reservation and low stock alert work without them
+)
+
 var HttpCmd = &cobra.Command{
     Use:   "http serve",
     Short: "Run Http API",
     Long:  "Run Http API",
     RunE: func(cmd *cobra.Command, args []string) error {
+        // Fail closed, member session can't be signed nor verified without secret
+        if _, err := security.SessionSecret(); err != nil {
+            return err
+        }
+
         logrus.Infof("Starting the server at :%s", os.Getenv("HTTP_SERVER_PORT"))
         initHTTP()
 
-        app := api.New(os.Getenv("APP_NAME"), baseHandler, storeHandler)
+        app := api.New(os.Getenv("APP_NAME"), initHTTPConfig(), baseHandler, storeHandler)
 
-        echan := make(chan error)
+        echan := make(chan error, 1)
         go func() {
             echan <- app.Run()
         }()
-        term := make(chan os.Signal, 1)
-        signal.Notify(term, os.Interrupt, syscall.SIGTERM)
 
         select {
-        case <-term:
-            logrus.Infoln("signal terminated detected")
+        case <-terminated():
+            logrus.Infoln("signal terminated detected, waiting request in progress")
+            ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout())
+            defer cancel()
+
+            err := app.Shutdown(ctx)
+            closeInfrastructure()
+            if err != nil {
+                return errors.Wrap(err, "http server shutdown")
+            }
             return nil
         case err := <-echan:
+            closeInfrastructure()
             return errors.Wrap(err, "service runtime error")
         }
     },
 }
+
+// initHTTPConfig HTTP_*_TIMEOUT in seconds, empty use the default
+func initHTTPConfig() api.Config {
+    return api.Config{
+        ReadTimeout:       time.Duration(cast.ToInt(os.Getenv("HTTP_READ_TIMEOUT"))) * time.Second,
+        ReadHeaderTimeout: time.Duration(cast.ToInt(os.Getenv("HTTP_READ_HEADER_TIMEOUT"))) * time.Second,
+        WriteTimeout:      time.Duration(cast.ToInt(os.Getenv("HTTP_WRITE_TIMEOUT"))) * time.Second,
+        IdleTimeout:       time.Duration(cast.ToInt(os.Getenv("HTTP_IDLE_TIMEOUT"))) * time.Second,
+        Health:            initHealth(),
+        AccessLog:         accessLogger,
+        Metrics:           metricsHandler,
+        MetricsPort:       os.Getenv("METRICS_PORT"),
+    }
+}
+
+// initHealth readiness check of the initialized dependency. HEALTH_*_TIMEOUT in milliseconds,
+// failing dependency in HEALTH_NON_CRITICAL mark the service degraded instead of down
+func initHealth() *health.Health {
+    nonCritical := os.Getenv("HEALTH_NON_CRITICAL")
+    if nonCritical == "" {
+        nonCritical = defaultHealthNonCritical
+    }
+    critical := func(name string) bool {
+        for _, item := range strings.Split(nonCritical, ",") {
+            if strings.TrimSpace(item) == name {
+                return false
+            }
+        }
+        return true
+    }
+    timeout := func(key string) time.Duration {
+        return time.Duration(cast.ToInt(os.Getenv(key))) * time.Millisecond
+    }
+
+    checker := health.New()
+    if mysqlClientRepo != nil && mysqlClientRepo.DB != nil {
+        checker.Register("mysql", critical("mysql"), timeout("HEALTH_MYSQL_TIMEOUT"), health.SQLCheck(mysqlClientRepo.DB))
+    }
+    if redisClient != nil {
+        checker.Register("redis", critical("redis"), timeout("HEALTH_REDIS_TIMEOUT"), health.RedisCheck(redisClient))
+    }
+    if brokers := os.Getenv("KAFKA_BROKERS"); brokers != "" {
+        var addresses []string
+        for _, broker := range strings.Split(brokers, ",") {
+            if broker = strings.TrimSpace(broker); broker != "" {
+                addresses = append(addresses, broker)
+            }
+        }
+        checker.Register("kafka", critical("kafka"), timeout("HEALTH_KAFKA_TIMEOUT"), health.TCPCheck(addresses))
+    }
+    return checker
+}
+
+// shutdownTimeout deadline to drain request, message or job in progress, HTTP_SHUTDOWN_TIMEOUT in seconds
+func shutdownTimeout() time.Duration {
+    if timeout := cast.ToInt(os.Getenv("HTTP_SHUTDOWN_TIMEOUT")); timeout > 0 {
+        return time.Duration(timeout) * time.Second
+    }
+    return defaultShutdownTimeout
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+    "encoding/json"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+
+    "store-api/pkg/health"
+
+    "github.com/stretchr/testify/assert"
+)
+
+// servePong answer PONG to every command, enough for the redis ping
+func servePong(t *testing.T) string {
+    listener, err := net.Listen("tcp", "127.0.0.1:0")
+    assert.Nil(t, err)
+    t.Cleanup(func() { listener.Close() })
+
+    go func() {
+        for {
+            conn, err := listener.Accept()
+            if err != nil {
+                return
+            }
+            go func() {
+                defer conn.Close()
+                buf := make([]byte, 512)
+                for {
+                    if _, err := conn.Read(buf); err != nil {
+                        return
+                    }
+                    conn.Write([]byte("+PONG\r\n"))
+                }
+            }()
+        }
+    }()
+    return listener.Addr().String()
+}
+
+func TestInitHealth_Redis(t *testing.T) {
+    t.Cleanup(func() {
+        closeRedis()
+        redisClient = nil
+    })
+
+    readyz := func() health.Result {
+        rw := httptest.NewRecorder()
+        initHealth().ReadinessHandler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+        result := health.Result{}
+        assert.Nil(t, json.Unmarshal(rw.Body.Bytes(), &result))
+        return result
+    }
+
+    t.Setenv("REDIS_ADDR", "")
+    initRedis()
+    assert.NotContains(t, readyz().Checks, "redis")
+
+    t.Setenv("REDIS_ADDR", servePong(t))
+    initRedis()
+    result := readyz()
+    assert.Equal(t, health.StatusUp, result.Status)
+    assert.Equal(t, health.StatusUp, result.Checks["redis"].Status)
+    assert.False(t, result.Checks["redis"].Critical, "Redis is non critical by default")
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+    "context"
+    "time"
+
+    storeRepo "store-api/internal/store/repository"
+    storeService "store-api/internal/store/service"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/cobra"
+)
+
+var outboxRelayCmd = &cobra.Command{
+    Use:   "outbox-relay",
+    Short: "Publish outbox event to kafka",
+    Long:  "Publish pending outbox event (order and stock change) to KAFKA_BROKERS, run periodically until terminated",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        interval, _ := cmd.Flags().GetDuration("interval")
+        once, _ := cmd.Flags().GetBool("once")
+        if interval <= 0 {
+            return errors.New("interval must be greater than 0")
+        }
+
+        params = initParams()
+        initMySQL()
+        defer closeMySQL()
+        if mysqlClientRepo == nil {
+            return errors.New("cannot connect to database")
+        }
+        // Event is marked sent after publish, publisher must be sync
+        kafkaPublisher = newKafkaPublisher(false)
+        if kafkaPublisher == nil {
+            return errors.New("KAFKA_BROKERS is required")
+        }
+        defer closeKafka()
+
+        service := storeService.NewService(storeRepo.NewStoreRepository(mysqlClientRepo.DB), nil, initServiceConfig())
+        relay := func(ctx context.Context) {
+            sent, _, err := service.RelayOutbox(ctx)
+            if err != nil {
+                logrus.Errorln("Relay outbox", err)
+            }
+            if sent > 0 {
+                logrus.Infof("Published %d outbox event", sent)
+            }
+        }
+
+        relay(context.Background())
+        if once {
+            return nil
+        }
+
+        logrus.Infof("Outbox relay started, interval %s", interval)
+        return runPeriodic(terminated(), interval, shutdownTimeout(), relay)
+    },
+}
+
+func init() {
+    rootCmd.AddCommand(outboxRelayCmd)
+
+    outboxRelayCmd.Flags().Duration("interval", 5*time.Second, "how often pending outbox event is published")
+    outboxRelayCmd.Flags().Bool("once", false, "publish once then exit, ex: run from cron")
+}
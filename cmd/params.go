@@ -4,6 +4,8 @@ import (
 	"os"
 	"path"
 	"runtime"
+
+	"store-api/pkg/middleware"
 )
 
 func initParams() map[string]string {
@@ -12,6 +14,18 @@ func initParams() map[string]string {
 	params["APP_ENV"] = os.Getenv("APP_ENV")
 	params["app-version"] = os.Getenv("APP_VERSION")
 	params["app-name"] = os.Getenv("APP_NAME")
+	params["admin-key"] = os.Getenv("ADMIN_API_KEY")
+
+	params[middleware.ParamCORSAllowedOrigins] = os.Getenv("CORS_ALLOWED_ORIGINS")
+	params[middleware.ParamCORSAllowedMethods] = os.Getenv("CORS_ALLOWED_METHODS")
+	params[middleware.ParamCORSAllowedHeaders] = os.Getenv("CORS_ALLOWED_HEADERS")
+	params[middleware.ParamCORSExposedHeaders] = os.Getenv("CORS_EXPOSED_HEADERS")
+	params[middleware.ParamCORSAllowCredentials] = os.Getenv("CORS_ALLOW_CREDENTIALS")
+	params[middleware.ParamCORSMaxAge] = os.Getenv("CORS_MAX_AGE")
+
+	params[middleware.ParamLogRequestBodyLimit] = os.Getenv("LOG_REQUEST_BODY_LIMIT")
+	params[middleware.ParamLogResponseBodyLimit] = os.Getenv("LOG_RESPONSE_BODY_LIMIT")
+	params[middleware.ParamLogSampleRate] = os.Getenv("LOG_SAMPLE_RATE")
 
 	_, b, _, _ := runtime.Caller(0)
 	appDir := path.Join(path.Dir(b), "..")
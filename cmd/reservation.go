@@ -0,0 +1,59 @@
+package cmd
+
+import (
+    "context"
+    "time"
+
+    storeRepo "store-api/internal/store/repository"
+    storeService "store-api/internal/store/service"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/cobra"
+)
+
+var reservationWorkerCmd = &cobra.Command{
+    Use:   "reservation-worker",
+    Short: "Release expired cart stock reservation",
+    Long:  "Give back stock of cart reservation older than CART_RESERVATION_TTL, run periodically until terminated",
+    RunE: func(cmd *cobra.Command, args []string) error {
+        interval, _ := cmd.Flags().GetDuration("interval")
+        once, _ := cmd.Flags().GetBool("once")
+        if interval <= 0 {
+            return errors.New("interval must be greater than 0")
+        }
+
+        params = initParams()
+        initMySQL()
+        defer closeMySQL()
+        if mysqlClientRepo == nil {
+            return errors.New("cannot connect to database")
+        }
+
+        service := storeService.NewService(storeRepo.NewStoreRepository(mysqlClientRepo.DB), nil, initServiceConfig())
+        release := func(ctx context.Context) {
+            released, _, err := service.ReleaseExpiredReservation(ctx)
+            if err != nil {
+                logrus.Errorln("Release expired reservation", err)
+            }
+            if released > 0 {
+                logrus.Infof("Released %d expired reservation", released)
+            }
+        }
+
+        release(context.Background())
+        if once {
+            return nil
+        }
+
+        logrus.Infof("Reservation worker started, interval %s", interval)
+        return runPeriodic(terminated(), interval, shutdownTimeout(), release)
+    },
+}
+
+func init() {
+    rootCmd.AddCommand(reservationWorkerCmd)
+
+    reservationWorkerCmd.Flags().Duration("interval", 30*time.Second, "how often expired reservation is released")
+    reservationWorkerCmd.Flags().Bool("once", false, "release once then exit, ex: run from cron")
+}
@@ -3,8 +3,6 @@ package cmd
 import (
     "os"
 
-    "store-api/pkg/errs"
-
     "github.com/joho/godotenv"
     "github.com/sirupsen/logrus"
     "github.com/spf13/cobra"
@@ -21,11 +19,12 @@ var rootCmd = &cobra.Command{
 //register command
 func init() {
     //load environment variable
-    if err := godotenv.Load("./params/.env"); err != nil && err.Error() != errs.ErrNoSuchFile {
+    if err := godotenv.Load("./params/.env"); err != nil && !os.IsNotExist(err) {
         logrus.Fatalln("unable to load environment variable", err.Error())
     }
 
     rootCmd.AddCommand(HttpCmd)
+    rootCmd.AddCommand(ConsumerCmd)
 }
 
 func Execute() error {
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+    "context"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+
+    "github.com/pkg/errors"
+    "github.com/sirupsen/logrus"
+)
+
+// terminated channel of SIGTERM and interrupt
+func terminated() <-chan os.Signal {
+    term := make(chan os.Signal, 1)
+    signal.Notify(term, os.Interrupt, syscall.SIGTERM)
+    return term
+}
+
+// runPeriodic run the job every interval until term. Job in progress is given timeout to finish, then its ctx is
+// cancelled and the deadline error returned, same as the http server shutdown.
+func runPeriodic(term <-chan os.Signal, interval, timeout time.Duration, job func(ctx context.Context)) error {
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    stop := make(chan struct{})
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        ticker := time.NewTicker(interval)
+        defer ticker.Stop()
+        for {
+            select {
+            case <-stop:
+                return
+            case <-ticker.C:
+                job(ctx)
+            }
+        }
+    }()
+
+    <-term
+    logrus.Infoln("signal terminated detected, waiting job in progress")
+    close(stop)
+
+    select {
+    case <-done:
+        return nil
+    case <-time.After(timeout):
+        cancel()
+        <-done
+        return errors.Wrap(context.DeadlineExceeded, "job shutdown")
+    }
+}
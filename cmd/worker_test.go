@@ -0,0 +1,54 @@
+package cmd
+
+import (
+    "context"
+    "os"
+    "testing"
+    "time"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestRunPeriodic(t *testing.T) {
+    t.Run("Job in progress is finished", func(t *testing.T) {
+        term := make(chan os.Signal, 1)
+        started := make(chan struct{}, 1)
+        var finished bool
+        go func() {
+            <-started
+            term <- os.Interrupt
+        }()
+
+        err := runPeriodic(term, time.Millisecond, time.Second, func(ctx context.Context) {
+            select {
+            case started <- struct{}{}:
+            default:
+            }
+            time.Sleep(20 * time.Millisecond)
+            finished = ctx.Err() == nil
+        })
+        assert.Nil(t, err)
+        assert.True(t, finished)
+    })
+
+    t.Run("Job is cancelled after the deadline", func(t *testing.T) {
+        term := make(chan os.Signal, 1)
+        started := make(chan struct{}, 1)
+        go func() {
+            <-started
+            term <- os.Interrupt
+        }()
+
+        var cancelled bool
+        err := runPeriodic(term, time.Millisecond, 10*time.Millisecond, func(ctx context.Context) {
+            select {
+            case started <- struct{}{}:
+            default:
+            }
+            <-ctx.Done()
+            cancelled = true
+        })
+        assert.ErrorIs(t, err, context.DeadlineExceeded)
+        assert.True(t, cancelled)
+    })
+}
@@ -0,0 +1,182 @@
+// Binding
+//
+// Usage:
+//	 func(h Handler) handler(ctx *app.Context) {
+//
+//		req := presenter.Request{}
+//		if !ctx.Bind(&req) {
+//			return h.AsContextError(ctx)
+//		}
+//
+// Presenter declare the rules with `validate` tag of go-playground/validator,
+// field errors is named by the `json` tag
+
+package app
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "reflect"
+    "regexp"
+    "strconv"
+    "strings"
+
+    "store-api/pkg/errs"
+
+    "github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+// arrayIndex index in the field path of json decode error, items.0.quantity -> items[0].quantity as validator
+var arrayIndex = regexp.MustCompile(`\.(\d+)\b`)
+
+func newValidator() *validator.Validate {
+    v := validator.New()
+    v.RegisterTagNameFunc(func(field reflect.StructField) string {
+        name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+        if name == "-" {
+            return ""
+        }
+        return name
+    })
+    return v
+}
+
+// Bind decode the JSON body into dest and validate it. Errors is appended to ctx, field errors
+// can get by ctx.FieldErrors(). Return false when the request is not valid
+func (ctx *Context) Bind(dest interface{}) bool {
+    if ctx.HasError() {
+        return false
+    }
+
+    if ctx.hasBody {
+        if !ctx.IsContentTypeJson() {
+            ctx.AppendError(errs.NewBadRequestError("Content-Type must be application/json", errors.New("invalid content type")))
+            return false
+        }
+        ctx.ParseJson()
+        if ctx.HasError() {
+            return false
+        }
+
+        if len(bytes.TrimSpace(ctx.body)) > 0 {
+            if err := json.Unmarshal(ctx.body, dest); err != nil {
+                var typeErr *json.UnmarshalTypeError
+                if errors.As(err, &typeErr) && typeErr.Field != "" {
+                    field := arrayIndex.ReplaceAllString(typeErr.Field, "[$1]")
+                    ctx.AppendError(errs.NewFieldError(field, jsonValue(ctx.data, typeErr.Field), errs.ErrorTypeInvalid,
+                        fmt.Errorf("%s must be %s", field, jsonType(typeErr.Type))))
+                } else {
+                    ctx.AppendError(errs.NewMalformedJSONError(fmt.Errorf("invalid json body request: %v", err)))
+                }
+                return false
+            }
+        }
+    }
+
+    if err := validate.Struct(dest); err != nil {
+        var validationErrors validator.ValidationErrors
+        if !errors.As(err, &validationErrors) {
+            ctx.AppendError(errs.NewUnprocessableEntityError(err))
+            return false
+        }
+        for _, fe := range validationErrors {
+            field := fieldName(fe)
+            errType := errs.ErrorType(errs.ErrorTypeInvalid)
+            if fe.Tag() == "required" {
+                errType = errs.ErrorTypeMissing
+            }
+            ctx.AppendError(errs.NewFieldError(field, fe.Value(), errType, errors.New(fieldMessage(field, fe))))
+        }
+        return false
+    }
+    return true
+}
+
+// fieldName json path of the field without the struct name, eg. items[0].quantity
+func fieldName(fe validator.FieldError) string {
+    namespace := fe.Namespace()
+    if i := strings.Index(namespace, "."); i >= 0 {
+        return namespace[i+1:]
+    }
+    return namespace
+}
+
+func fieldMessage(field string, fe validator.FieldError) string {
+    kind := fe.Kind()
+    unit := ""
+    switch kind {
+    case reflect.String:
+        unit = " characters"
+    case reflect.Slice, reflect.Array, reflect.Map:
+        unit = " items"
+    }
+
+    switch fe.Tag() {
+    case "required":
+        return fmt.Sprintf("%s is required", field)
+    case "min":
+        return fmt.Sprintf("%s must be at least %s%s", field, fe.Param(), unit)
+    case "max":
+        return fmt.Sprintf("%s must be at most %s%s", field, fe.Param(), unit)
+    case "len":
+        return fmt.Sprintf("%s must be %s%s", field, fe.Param(), unit)
+    case "gt":
+        return fmt.Sprintf("%s must be greater than %s", field, fe.Param())
+    case "gte":
+        return fmt.Sprintf("%s must be greater than or equal to %s", field, fe.Param())
+    case "lt":
+        return fmt.Sprintf("%s must be less than %s", field, fe.Param())
+    case "lte":
+        return fmt.Sprintf("%s must be less than or equal to %s", field, fe.Param())
+    case "oneof":
+        return fmt.Sprintf("%s must be one of: %s", field, strings.Join(strings.Fields(fe.Param()), ", "))
+    case "email":
+        return fmt.Sprintf("%s must be a valid email", field)
+    case "url":
+        return fmt.Sprintf("%s must be a valid url", field)
+    }
+    return fmt.Sprintf("%s is not valid", field)
+}
+
+// jsonValue value of the json body by the path of json decode error, eg. items.0.quantity
+func jsonValue(data interface{}, path string) interface{} {
+    for _, key := range strings.Split(path, ".") {
+        switch node := data.(type) {
+        case map[string]interface{}:
+            data = node[key]
+        case []interface{}:
+            i, err := strconv.Atoi(key)
+            if err != nil || i < 0 || i >= len(node) {
+                return nil
+            }
+            data = node[i]
+        default:
+            return nil
+        }
+    }
+    return data
+}
+
+// jsonType name of the go type in json, for the decode error message
+func jsonType(t reflect.Type) string {
+    switch t.Kind() {
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        return "an integer"
+    case reflect.Float32, reflect.Float64:
+        return "a number"
+    case reflect.String:
+        return "a string"
+    case reflect.Bool:
+        return "a boolean"
+    case reflect.Slice, reflect.Array:
+        return "an array"
+    case reflect.Map, reflect.Struct:
+        return "an object"
+    }
+    return t.String()
+}
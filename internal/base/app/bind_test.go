@@ -0,0 +1,110 @@
+package app
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "store-api/pkg/errs"
+
+    "github.com/stretchr/testify/assert"
+)
+
+type bindItem struct {
+    VariantID int `json:"variant_id" validate:"required"`
+    Quantity  int `json:"quantity" validate:"gt=0"`
+}
+
+type bindRequest struct {
+    MemberID int        `json:"-"`
+    Name     string     `json:"name" validate:"required,max=5"`
+    Type     string     `json:"type" validate:"oneof=restock refund"`
+    Items    []bindItem `json:"items" validate:"required,min=1,dive"`
+}
+
+func newJsonContext(method, body, contentType string) *Context {
+    r := httptest.NewRequest(method, "/api/v1/test", strings.NewReader(body))
+    r.Header.Set("Content-Type", contentType)
+    return NewContext(httptest.NewRecorder(), r, false)
+}
+
+func TestContext_Bind(t *testing.T) {
+    t.Run("Decode valid body", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, `{"name":"kaos","type":"refund","items":[{"variant_id":3,"quantity":2}]}`, "application/json")
+
+        req := bindRequest{}
+        assert.True(t, ctx.Bind(&req))
+        assert.False(t, ctx.HasError())
+        assert.Equal(t, "kaos", req.Name)
+        assert.Equal(t, []bindItem{{VariantID: 3, Quantity: 2}}, req.Items)
+        assert.Equal(t, "kaos", ctx.GetJsonBody()["name"]) // ParseJson still work
+    })
+
+    t.Run("Field errors named by json tag", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, `{"name":"kemeja","type":"sale","items":[{"variant_id":0,"quantity":1}]}`, "application/json")
+
+        req := bindRequest{}
+        assert.False(t, ctx.Bind(&req))
+        assert.Equal(t, []errs.ErrorResponse{
+            {Code: "invalid", Message: "name must be at most 5 characters", Field: "name", Value: "kemeja"},
+            {Code: "invalid", Message: "type must be one of: restock, refund", Field: "type", Value: "sale"},
+            {Code: "missing", Message: "items[0].variant_id is required", Field: "items[0].variant_id", Value: 0},
+        }, ctx.ErrorResponses())
+        assert.Equal(t, http.StatusUnprocessableEntity, ctx.ErrorStatusCode())
+    })
+
+    t.Run("Empty body validate required fields", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, ``, "application/json")
+
+        req := bindRequest{}
+        assert.False(t, ctx.Bind(&req))
+        errors := ctx.ErrorResponses()
+        assert.Len(t, errors, 3)
+        assert.Equal(t, "name is required", errors[0].Message)
+        assert.Equal(t, "missing", errors[0].Code)
+    })
+
+    t.Run("Wrong json type is field error", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, `{"name":5,"type":"refund","items":[{"variant_id":3,"quantity":1}]}`, "application/json")
+
+        req := bindRequest{}
+        assert.False(t, ctx.Bind(&req))
+        assert.Equal(t, []errs.ErrorResponse{
+            {Code: "invalid", Message: "name must be a string", Field: "name", Value: float64(5)},
+        }, ctx.ErrorResponses())
+    })
+
+    t.Run("Malformed json is not field error", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, `{"name":`, "application/json")
+
+        req := bindRequest{}
+        assert.False(t, ctx.Bind(&req))
+        assert.Equal(t, "parse", ctx.ErrorResponses()[0].Code)
+        assert.Empty(t, ctx.ErrorResponses()[0].Field)
+        assert.Equal(t, http.StatusBadRequest, ctx.ErrorStatusCode())
+        assert.True(t, strings.HasPrefix(ctx.GetFirstError().Error(), "invalid json body request"))
+    })
+
+    t.Run("Parse error keep the rejected value", func(t *testing.T) {
+        r := httptest.NewRequest(http.MethodGet, "/api/v1/test?page=abc", nil)
+        ctx := NewContext(httptest.NewRecorder(), r, false)
+
+        ctx.GetQueryInt("page")
+        ctx.GetQuery("category")
+        assert.Equal(t, []errs.ErrorResponse{
+            {Code: "parse", Message: "Parse :page fail", Field: "page", Value: "abc"},
+            {Code: "missing", Message: "Missing query: category in URI", Field: "category"},
+        }, ctx.ErrorResponses())
+        assert.Equal(t, http.StatusBadRequest, ctx.ErrorStatusCode())
+    })
+
+    t.Run("Reject form content type", func(t *testing.T) {
+        ctx := newJsonContext(http.MethodPost, `name=kaos`, "application/x-www-form-urlencoded")
+
+        req := bindRequest{}
+        assert.False(t, ctx.Bind(&req))
+        assert.Equal(t, "bad_request", ctx.ErrorResponses()[0].Code)
+        assert.Equal(t, http.StatusBadRequest, ctx.ErrorStatusCode())
+    })
+}
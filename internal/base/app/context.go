@@ -12,10 +12,12 @@
 package app
 
 import (
+    "bytes"
     "context"
     "encoding/json"
     "errors"
     "fmt"
+    "io/ioutil"
     "net/http"
     "os"
     "strconv"
@@ -25,9 +27,13 @@ import (
     "store-api/pkg/data/filedata"
     "store-api/pkg/errs"
     "store-api/pkg/helper/realiphelper"
+    "store-api/pkg/middleware"
     "store-api/pkg/pagination"
+    "store-api/pkg/response"
 
     "github.com/gorilla/mux"
+    "github.com/sirupsen/logrus"
+    "github.com/spf13/cast"
 )
 
 const defaultMemory = 32 << 20
@@ -48,6 +54,8 @@ type Context struct {
     hasBody    bool                   // For POST, PUT
     isFormData bool                   // For JSON or FormData
     data       map[string]interface{} // For JSON
+    body       []byte                 // Raw JSON body, keep for ctx.Bind()
+    bodyParsed bool                   // JSON body is read once, request body can not read again
     formData   map[string]string      // For FormData
 
     // Extend more on your demand. Consider small data for performance factor
@@ -58,8 +66,19 @@ type Context struct {
 
 func (ctx Context) GetSsoID() string          { return ctx.ssoID }
 func (ctx Context) IsGuest() bool             { return ctx.isGuest }
+func (ctx Context) GetMemberID() int          { return cast.ToInt(ctx.ssoID) }
 func (ctx Context) GetIP() string             { return ctx.ip }
 func (ctx *Context) Context() context.Context { return ctx.Request.Context() }
+func (ctx *Context) RequestID() string        { return middleware.RequestID(ctx.Request.Context()) }
+
+// Log entry with the request ID, log of the handler is joined with the access log by it
+func (ctx *Context) Log() *logrus.Entry { return middleware.Logger(ctx.Request.Context()) }
+
+// SetSsoID mark request as authenticated by the member session
+func (ctx *Context) SetSsoID(ssoID string) {
+    ctx.ssoID = ssoID
+    ctx.isGuest = false
+}
 
 func (ctx Context) MethodName() string { return ctx.Request.Method }
 
@@ -104,6 +123,23 @@ func (ctx Context) Errors() []string {
     return errorStrings
 }
 
+// ErrorResponses errors of the request as the error body of the response
+func (ctx Context) ErrorResponses() []errs.ErrorResponse {
+    responses := make([]errs.ErrorResponse, len(ctx.errors))
+    for i, err := range ctx.errors {
+        responses[i] = err.ToResponse()
+    }
+    return responses
+}
+
+// ErrorStatusCode http status of the first error, http.StatusOK when there is no error
+func (ctx Context) ErrorStatusCode() int {
+    if len(ctx.errors) == 0 {
+        return http.StatusOK
+    }
+    return response.GetStatusCode(ctx.errors[0])
+}
+
 func (ctx Context) GetFirstError() error {
     if len(ctx.errors) == 0 {
         return nil
@@ -215,6 +251,11 @@ func (ctx *Context) GetQueryBool(key string) bool {
     return ctx.stringToBool(key, ctx.GetQuery(key))
 }
 
+// HasQuery check if the query string contain specific key, for optional query
+func (ctx *Context) HasQuery(key string) bool {
+    return ctx.Request.URL.Query().Has(key)
+}
+
 // HasParam Check if parameter contain specific key
 func (ctx *Context) HasParam(key string) bool {
     _, hasParam := ctx.Request.Form[key]
@@ -237,7 +278,7 @@ func (ctx *Context) GetValue(key string) string {
                 if ok {
                     return val
                 } else {
-                    ctx.AppendError(errs.NewParseError(key))
+                    ctx.appendParseError(key, s)
                 }
             }
         }
@@ -295,14 +336,23 @@ func (ctx *Context) ParseJson() {
 
     ctx.isFormData = false
     if ctx.hasBody {
-        decoder := json.NewDecoder(ctx.Request.Body)
-        err := decoder.Decode(&ctx.data)
+        if ctx.bodyParsed {
+            return
+        }
+        ctx.bodyParsed = true
+
+        body, err := ioutil.ReadAll(ctx.Request.Body)
         if err != nil {
-            errString := err.Error()
+            ctx.AppendError(errs.NewMalformedJSONError(fmt.Errorf("invalid json body request: %v", err)))
+            return
+        }
+        ctx.body = body
 
-            if errString != "EOF" && errString != "unexpected EOF" {
-                ctx.AppendError(errs.NewMalformedJSONError(fmt.Errorf("invalid json body request: %v", err)))
-            }
+        if len(bytes.TrimSpace(body)) == 0 {
+            return
+        }
+        if err := json.Unmarshal(body, &ctx.data); err != nil {
+            ctx.AppendError(errs.NewMalformedJSONError(fmt.Errorf("invalid json body request: %v", err)))
         }
 
     } else {
@@ -342,6 +392,7 @@ func NewContext(rw http.ResponseWriter, r *http.Request, isStaging bool) *Contex
         Request:    r,
         hasBody:    r.Method != http.MethodGet,
         isFormData: false,
+        isGuest:    true, // Until Authentication set the session
 
         ip:        realiphelper.FromRequest(r),
         startTime: time.Now(),
@@ -363,7 +414,7 @@ func (ctx *Context) stringToInt(key, value string) int {
     if !ctx.HasError() { // short circuit, no more parse data
         val, err := strconv.Atoi(value)
         if err != nil {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
         return val
     }
@@ -374,7 +425,7 @@ func (ctx *Context) stringToInt64(key, value string) int64 {
     if !ctx.HasError() { // short circuit, no more parse data
         val, err := strconv.ParseInt(value, 10, 64)
         if err != nil {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
         return val
     }
@@ -385,7 +436,7 @@ func (ctx *Context) stringToFloat64(key, value string) float64 {
     if !ctx.HasError() { // short circuit, no more parse data
         val, err := strconv.ParseFloat(value, 64)
         if err != nil {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
         return val
     }
@@ -396,7 +447,7 @@ func (ctx *Context) stringToBool(key, value string) bool {
     if !ctx.HasError() { // short circuit, no more parse data
         val, err := strconv.ParseBool(value)
         if err != nil {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
         return val
     }
@@ -423,7 +474,7 @@ func (ctx *Context) anyToFloat64(key string, value interface{}) float64 {
         if ok {
             return val
         } else {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
     }
     return 0
@@ -435,12 +486,18 @@ func (ctx *Context) anyToBool(key string, value interface{}) bool {
         if ok {
             return val
         } else {
-            ctx.AppendError(errs.NewParseError(key))
+            ctx.appendParseError(key, value)
         }
     }
     return false
 }
 
+func (ctx *Context) appendParseError(key string, value interface{}) {
+    err := errs.NewParseError(key)
+    err.Value = value
+    ctx.AppendError(err)
+}
+
 func (ctx *Context) getFormData(key string) string {
     //if ctx.formData == nil {
     //	ctx.AppendError(errs.NewError(
@@ -2,21 +2,26 @@ package handler
 
 import (
     "bytes"
+    "crypto/subtle"
     "encoding/json"
     "fmt"
     "net/http"
     "os"
+    "strconv"
+    "strings"
 
     "store-api/pkg/metric"
-
-    "gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+    "store-api/pkg/tracing"
 
     "store-api/internal/base/app"
     storeService "store-api/internal/store/service"
     "store-api/pkg/errs"
     "store-api/pkg/httpclient"
+    "store-api/pkg/middleware"
+    "store-api/pkg/security"
     "store-api/pkg/server"
 
+    "github.com/gorilla/mux"
     "github.com/jmoiron/sqlx"
     "github.com/sirupsen/logrus"
 )
@@ -84,6 +89,18 @@ func (h BaseHTTPHandler) AsMobileJsonSetStatusCode(ctx *app.Context, status int,
     }
 }
 
+// AsContextError response the errors of ctx, httpStatus of the first error. Same errors body for web and mobile
+func (h BaseHTTPHandler) AsContextError(ctx *app.Context) *server.Response {
+    return &server.Response{
+        Status:       ctx.ErrorStatusCode(),
+        Message:      ctx.GetFirstError().Error(),
+        Data:         []int{},
+        Errors:       ctx.ErrorResponses(),
+        Version:      os.Getenv("APP_VERSION"),
+        ResponseType: server.MobileSetStatusCodeType,
+    }
+}
+
 // ThrowExceptionJson for some exception not handle in Yii2 framework
 func (h BaseHTTPHandler) ThrowExceptionJson(ctx *app.Context, status, code int, name, message string) *server.Response {
     return &server.Response{
@@ -96,6 +113,17 @@ func (h BaseHTTPHandler) ThrowExceptionJson(ctx *app.Context, status, code int,
     }
 }
 
+// AsStream to download file, body is written as attachment by WriteStream
+func (h BaseHTTPHandler) AsStream(ctx *app.Context, fileName string, body bytes.Buffer) *server.Response {
+    return &server.Response{
+        Status:       http.StatusOK,
+        Message:      fileName,
+        Data:         body,
+        Version:      os.Getenv("APP_VERSION"),
+        ResponseType: server.StreamResponseType,
+    }
+}
+
 // AsJsonWithLog for custom log
 func (h BaseHTTPHandler) AsJsonWithLog(status int, message string, data interface{}, log *server.LogMessage) *server.Response {
     return &server.Response{Status: status, Message: message, Data: data, Version: os.Getenv("APP_VERSION"), Log: log}
@@ -113,6 +141,18 @@ func (h BaseHTTPHandler) GetParam(key string) string {
     return h.Params[key]
 }
 
+// RequireAdmin only allow request with valid X-Admin-Key header (ADMIN_API_KEY)
+func (h BaseHTTPHandler) RequireAdmin(fn HandlerFn) HandlerFn {
+    return func(ctx *app.Context) *server.Response {
+        adminKey := h.GetParam("admin-key")
+        requestKey := ctx.Request.Header.Get("X-Admin-Key")
+        if adminKey == "" || subtle.ConstantTimeCompare([]byte(adminKey), []byte(requestKey)) != 1 {
+            return h.AsJson(ctx, http.StatusForbidden, errs.ERROR_403, nil)
+        }
+        return fn(ctx)
+    }
+}
+
 // RunAction entry point to handle route.
 func (h BaseHTTPHandler) RunAction(fn HandlerFn) http.HandlerFunc {
     return h.CapturePanic(h.Execute(fn))
@@ -120,11 +160,11 @@ func (h BaseHTTPHandler) RunAction(fn HandlerFn) http.HandlerFunc {
 
 // SendPanicFlock used only for CapturePanic() to send some clue
 func (h BaseHTTPHandler) SendPanic(r *http.Request, errMsg string, err interface{}) {
-    logrus.Errorln(errMsg) // Need to notify
+    middleware.Logger(r.Context()).Errorln(errMsg) // Need to notify
 
     errStack, file := errs.StackAndFile(3)
     errInfo := fmt.Sprintf("\n:red_circle paylater-customer-api service \n* MUST FIX :boom: :boom: :boom: "+
-            "Panic Error: %v*\nRequest: %s %s\nAccess Token: %s\nFile: %s", err, r.Method, r.RequestURI, r.Header.Get("Authorization"), file)
+            "Panic Error: %v*\nRequest: %s %s\nRequest ID: %s\nFile: %s", err, r.Method, r.RequestURI, middleware.RequestID(r.Context()), file)
     msg := fmt.Sprintf("%s\n\nStack trace: \n%s...", errInfo, errStack)
 
     fmt.Println("\nPANIC:", msg)
@@ -134,25 +174,20 @@ func (h BaseHTTPHandler) SendPanic(r *http.Request, errMsg string, err interface
 func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
     return func(rw http.ResponseWriter, r *http.Request) {
 
-        // 1. Authentication
-        ctx, _ := f.Authentication(rw, r)
-        //if err != nil {
-        //    span, _ := tracer.StartSpanFromContext(r.Context(), "Unauthorized", tracer.ResourceName(r.RequestURI))
-        //    defer span.Finish(tracer.WithError(fmt.Errorf("accessToken: %s. Err: %v", r.Header.Get("accessToken"), err)))
-        //
-        //    WriteJSON(rw, http.StatusUnauthorized, server.MobileResponse{
-        //        Status:  http.StatusUnauthorized,
-        //        Message: err.Error(),
-        //        Data:    check, // Show more err for debug instead of empty []
-        //        Version: os.Getenv("APP_VERSION"),
-        //    })
-        //    return
-        //}
+        // 1. Authentication, token which is sent but invalid or expired is not a guest, client must login again
+        ctx, err := f.Authentication(rw, r)
+        action := handler
+        if err != nil {
+            action = func(ctx *app.Context) *server.Response {
+                return f.AsMobileJsonSetStatusCode(ctx, http.StatusUnauthorized, errs.ERROR_401, nil)
+            }
+        }
 
         // 2. Capture handler error to avoid infinite loop SendFlock
         defer func() {
             if err0 := recover(); err0 != nil {
                 f.SendPanic(r, "CaptureLastPanic NEED TO FIX NOW", err0)
+                f.recordRequest(ctx, http.StatusInternalServerError)
 
                 WriteJSON(rw, http.StatusInternalServerError,
                     "Request is halted unexpectedly, please contact the administrator.")
@@ -160,39 +195,9 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
         }()
 
         // 3. Process route action, and return *server.Response
-        resp := handler(ctx)
+        resp := action(ctx)
         httpStatus := resp.GetStatus()
-
-        // Start Timer
-        //start := carbon.Now().ToDateTimeString()
-
-        //header, err := ctx.GetAccess()
-        //if err != nil {
-        //    logrus.Error("Error getting header for sending to graylog : " + err.Error())
-        //}
-
-        //stringRequestHeader, err := jsoniter.MarshalToString(header)
-        //if err != nil {
-        //    logrus.Error("Error when marshaling request header to string for sending to graylog : " + err.Error())
-        //}
-        //
-        //stringResponse, err := jsoniter.MarshalToString(resp)
-        //if err != nil {
-        //    logrus.Error("Error when marshaling response to string for sending to graylog : " + err.Error())
-        //}
-        //
-        //logrus.WithFields(logrus.Fields{
-        //    "clientip":        ctx.GetIP(),
-        //    "timestamp":       start,
-        //    "method":          ctx.Request.Method,
-        //    "path":            ctx.GetURI(),
-        //    "statuscode":      httpStatus,
-        //    "latency":         ctx.GetElapsed().Milliseconds(),
-        //    "request_header":  stringRequestHeader,
-        //    "request":         ctx.GetRequestInfo(),
-        //    "response_header": rw.Header(),
-        //    "response":        stringResponse,
-        //}).Info()
+        f.recordRequest(ctx, httpStatus)
 
         // Except mobile with server.MobileStatusOKType always response httpStatus 200
         if httpStatus >= http.StatusInternalServerError && resp.ResponseType != server.MobileStatusOKType {
@@ -207,8 +212,10 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
         }
 
         if httpStatus >= 300 {
-            span, _ := tracer.StartSpanFromContext(ctx.Context(), "response", tracer.ResourceName(ctx.Request.RequestURI))
-            defer span.Finish(tracer.WithError(fmt.Errorf("%v %v", resp.Message, resp.Data)))
+            span, _ := tracing.StartSpan(ctx.Context(), "response", tracing.WithResource(ctx.Request.RequestURI),
+                tracing.WithTag(tracing.TagHTTPStatusCode, httpStatus))
+            span.SetError(fmt.Errorf("%v %v", resp.Message, resp.Data))
+            defer span.Finish()
         }
 
         if f.IsStaging() {
@@ -225,7 +232,8 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
                 WriteJSON(rw, httpStatus, server.WebResponse{
                     Status:  httpStatus,
                     Message: resp.Message,
-                    Data:    resp.Data})
+                    Data:    resp.Data,
+                    Errors:  resp.Errors})
             }
             return
         } else {
@@ -239,6 +247,7 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
                     Status:  httpStatus,
                     Message: resp.Message,
                     Data:    resp.Data,
+                    Errors:  resp.Errors,
                     Version: resp.Version})
                 return
 
@@ -250,6 +259,7 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
                     Status:  httpStatus,
                     Message: resp.Message,
                     Data:    resp.Data,
+                    Errors:  resp.Errors,
                     Version: resp.Version})
                 return
             }
@@ -259,8 +269,44 @@ func (f BaseHTTPHandler) Execute(handler HandlerFn) http.HandlerFunc {
     }
 }
 
+// recordRequest count and latency per route, tagged by the route template so path var doesn't create new metric
+func (f BaseHTTPHandler) recordRequest(ctx *app.Context, httpStatus int) {
+    if f.StatsdMonitoring == nil {
+        return
+    }
+
+    route := "unknown"
+    if current := mux.CurrentRoute(ctx.Request); current != nil {
+        if template, err := current.GetPathTemplate(); err == nil {
+            route = template
+        }
+    }
+    tags := []string{"method:" + ctx.Request.Method, "route:" + route, "status:" + strconv.Itoa(httpStatus)}
+    if err := f.StatsdMonitoring.Increment("http.request", tags, 1); err != nil {
+        logrus.Debugln("recordRequest: increment", err)
+    }
+    if err := f.StatsdMonitoring.Timing("http.request.latency", ctx.GetElapsed(), tags, 1); err != nil {
+        logrus.Debugln("recordRequest: timing", err)
+    }
+}
+
+// Authentication set member session from "Authorization: Bearer {token}". Request without token is a guest,
+// each route decide whether session is required
 func (h BaseHTTPHandler) Authentication(rw http.ResponseWriter, r *http.Request) (*app.Context, error) {
-    return app.NewContext(rw, r, h.IsStaging()), nil
+    ctx := app.NewContext(rw, r, h.IsStaging())
+
+    token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer"))
+    if token == "" {
+        return ctx, nil
+    }
+
+    session, err := security.ParseSession(token)
+    if err != nil {
+        return ctx, errs.NewUnautorizedError(err)
+    }
+
+    ctx.SetSsoID(session.UserId)
+    return ctx, nil
 }
 
 // CapturePanic Last layer to capture panic which might halt the whole application.
@@ -304,32 +350,33 @@ func WriteStream(rw http.ResponseWriter, fileName string, b bytes.Buffer) {
     }
 }
 
-// common missing id response, can be used by any endpoint route
-func (h BaseHTTPHandler) NotFoundHandler(w http.ResponseWriter, r *http.Request) {
-    WriteJSON(w, http.StatusBadRequest, server.NotAllowedMethod{
-        Name:    "Bad Request",
-        Message: "Missing required parameters: id",
-        Code:    0,
-        Status:  http.StatusBadRequest,
-    })
-}
-
-func (h BaseHTTPHandler) NotFoundHandlerNIK(w http.ResponseWriter, r *http.Request) {
-    WriteJSON(w, http.StatusBadRequest, server.NotAllowedMethod{
-        Name:    "Bad Request",
-        Message: "Missing required parameters: nik",
-        Code:    0,
-        Status:  http.StatusBadRequest,
+// NotFoundHandler response 404 for unknown route
+func (h BaseHTTPHandler) NotFoundHandler() http.Handler {
+    return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+        WriteJSON(rw, http.StatusNotFound, server.NotAllowedMethod{
+            Name:    "Not Found",
+            Message: fmt.Sprintf("Page not found: %s %s", r.Method, r.URL.Path),
+            Code:    0,
+            Status:  http.StatusNotFound,
+        })
     })
 }
 
-// common method not allowed response (405)
-func (h BaseHTTPHandler) MethodNotAllowedHandler() http.Handler {
+// MethodNotAllowedHandler response 405 with Allow header, allowed return the registered methods of the request path.
+// OPTIONS request is answered with 204 and the same Allow header
+func (h BaseHTTPHandler) MethodNotAllowedHandler(allowed func(r *http.Request) []string) http.Handler {
     return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+        methods := allowed(r)
+        rw.Header().Set("Allow", strings.Join(methods, ", "))
+
+        if r.Method == http.MethodOptions {
+            rw.WriteHeader(http.StatusNoContent)
+            return
+        }
+
         WriteJSON(rw, http.StatusMethodNotAllowed, server.NotAllowedMethod{
-            Name: "Method Not Allowed",
-            // hardcode the allowed method list for now
-            Message: fmt.Sprintf("Method Not Allowed. This URL can only handle the following request methods: %s", "GET"),
+            Name:    "Method Not Allowed",
+            Message: fmt.Sprintf("Method Not Allowed. This URL can only handle the following request methods: %s", strings.Join(methods, ", ")),
             Code:    0,
             Status:  http.StatusMethodNotAllowed,
         })
@@ -2,6 +2,7 @@ package firebase
 
 import (
 	"context"
+	"fmt"
 
 	"firebase.google.com/go/messaging"
 )
@@ -11,8 +12,12 @@ type firebaseClient struct {
 }
 
 func (f firebaseClient) Send(ctx context.Context, data *messaging.Message) (string, error) {
-	response, err := f.FcmClient.Send(context.Background(), data)
+	response, err := f.FcmClient.Send(ctx, data)
 	if err != nil {
+		// Invalid argument is also returned for a bad payload, the token might still be valid
+		if messaging.IsRegistrationTokenNotRegistered(err) {
+			err = fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		}
 		return "Error when sending payload fcm token", err
 	}
 	return response, err
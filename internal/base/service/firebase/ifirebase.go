@@ -2,10 +2,14 @@ package firebase
 
 import (
 	"context"
+	"errors"
 
 	"firebase.google.com/go/messaging"
 )
 
+// ErrInvalidToken is wrapped by Send when the token is not registered anymore, it should not be used again
+var ErrInvalidToken = errors.New("fcm token is not valid")
+
 type FirebaseClient interface {
 	Send(ctx context.Context, data *messaging.Message) (string, error)
 }
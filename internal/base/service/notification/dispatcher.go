@@ -0,0 +1,194 @@
+package notification
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultWorkers     = 2
+	DefaultQueueSize   = 100
+	DefaultMaxAttempts = 3
+	DefaultBackoff     = time.Second
+	DefaultTimeout     = 10 * time.Second
+	maxBackoff         = time.Minute
+)
+
+// Config of Dispatcher, zero value use the default
+type Config struct {
+	Workers     int
+	QueueSize   int
+	MaxAttempts int
+	Backoff     time.Duration // Delay of the first retry, doubled after each attempt
+	Timeout     time.Duration // Timeout of each attempt
+}
+
+// Dispatcher queue message and send them in background with retry
+type Dispatcher struct {
+	config    Config
+	templates *Templates
+	recorder  Recorder
+	channels  map[string]Channel
+
+	mu     sync.RWMutex
+	closed bool
+	queue  chan Message
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher start the workers, call Close to send queued message before exit. recorder can be nil.
+func NewDispatcher(config Config, templates *Templates, recorder Recorder, channels ...Channel) *Dispatcher {
+	if config.Workers <= 0 {
+		config.Workers = DefaultWorkers
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = DefaultMaxAttempts
+	}
+	if config.Backoff <= 0 {
+		config.Backoff = DefaultBackoff
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+	if templates == nil {
+		templates = NewTemplates()
+	}
+
+	d := &Dispatcher{
+		config:    config,
+		templates: templates,
+		recorder:  recorder,
+		channels:  make(map[string]Channel),
+		queue:     make(chan Message, config.QueueSize),
+	}
+	for _, channel := range channels {
+		d.channels[channel.Name()] = channel
+	}
+
+	for i := 0; i < config.Workers; i++ {
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			for msg := range d.queue {
+				d.Send(context.Background(), msg)
+			}
+		}()
+	}
+	return d
+}
+
+// Notify render the template of the event and queue the message
+func (d *Dispatcher) Notify(channel, eventType, recipient string, data interface{}) error {
+	subject, body, err := d.templates.Render(eventType, channel, data)
+	if err != nil {
+		return err
+	}
+	return d.Enqueue(Message{Channel: channel, EventType: eventType, Recipient: recipient, Subject: subject, Body: body})
+}
+
+// Enqueue message without waiting, ErrQueueFull when workers are behind
+func (d *Dispatcher) Enqueue(msg Message) error {
+	if _, ok := d.channels[msg.Channel]; !ok {
+		return ErrUnknownChannel
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+
+	select {
+	case d.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Send the message and wait, retried until MaxAttempts, permanent error or ctx is done. Result is recorded.
+func (d *Dispatcher) Send(ctx context.Context, msg Message) (err error) {
+	channel, ok := d.channels[msg.Channel]
+	if !ok {
+		return ErrUnknownChannel
+	}
+
+	attempts := 0
+	backoff := d.config.Backoff
+	for attempts < d.config.MaxAttempts {
+		attempts++
+		sendCtx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+		err = channel.Send(sendCtx, msg)
+		cancel()
+		if err == nil || IsPermanent(err) || attempts == d.config.MaxAttempts {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+		case <-time.After(backoff):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+
+	// Send cancelled by ctx is recorded all the same
+	recordCtx := ctx
+	if ctx.Err() != nil {
+		recordCtx = context.Background()
+	}
+	d.record(recordCtx, msg, attempts, err)
+	return
+}
+
+// Close stop accepting message and wait the queued message is sent
+func (d *Dispatcher) Close() {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	close(d.queue)
+	d.mu.Unlock()
+
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) record(ctx context.Context, msg Message, attempts int, err error) {
+	log := Log{
+		Channel:   msg.Channel,
+		EventType: msg.EventType,
+		Recipient: msg.Recipient,
+		Subject:   msg.Subject,
+		Status:    StatusSent,
+		Attempts:  attempts,
+	}
+	entry := logrus.WithFields(logrus.Fields{"channel": msg.Channel, "event_type": msg.EventType, "attempts": attempts})
+	if err != nil {
+		log.Status = StatusFailed
+		log.LastError = err.Error()
+		if len(log.LastError) > 255 {
+			log.LastError = log.LastError[:255]
+		}
+		entry.WithError(err).Errorln("Notification send failed")
+	}
+
+	if d.recorder == nil {
+		return
+	}
+	if errRecord := d.recorder.CreateNotificationLog(ctx, log); errRecord != nil {
+		entry.WithError(errRecord).Errorln("Notification log failed")
+	}
+}
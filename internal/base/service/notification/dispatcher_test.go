@@ -0,0 +1,180 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"store-api/internal/base/service/firebase"
+	"store-api/pkg/httpclient"
+
+	"firebase.google.com/go/messaging"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryRecorder struct {
+	mu   sync.Mutex
+	logs []Log
+}
+
+func (r *memoryRecorder) CreateNotificationLog(ctx context.Context, model Log) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.logs = append(r.logs, model)
+	return nil
+}
+
+// provider respond with the status of each call in order, the last one is repeated
+type provider struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []map[string]interface{}
+}
+
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var body map[string]interface{}
+	json.NewDecoder(r.Body).Decode(&body)
+	body["authorization"] = r.Header.Get("Authorization")
+	p.requests = append(p.requests, body)
+
+	status := p.statuses[0]
+	if len(p.statuses) > 1 {
+		p.statuses = p.statuses[1:]
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"message": "%s"}`, http.StatusText(status))
+}
+
+func newTestDispatcher(recorder Recorder, channels ...Channel) *Dispatcher {
+	templates := NewTemplates()
+	templates.MustRegister("order.paid", ChannelEmail, "Order {{.TrxCode}} is paid", "Thank you {{.Name}}")
+	templates.MustRegister("order.paid", ChannelInApp, "Payment success", "Order {{.TrxCode}} is paid")
+	return NewDispatcher(Config{Backoff: time.Millisecond}, templates, recorder, channels...)
+}
+
+type order struct {
+	TrxCode string
+	Name    string
+}
+
+func TestDispatcher_Notify(t *testing.T) {
+	p := &provider{statuses: []int{http.StatusOK}}
+	server := httptest.NewServer(p)
+	defer server.Close()
+
+	recorder := &memoryRecorder{}
+	httpClient := httpclient.New().CreateClient()
+	d := newTestDispatcher(recorder,
+		NewEmailChannel(server.URL, "store@example.com", map[string]string{"Authorization": "Bearer key"}, httpClient),
+		NewInAppChannel(server.URL, nil, httpClient))
+
+	assert.NoError(t, d.Notify(ChannelEmail, "order.paid", "member@example.com", order{TrxCode: "TRX-1", Name: "Ana"}))
+	assert.NoError(t, d.Notify(ChannelInApp, "order.paid", "7", order{TrxCode: "TRX-1"}))
+	assert.ErrorIs(t, d.Notify(ChannelPush, "order.paid", "token", order{}), ErrNoTemplate)
+	assert.ErrorIs(t, d.Notify(ChannelEmail, "order.created", "member@example.com", order{}), ErrNoTemplate)
+	d.Close()
+
+	assert.ErrorIs(t, d.Notify(ChannelInApp, "order.paid", "7", order{}), ErrClosed)
+	assert.Len(t, p.requests, 2)
+	assert.Len(t, recorder.logs, 2)
+	for _, log := range recorder.logs {
+		assert.Equal(t, StatusSent, log.Status)
+		assert.Equal(t, 1, log.Attempts)
+	}
+
+	// Workers send concurrently, request order is not known
+	var email, inApp map[string]interface{}
+	for _, request := range p.requests {
+		if _, ok := request["to"]; ok {
+			email = request
+		} else {
+			inApp = request
+		}
+	}
+	assert.Equal(t, "store@example.com", email["from"])
+	assert.Equal(t, "member@example.com", email["to"])
+	assert.Equal(t, "Order TRX-1 is paid", email["subject"])
+	assert.Equal(t, "Thank you Ana", email["body"])
+	assert.Equal(t, "Bearer key", email["authorization"])
+	assert.Equal(t, "7", inApp["member_id"])
+	assert.Equal(t, "Payment success", inApp["title"])
+}
+
+func TestDispatcher_Send(t *testing.T) {
+	httpClient := httpclient.New().CreateClient()
+	msg := Message{Channel: ChannelInApp, EventType: "order.paid", Recipient: "7", Subject: "Payment success"}
+
+	tests := []struct {
+		name     string
+		statuses []int
+		status   string
+		attempts int
+	}{
+		{"Sent", []int{http.StatusOK}, StatusSent, 1},
+		{"Retried until sent", []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, StatusSent, 3},
+		{"Given up after max attempts", []int{http.StatusBadGateway}, StatusFailed, DefaultMaxAttempts},
+		{"Rejected is not retried", []int{http.StatusBadRequest}, StatusFailed, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &provider{statuses: tt.statuses}
+			server := httptest.NewServer(p)
+			defer server.Close()
+
+			recorder := &memoryRecorder{}
+			d := newTestDispatcher(recorder, NewInAppChannel(server.URL, nil, httpClient))
+			defer d.Close()
+
+			err := d.Send(context.Background(), msg)
+			assert.Equal(t, tt.status == StatusFailed, err != nil)
+			assert.Len(t, p.requests, tt.attempts)
+			assert.Equal(t, []Log{{Channel: ChannelInApp, EventType: "order.paid", Recipient: "7", Subject: "Payment success",
+				Status: tt.status, Attempts: tt.attempts, LastError: errorString(err)}}, recorder.logs)
+		})
+	}
+
+	t.Run("Unknown channel", func(t *testing.T) {
+		d := newTestDispatcher(nil)
+		defer d.Close()
+		assert.ErrorIs(t, d.Send(context.Background(), msg), ErrUnknownChannel)
+		assert.ErrorIs(t, d.Enqueue(msg), ErrUnknownChannel)
+	})
+}
+
+type fakeFirebase struct {
+	err error
+}
+
+func (f fakeFirebase) Send(ctx context.Context, data *messaging.Message) (string, error) {
+	return "", f.err
+}
+
+func TestPushChannel_Send(t *testing.T) {
+	msg := Message{Channel: ChannelPush, Recipient: "token"}
+
+	err := PushChannel{Client: fakeFirebase{err: fmt.Errorf("%w: unregistered", firebase.ErrInvalidToken)}}.Send(context.Background(), msg)
+	assert.True(t, IsPermanent(err))
+
+	err = PushChannel{Client: fakeFirebase{err: errors.New("unavailable")}}.Send(context.Background(), msg)
+	assert.Error(t, err)
+	assert.False(t, IsPermanent(err))
+
+	assert.NoError(t, PushChannel{Client: fakeFirebase{}}.Send(context.Background(), msg))
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
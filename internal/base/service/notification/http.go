@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"store-api/pkg/httpclient"
+)
+
+// HTTPChannel post the message as json to the notification provider
+type HTTPChannel struct {
+	name       string
+	url        string
+	headers    map[string]string
+	httpClient httpclient.Client
+	encode     func(msg Message) interface{}
+}
+
+type (
+	emailRequest struct {
+		From      string `json:"from"`
+		To        string `json:"to"`
+		Subject   string `json:"subject"`
+		Body      string `json:"body"`
+		EventType string `json:"event_type"`
+	}
+
+	inAppRequest struct {
+		MemberID  string            `json:"member_id"`
+		Title     string            `json:"title"`
+		Body      string            `json:"body"`
+		EventType string            `json:"event_type"`
+		Data      map[string]string `json:"data,omitempty"`
+	}
+)
+
+// NewEmailChannel send email from sender, recipient is the email address
+func NewEmailChannel(url, sender string, headers map[string]string, httpClient httpclient.Client) *HTTPChannel {
+	return &HTTPChannel{name: ChannelEmail, url: url, headers: headers, httpClient: httpClient,
+		encode: func(msg Message) interface{} {
+			return emailRequest{From: sender, To: msg.Recipient, Subject: msg.Subject, Body: msg.Body, EventType: msg.EventType}
+		}}
+}
+
+// NewInAppChannel add the message to member inbox, recipient is the member id
+func NewInAppChannel(url string, headers map[string]string, httpClient httpclient.Client) *HTTPChannel {
+	return &HTTPChannel{name: ChannelInApp, url: url, headers: headers, httpClient: httpClient,
+		encode: func(msg Message) interface{} {
+			return inAppRequest{MemberID: msg.Recipient, Title: msg.Subject, Body: msg.Body, EventType: msg.EventType,
+				Data: msg.Data}
+		}}
+}
+
+func (c *HTTPChannel) Name() string {
+	return c.name
+}
+
+// Send the message, 4xx response is a permanent error. ctx is only used for the trace, timeout is the client's own.
+func (c *HTTPChannel) Send(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	headers := map[string]string{"Content-Type": "application/json"}
+	for k, v := range c.headers {
+		headers[k] = v
+	}
+
+	var response map[string]interface{}
+	httpStatus, err := c.httpClient.WithContext(ctx).Post(c.url, c.encode(msg), headers, &response)
+	if err != nil {
+		return err
+	}
+	if httpStatus >= http.StatusBadRequest {
+		err = fmt.Errorf("%s provider response %d: %v", c.name, httpStatus, response["message"])
+		if httpStatus < http.StatusInternalServerError && httpStatus != http.StatusTooManyRequests {
+			return Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package notification send templated message to member through email, in-app and push channel.
+//
+// Message is queued by Dispatcher and sent by its workers, failed send is retried with backoff until
+// MaxAttempts or a permanent error. Every send is recorded by the Recorder:
+//
+//	templates := notification.NewTemplates()
+//	templates.MustRegister("order.paid", notification.ChannelInApp, "Payment success", "Order {{.TrxCode}} is paid")
+//	dispatcher := notification.NewDispatcher(notification.Config{}, templates, recorder, inAppChannel)
+//	defer dispatcher.Close()
+//	err := dispatcher.Notify(notification.ChannelInApp, "order.paid", memberId, order)
+package notification
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	TableNameLog = "notification_log"
+
+	ChannelEmail = "email"
+	ChannelInApp = "in_app"
+	ChannelPush  = "push"
+
+	StatusSent   = "sent"
+	StatusFailed = "failed"
+)
+
+var (
+	ErrNoTemplate     = errors.New("notification template is not registered")
+	ErrUnknownChannel = errors.New("notification channel is not registered")
+	ErrQueueFull      = errors.New("notification queue is full")
+	ErrClosed         = errors.New("notification dispatcher is closed")
+)
+
+// Message rendered for a recipient of the channel
+type Message struct {
+	Channel   string
+	EventType string
+	Recipient string // Email address, member id or FCM token, depends on the channel
+	Subject   string
+	Body      string
+	Data      map[string]string // Extra data of in-app and push message
+}
+
+// Channel deliver the message, error wrapped by Permanent is not retried
+type Channel interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// Notifier render the template of the event and queue it
+type Notifier interface {
+	Notify(channel, eventType, recipient string, data interface{}) error
+}
+
+// Sender send the rendered message and wait, ex: Dispatcher. Send is recorded the same as the queued message.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Log of a send, written once the message is sent or given up
+type Log struct {
+	ID          int64     `json:"id" db:"id"`
+	Channel     string    `json:"channel" db:"channel"`
+	EventType   string    `json:"event_type" db:"event_type"`
+	Recipient   string    `json:"recipient" db:"recipient"`
+	Subject     string    `json:"subject" db:"subject"`
+	Status      string    `json:"status" db:"status"`
+	Attempts    int       `json:"attempts" db:"attempts"`
+	LastError   string    `json:"last_error" db:"last_error"`
+	CreatedDate time.Time `json:"created_date" db:"created_date"`
+}
+
+func (m *Log) TableName() string {
+	return TableNameLog
+}
+
+// Recorder store the send log, ex: StoreRepository
+type Recorder interface {
+	CreateNotificationLog(ctx context.Context, model Log) error
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent error is not retried, ex: invalid recipient
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err: err}
+}
+
+func IsPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
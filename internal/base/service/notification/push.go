@@ -0,0 +1,32 @@
+package notification
+
+import (
+	"context"
+	"errors"
+
+	"store-api/internal/base/service/firebase"
+
+	"firebase.google.com/go/messaging"
+)
+
+// PushChannel send FCM notification, recipient is the registration token
+type PushChannel struct {
+	Client firebase.FirebaseClient
+}
+
+func (c PushChannel) Name() string {
+	return ChannelPush
+}
+
+// Send the notification, invalid token is a permanent error
+func (c PushChannel) Send(ctx context.Context, msg Message) error {
+	_, err := c.Client.Send(ctx, &messaging.Message{
+		Token:        msg.Recipient,
+		Notification: &messaging.Notification{Title: msg.Subject, Body: msg.Body},
+		Data:         msg.Data,
+	})
+	if errors.Is(err, firebase.ErrInvalidToken) {
+		return Permanent(err)
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"text/template"
+)
+
+// Templates subject and body of each event type and channel, executed with the event payload
+type Templates struct {
+	mu        sync.RWMutex
+	templates map[string]entry
+}
+
+type entry struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func NewTemplates() *Templates {
+	return &Templates{templates: make(map[string]entry)}
+}
+
+// Register parse the template of the event channel, register again replace it
+func (t *Templates) Register(eventType, channel, subject, body string) error {
+	subjectTemplate, err := template.New(eventType + ".subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return err
+	}
+	bodyTemplate, err := template.New(eventType + ".body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.templates[key(eventType, channel)] = entry{subject: subjectTemplate, body: bodyTemplate}
+	return nil
+}
+
+// MustRegister panic when the template cannot be parsed, for template defined in code
+func (t *Templates) MustRegister(eventType, channel, subject, body string) {
+	if err := t.Register(eventType, channel, subject, body); err != nil {
+		panic(err)
+	}
+}
+
+// Render subject and body, ErrNoTemplate when the event is not sent to the channel
+func (t *Templates) Render(eventType, channel string, data interface{}) (subject string, body string, err error) {
+	t.mu.RLock()
+	tmpl, ok := t.templates[key(eventType, channel)]
+	t.mu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w: %s %s", ErrNoTemplate, channel, eventType)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.subject.Execute(&buf, data); err != nil {
+		return
+	}
+	subject = buf.String()
+
+	buf.Reset()
+	if err = tmpl.body.Execute(&buf, data); err != nil {
+		return
+	}
+	body = buf.String()
+	return
+}
+
+func key(eventType, channel string) string {
+	return channel + ":" + eventType
+}
@@ -0,0 +1,66 @@
+package redisser
+
+import (
+	"context"
+	"strings"
+
+	"github.com/go-redis/redis/v8"
+
+	"store-api/pkg/tracing"
+)
+
+// tracingHook span of each command with the global tracer, the key and value are not tagged
+type tracingHook struct{}
+
+type spanKey struct{}
+
+func (tracingHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return startSpan(ctx, cmd.Name(), 1), nil
+}
+
+func (tracingHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	finishSpan(ctx, cmd.Err())
+	return nil
+}
+
+func (tracingHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	names := make([]string, 0, len(cmds))
+	for _, cmd := range cmds {
+		names = append(names, cmd.Name())
+	}
+	return startSpan(ctx, strings.Join(names, " "), len(cmds)), nil
+}
+
+func (tracingHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+	for _, cmd := range cmds {
+		if cmd.Err() != nil {
+			err = cmd.Err()
+			break
+		}
+	}
+	finishSpan(ctx, err)
+	return nil
+}
+
+func startSpan(ctx context.Context, resource string, length int) context.Context {
+	span, ctx := tracing.StartSpan(ctx, "redis.command",
+		tracing.WithKind(tracing.KindClient),
+		tracing.WithResource(resource),
+		tracing.WithTag(tracing.TagDBSystem, "redis"),
+		tracing.WithTag("redis.pipeline_length", length),
+	)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// finishSpan redis.Nil is a miss, not an error
+func finishSpan(ctx context.Context, err error) {
+	span, ok := ctx.Value(spanKey{}).(tracing.Span)
+	if !ok {
+		return
+	}
+	if err != nil && err != redis.Nil {
+		span.SetError(err)
+	}
+	span.Finish()
+}
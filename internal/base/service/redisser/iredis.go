@@ -16,4 +16,6 @@ type RedisClient interface {
 
 	SetBit(ctx context.Context, key string, offset int64, value int) (int64, error)
 	GetAllBits(ctx context.Context, key string) ([]bool, error)
+
+	Close() error
 }
@@ -83,6 +83,13 @@ func bitstringToBool(str string) []bool {
 	return s
 }
 
+// Close the connection pool
+func (r redisClient) Close() error {
+	return r.Redis.Close()
+}
+
+// NewRedisClient the command is traced by the tracer set with tracing.SetTracer
 func NewRedisClient(redis *redis.Client) RedisClient {
+	redis.AddHook(tracingHook{})
 	return &redisClient{Redis: redis}
 }
@@ -8,6 +8,7 @@ type Cart struct {
     ID        int  `json:"id" db:"id"`
     MemberID  int  `json:"member_id" db:"member_id"`
     ProductID int  `json:"product_id" db:"product_id"`
+    VariantID int  `json:"variant_id" db:"variant_id"`
     Quantity  int  `json:"quantity" db:"quantity"`
     IsActive  bool `json:"is_active" db:"is_active"`
 }
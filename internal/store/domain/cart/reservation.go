@@ -0,0 +1,28 @@
+package cart
+
+import "time"
+
+const (
+    TableNameReservation = "cart_reservation"
+
+    ReservationActive    = "active"
+    ReservationReleased  = "released"  // Removed from cart
+    ReservationExpired   = "expired"   // Released by the worker after TTL
+    ReservationConverted = "converted" // Checked out
+)
+
+type Reservation struct {
+    ID          int       `json:"id" db:"id"`
+    MemberID    int       `json:"member_id" db:"member_id"`
+    ProductID   int       `json:"product_id" db:"product_id"`
+    VariantID   int       `json:"variant_id" db:"variant_id"`
+    Quantity    int       `json:"quantity" db:"quantity"`
+    Status      string    `json:"status" db:"status"`
+    ExpiredAt   time.Time `json:"expired_at" db:"expired_at"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+    UpdatedDate time.Time `json:"updated_date" db:"updated_date"`
+}
+
+func (m *Reservation) TableName() string {
+    return TableNameReservation
+}
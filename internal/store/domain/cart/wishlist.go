@@ -0,0 +1,19 @@
+package cart
+
+import "time"
+
+const (
+    TableNameWishlist = "wishlist"
+)
+
+type Wishlist struct {
+    ID          int       `json:"id" db:"id"`
+    MemberID    int       `json:"member_id" db:"member_id"`
+    ProductID   int       `json:"product_id" db:"product_id"`
+    VariantID   int       `json:"variant_id" db:"variant_id"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+}
+
+func (m *Wishlist) TableName() string {
+    return TableNameWishlist
+}
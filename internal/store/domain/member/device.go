@@ -0,0 +1,28 @@
+package member
+
+import "time"
+
+const (
+    TableNameDevice = "member_device"
+
+    PlatformAndroid = "android"
+    PlatformIOS     = "ios"
+    PlatformWeb     = "web"
+)
+
+type Device struct {
+    ID          int       `json:"id" db:"id"`
+    MemberID    int       `json:"member_id" db:"member_id"`
+    Token       string    `json:"token" db:"token"`
+    Platform    string    `json:"platform" db:"platform"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+    UpdatedDate time.Time `json:"updated_date" db:"updated_date"`
+}
+
+func (m *Device) TableName() string {
+    return TableNameDevice
+}
+
+func IsValidPlatform(platform string) bool {
+    return platform == PlatformAndroid || platform == PlatformIOS || platform == PlatformWeb
+}
@@ -1,6 +1,9 @@
 package member
 
-import "time"
+import (
+    "net/mail"
+    "time"
+)
 
 const (
     TableName = "member"
@@ -18,3 +21,12 @@ type Member struct {
 func (m *Member) TableName() string {
     return TableName
 }
+
+// EmailAddress of the member, username is used when member sign up with email. Empty when it is not an email.
+func (m *Member) EmailAddress() string {
+    address, err := mail.ParseAddress(m.Username)
+    if err != nil || address.Address != m.Username {
+        return ""
+    }
+    return address.Address
+}
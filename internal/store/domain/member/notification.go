@@ -0,0 +1,33 @@
+package member
+
+import "time"
+
+const (
+    TableNameNotificationOptOut = "member_notification_opt_out"
+
+    ChannelPush  = "push"
+    ChannelInApp = "in_app"
+    ChannelEmail = "email"
+
+    CategoryOrder    = "order"    // Order status
+    CategoryWishlist = "wishlist" // Wishlist product back in stock
+)
+
+type NotificationOptOut struct {
+    MemberID    int       `json:"member_id" db:"member_id"`
+    Channel     string    `json:"channel" db:"channel"`
+    Category    string    `json:"category" db:"category"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+}
+
+func (m *NotificationOptOut) TableName() string {
+    return TableNameNotificationOptOut
+}
+
+func IsValidChannel(channel string) bool {
+    return channel == ChannelPush || channel == ChannelInApp || channel == ChannelEmail
+}
+
+func IsValidCategory(category string) bool {
+    return category == CategoryOrder || category == CategoryWishlist
+}
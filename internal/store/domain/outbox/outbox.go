@@ -0,0 +1,107 @@
+package outbox
+
+import (
+    "database/sql"
+    "encoding/json"
+    "strconv"
+    "time"
+
+    modelProduct "store-api/internal/store/domain/product"
+    "store-api/pkg/messaging/event"
+)
+
+const (
+    TableName = "outbox_event"
+
+    TopicOrders = "store.orders"
+    TopicStock  = "store.stock"
+
+    EventOrderCreated = "order.created"
+    EventOrderPaid    = "order.paid"
+    EventStockChanged = "stock.changed"
+    EventBackInStock  = "stock.back_in_stock" // Variant stock goes from 0 to positive by restock or adjustment
+
+    SchemaVersion = 1 // Version of the payload, increase on breaking change
+
+    StatusPending = "pending"
+    StatusSent    = "sent"
+    StatusFailed  = "failed" // Max attempts reached, need manual action
+)
+
+type Event struct {
+    ID          int64        `json:"id" db:"id"`
+    Topic       string       `json:"topic" db:"topic"`
+    EventType   string       `json:"event_type" db:"event_type"`
+    AggregateID string       `json:"aggregate_id" db:"aggregate_id"`
+    Payload     []byte       `json:"payload" db:"payload"`
+    Status      string       `json:"status" db:"status"`
+    Attempts    int          `json:"attempts" db:"attempts"`
+    LastError   string       `json:"last_error" db:"last_error"`
+    AvailableAt time.Time    `json:"available_at" db:"available_at"`
+    SentAt      sql.NullTime `json:"sent_at" db:"sent_at"`
+    CreatedDate time.Time    `json:"created_date" db:"created_date"`
+}
+
+func (m *Event) TableName() string {
+    return TableName
+}
+
+// NewEvent pending event, payload is encoded as json
+func NewEvent(topic, eventType, aggregateId string, payload interface{}) (Event, error) {
+    body, err := json.Marshal(payload)
+    if err != nil {
+        return Event{}, err
+    }
+
+    return Event{
+        Topic:       topic,
+        EventType:   eventType,
+        AggregateID: aggregateId,
+        Payload:     body,
+        Status:      StatusPending,
+        AvailableAt: time.Now(),
+    }, nil
+}
+
+// Envelope published to kafka, id of the outbox is the event id so consumer can dedupe redelivery
+func (m Event) Envelope(source string) event.Envelope {
+    return event.Envelope{
+        ID:         strconv.FormatInt(m.ID, 10),
+        Type:       m.EventType,
+        Version:    SchemaVersion,
+        OccurredAt: m.CreatedDate,
+        Source:     source,
+        Payload:    json.RawMessage(m.Payload),
+    }
+}
+
+// RegisterEvents payload of the events published by the store, for consumer decoding them
+func RegisterEvents(registry *event.Registry) {
+    registry.Register(EventOrderCreated, SchemaVersion, func() interface{} { return &OrderPayload{} })
+    registry.Register(EventOrderPaid, SchemaVersion, func() interface{} { return &OrderPayload{} })
+    registry.Register(EventStockChanged, SchemaVersion, func() interface{} { return &StockPayload{} })
+    registry.Register(EventBackInStock, SchemaVersion, func() interface{} { return &StockPayload{} })
+    registry.Register(modelProduct.LowStockEvent, modelProduct.LowStockSchemaVersion,
+        func() interface{} { return &modelProduct.LowStockAlert{} })
+}
+
+type OrderPayload struct {
+    TransactionID int     `json:"transaction_id"`
+    TrxCode       string  `json:"trx_code"`
+    MemberID      int     `json:"member_id"`
+    ProductID     int     `json:"product_id"`
+    VariantID     int     `json:"variant_id"`
+    Quantity      int     `json:"quantity"`
+    Amount        float64 `json:"amount"`
+    Status        string  `json:"status"`
+}
+
+type StockPayload struct {
+    MovementID int    `json:"movement_id"`
+    ProductID  int    `json:"product_id"`
+    VariantID  int    `json:"variant_id"`
+    Type       string `json:"type"`
+    Quantity   int    `json:"quantity"`
+    StockAfter int    `json:"stock_after"`
+    Reference  string `json:"reference"`
+}
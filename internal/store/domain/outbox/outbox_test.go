@@ -0,0 +1,40 @@
+package outbox
+
+import (
+    "testing"
+    "time"
+
+    "store-api/pkg/messaging/event"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestEvent_Envelope(t *testing.T) {
+    order := OrderPayload{TransactionID: 1, TrxCode: "TRX-1", MemberID: 7, ProductID: 1, VariantID: 2, Quantity: 2,
+        Amount: 150000, Status: "pending"}
+    outbox, err := NewEvent(TopicOrders, EventOrderCreated, "1", order)
+    assert.Nil(t, err)
+    outbox.ID = 5
+    outbox.CreatedDate = time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+    registry := event.NewRegistry()
+    RegisterEvents(registry)
+
+    t.Run("Json round trip", func(t *testing.T) {
+        body, err := event.JSON.Marshal(outbox.Envelope("store-api"))
+        assert.Nil(t, err)
+
+        env, err := registry.Decode(event.JSON.ContentType(), body)
+        assert.Nil(t, err)
+        assert.Equal(t, "5", env.ID)
+        assert.Equal(t, EventOrderCreated, env.Type)
+        assert.Equal(t, SchemaVersion, env.Version)
+        assert.True(t, outbox.CreatedDate.Equal(env.OccurredAt))
+        assert.Equal(t, &order, env.Payload)
+    })
+
+    t.Run("Protobuf reject the json payload", func(t *testing.T) {
+        _, err := event.Protobuf.Marshal(outbox.Envelope("store-api"))
+        assert.ErrorIs(t, err, event.ErrUnsupportedPayload, "Fail at publish, consumer cannot decode it")
+    })
+}
@@ -0,0 +1,20 @@
+package product
+
+import "time"
+
+const (
+    LowStockTopic = "store.stock.low" // Default kafka topic of LowStockAlert
+    LowStockEvent = "stock.low"
+
+    LowStockSchemaVersion = 1
+)
+
+// LowStockAlert published once when a sale take product stock below its reorder threshold
+type LowStockAlert struct {
+    ProductID        int       `json:"product_id"`
+    Name             string    `json:"name"`
+    Category         string    `json:"category"`
+    Stock            int       `json:"stock"`
+    ReorderThreshold int       `json:"reorder_threshold"`
+    AlertedAt        time.Time `json:"alerted_at"`
+}
@@ -0,0 +1,7 @@
+package product
+
+// CatalogueRow is one variant together with its product, a line of bulk import / export
+type CatalogueRow struct {
+    Product Product
+    Variant Variant
+}
@@ -0,0 +1,21 @@
+package product
+
+import "time"
+
+const (
+    TableNameImage = "product_image"
+
+    ImageFolder = "product" // S3 folder of product images
+)
+
+type Image struct {
+    ID          int       `json:"id" db:"id"`
+    ProductID   int       `json:"product_id" db:"product_id"`
+    S3Name      string    `json:"s3_name" db:"s3_name"`
+    Position    int       `json:"position" db:"position"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+}
+
+func (m *Image) TableName() string {
+    return TableNameImage
+}
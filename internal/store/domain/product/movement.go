@@ -0,0 +1,47 @@
+package product
+
+import (
+    "errors"
+    "time"
+)
+
+const (
+    TableNameStockMovement = "stock_movement"
+
+    MovementSale        = "sale"
+    MovementRefund      = "refund"
+    MovementRestock     = "restock"
+    MovementAdjustment  = "adjustment"
+    MovementReservation = "reservation"
+)
+
+var ErrInsufficientStock = errors.New("Quantity not enough")
+
+// StockMovement append only ledger of variant stock. Quantity is signed (sale is negative),
+// sum of quantity of a variant is always its stock.
+type StockMovement struct {
+    ID          int       `json:"id" db:"id"`
+    ProductID   int       `json:"product_id" db:"product_id"`
+    VariantID   int       `json:"variant_id" db:"variant_id"`
+    Type        string    `json:"type" db:"type"`
+    Quantity    int       `json:"quantity" db:"quantity"`
+    StockAfter  int       `json:"stock_after" db:"stock_after"`
+    Reference   string    `json:"reference" db:"reference"`
+    Note        string    `json:"note" db:"note"`
+    CreatedDate time.Time `json:"created_date" db:"created_date"`
+}
+
+func (m *StockMovement) TableName() string {
+    return TableNameStockMovement
+}
+
+// IsRestockMovement stock brought in by admin or import, back in stock is notified only for it. Released hold
+// and refund give back stock taken a moment ago, notifying them would spam the wishlist on every cart churn.
+func IsRestockMovement(movementType string) bool {
+    return movementType == MovementRestock || movementType == MovementAdjustment
+}
+
+// IsManualMovement type which can be created by admin, others are written by the order flow
+func IsManualMovement(movementType string) bool {
+    return movementType == MovementRestock || movementType == MovementAdjustment || movementType == MovementRefund
+}
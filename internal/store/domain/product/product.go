@@ -5,13 +5,20 @@ const (
 )
 
 type Product struct {
-    ID       int     `json:"id" db:"id"`
-    Name     string  `json:"name" db:"name"`
-    Category string  `json:"category" db:"category"`
-    Price    float64 `json:"price" db:"price"`
-    Stock    int     `json:"stock" db:"stock"`
+    ID               int     `json:"id" db:"id"`
+    Name             string  `json:"name" db:"name"`
+    Category         string  `json:"category" db:"category"`
+    Price            float64 `json:"price" db:"price"`
+    Stock            int     `json:"stock" db:"stock"`
+    ReorderThreshold int     `json:"reorder_threshold" db:"reorder_threshold"` // 0 disable low stock alert
+    LowStockAlerted  bool    `json:"low_stock_alerted" db:"low_stock_alerted"`
 }
 
 func (m *Product) TableName() string {
     return TableName
 }
+
+// IsLowStock stock is below reorder threshold
+func (m Product) IsLowStock() bool {
+    return m.ReorderThreshold > 0 && m.Stock < m.ReorderThreshold
+}
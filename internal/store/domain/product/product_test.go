@@ -1 +1,33 @@
 package product
+
+import (
+    "database/sql"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestVariant_GetPrice(t *testing.T) {
+    product := Product{ID: 1, Price: 100}
+
+    t.Run("Fallback to product price", func(t *testing.T) {
+        variant := Variant{ProductID: 1}
+        assert.Equal(t, 100.0, variant.GetPrice(product))
+    })
+
+    t.Run("Use price override", func(t *testing.T) {
+        variant := Variant{ProductID: 1, PriceOverride: sql.NullFloat64{Float64: 150, Valid: true}}
+        assert.Equal(t, 150.0, variant.GetPrice(product))
+    })
+
+    t.Run("Zero price override is still an override", func(t *testing.T) {
+        variant := Variant{ProductID: 1, PriceOverride: sql.NullFloat64{Float64: 0, Valid: true}}
+        assert.Equal(t, 0.0, variant.GetPrice(product))
+    })
+}
+
+func TestProduct_IsLowStock(t *testing.T) {
+    assert.False(t, Product{Stock: 0, ReorderThreshold: 0}.IsLowStock(), "Threshold 0 disable the alert")
+    assert.False(t, Product{Stock: 5, ReorderThreshold: 5}.IsLowStock())
+    assert.True(t, Product{Stock: 4, ReorderThreshold: 5}.IsLowStock())
+}
@@ -0,0 +1,32 @@
+package product
+
+import "database/sql"
+
+const (
+    TableNameVariant = "product_variant"
+)
+
+// Variant is a sellable unit of a product (size, colour, ...). Stock is kept per variant,
+// product.stock is only the sum of its variants.
+type Variant struct {
+    ID            int             `json:"id" db:"id"`
+    ProductID     int             `json:"product_id" db:"product_id"`
+    SKU           string          `json:"sku" db:"sku"`
+    Size          string          `json:"size" db:"size"`
+    Colour        string          `json:"colour" db:"colour"`
+    PriceOverride sql.NullFloat64 `json:"price_override" db:"price_override"`
+    Stock         int             `json:"stock" db:"stock"`
+    IsActive      bool            `json:"is_active" db:"is_active"`
+}
+
+func (m *Variant) TableName() string {
+    return TableNameVariant
+}
+
+// GetPrice return price override of the variant, fallback to product price
+func (m Variant) GetPrice(product Product) float64 {
+    if m.PriceOverride.Valid {
+        return m.PriceOverride.Float64
+    }
+    return product.Price
+}
@@ -1,15 +1,46 @@
 package transaction
 
-import "time"
+import (
+    "errors"
+    "time"
+)
 
 const (
     TableName = "transaction"
+
+    StatusPending = "pending" // Waiting payment
+    StatusSuccess = "success"
+    StatusFailed  = "failed"
 )
 
+var ErrInvalidStatus = errors.New("Transaction status cannot be changed")
+
+// CanChangeStatus only pending transaction can be paid or failed, same status is accepted so update is idempotent
+func CanChangeStatus(from, to string) bool {
+    if from == to {
+        return true
+    }
+    return from == StatusPending && (to == StatusSuccess || to == StatusFailed)
+}
+
+// CurrentOrder order of the trx code from its rows, newest first. Failed attempt of a retried trx code share the
+// trx code, so the pending then the paid order is picked before any failed row
+func CurrentOrder(transactions []Transactions) (result Transactions, ok bool) {
+    for _, status := range []string{StatusPending, StatusSuccess, StatusFailed} {
+        for _, transaction := range transactions {
+            if transaction.Status == status {
+                return transaction, true
+            }
+        }
+    }
+    return
+}
+
 type Transactions struct {
     ID           int       `json:"id" db:"id"`
     MemberID     int       `json:"member_id" db:"member_id"`
     ProductID    int       `json:"product_id" db:"product_id"`
+    VariantID    int       `json:"variant_id" db:"variant_id"`
     TrxCode      string    `json:"trx_code" db:"trx_code"`
     ChannelID    string    `json:"channel_id" db:"channel_id"`
     ChannelRefNo string    `json:"channel_ref_no" db:"channel_ref_no"`
@@ -0,0 +1,90 @@
+package handler
+
+import (
+    "context"
+    "net/http"
+
+    "store-api/app/consumer"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+    "store-api/internal/store/service"
+    "store-api/pkg/messaging/event"
+
+    jsoniter "github.com/json-iterator/go"
+    "github.com/pkg/errors"
+    "github.com/segmentio/kafka-go"
+    "github.com/sirupsen/logrus"
+)
+
+// ConsumerHandler handles kafka message
+type ConsumerHandler struct {
+    StoreService service.StoreService
+    Events       *event.Registry
+}
+
+// NewConsumerHandler creates new consumer handler
+func NewConsumerHandler(storeService service.StoreService) *ConsumerHandler {
+    events := event.NewRegistry()
+    modelOutbox.RegisterEvents(events)
+    return &ConsumerHandler{StoreService: storeService, Events: events}
+}
+
+// PaymentStatus apply payment result of the channel. Invalid message is not retried.
+func (h ConsumerHandler) PaymentStatus(ctx context.Context, msg kafka.Message) error {
+    request := presenterTransaction.PaymentStatusRequest{}
+    if err := jsoniter.Unmarshal(msg.Value, &request); err != nil {
+        return consumer.Permanent(errors.Wrap(err, "invalid payment status message"))
+    }
+
+    httpStatus, err := h.StoreService.UpdateTransactionStatus(ctx, request)
+    if err != nil && httpStatus < http.StatusInternalServerError {
+        return consumer.Permanent(err)
+    }
+    return err
+}
+
+// OrderNotification push order event to the member devices. Event unknown by this version is skipped.
+func (h ConsumerHandler) OrderNotification(ctx context.Context, msg kafka.Message) error {
+    env, err := h.Events.Decode(event.ContentType(msg.Headers), msg.Value)
+    if errors.Is(err, event.ErrUnknownEvent) {
+        logrus.Debugln("OrderNotification: skip", err)
+        return nil
+    }
+    if err != nil {
+        return consumer.Permanent(errors.Wrap(err, "invalid order event"))
+    }
+
+    order, ok := env.Payload.(*modelOutbox.OrderPayload)
+    if !ok {
+        return nil
+    }
+
+    _, httpStatus, err := h.StoreService.SendOrderNotification(ctx, env.Type, *order)
+    if err != nil && httpStatus < http.StatusInternalServerError {
+        return consumer.Permanent(err)
+    }
+    return err
+}
+
+// BackInStock notify wishlist member, other stock event is skipped
+func (h ConsumerHandler) BackInStock(ctx context.Context, msg kafka.Message) error {
+    env, err := h.Events.Decode(event.ContentType(msg.Headers), msg.Value)
+    if errors.Is(err, event.ErrUnknownEvent) {
+        logrus.Debugln("BackInStock: skip", err)
+        return nil
+    }
+    if err != nil {
+        return consumer.Permanent(errors.Wrap(err, "invalid stock event"))
+    }
+
+    stock, ok := env.Payload.(*modelOutbox.StockPayload)
+    if !ok || env.Type != modelOutbox.EventBackInStock {
+        return nil
+    }
+
+    _, httpStatus, err := h.StoreService.NotifyBackInStock(ctx, *stock)
+    if err != nil && httpStatus < http.StatusInternalServerError {
+        return consumer.Permanent(err)
+    }
+    return err
+}
@@ -6,6 +6,7 @@ import (
     "store-api/internal/base/app"
     "store-api/internal/base/handler"
     "store-api/internal/store/service"
+    "store-api/pkg/errs"
     "store-api/pkg/server"
 )
 
@@ -36,6 +37,22 @@ func (h HTTPHandler) AsMobileJson(ctx *app.Context, status int, message string,
     return h.App.AsMobileStatusOK(ctx, status, message, data)
 }
 
+// AsRestJson for /api/v2/ route, set httpStatus as the status: 200, 201, 404...
+func (h HTTPHandler) AsRestJson(ctx *app.Context, status int, message string, data interface{}) *server.Response {
+    if data == nil {
+        data = []int{}
+    }
+    return h.App.AsMobileJsonSetStatusCode(ctx, status, message, data)
+}
+
+// AsRestError for /api/v2/ route. Some service error still use httpStatus 200, response it as 400
+func (h HTTPHandler) AsRestError(ctx *app.Context, status int, err error) *server.Response {
+    if status < http.StatusBadRequest {
+        status = http.StatusBadRequest
+    }
+    return h.AsRestJson(ctx, status, err.Error(), nil)
+}
+
 // Backward compatibility with Yii2, not handle exception
 func (h HTTPHandler) ThrowBadRequestException(ctx *app.Context, message string) *server.Response {
     return h.App.ThrowExceptionJson(ctx, http.StatusBadRequest, 0, "Bad Request", message)
@@ -51,6 +68,16 @@ func (h HTTPHandler) MobileForbiddenRequest(ctx *app.Context, err error) *server
     return h.App.AsJson(ctx, http.StatusForbidden, err.Error(), nil)
 }
 
+// Unauthorized for route which require member session. Set httpStatus 401, client must login again
+func (h HTTPHandler) Unauthorized(ctx *app.Context) *server.Response {
+    return h.App.AsMobileJsonSetStatusCode(ctx, http.StatusUnauthorized, errs.ERROR_401, nil)
+}
+
+// AsContextError response the request errors of ctx.Bind(), ctx.GetVar(), ... with the structured errors body
+func (h HTTPHandler) AsContextError(ctx *app.Context) *server.Response {
+    return h.App.AsContextError(ctx)
+}
+
 // AsInternalError will set httpStatus to 500. Different with AsMobileJson always 200 code
 func (h HTTPHandler) AsInternalError(ctx *app.Context, err error, message string) *server.Response {
     return h.App.AsJson(ctx, http.StatusInternalServerError, message, nil)
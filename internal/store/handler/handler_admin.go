@@ -0,0 +1,61 @@
+package handler
+
+import (
+    "net/http"
+
+    "store-api/internal/base/app"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/constant"
+    "store-api/pkg/server"
+)
+
+// UploadProductImage multipart form: product_id, image
+func (h HTTPHandler) UploadProductImage(ctx *app.Context) *server.Response {
+    productId := ctx.GetValueInt("product_id")
+    if ctx.HasError() {
+        return h.AsContextError(ctx)
+    }
+
+    file, err := ctx.GetUploadFile("image")
+    if err != nil {
+        return h.AsWebResponse(ctx, http.StatusBadRequest, err.Error(), constant.EmptyArray)
+    }
+    defer file.Clean()
+
+    imageReq := presenterProduct.ImageUploadRequest{ProductID: productId}
+
+    result, httpStatus, err := h.StoreService.UploadProductImage(ctx.Context(), imageReq, file)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, httpStatus, "Upload Product Image Success", result)
+}
+
+func (h HTTPHandler) ReorderProductImage(ctx *app.Context) *server.Response {
+    imageReq := presenterProduct.ImageReorderRequest{}
+    if !ctx.Bind(&imageReq) {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.ReorderProductImage(ctx.Context(), imageReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, http.StatusOK, "Reorder Product Image Success", nil)
+}
+
+func (h HTTPHandler) DeleteProductImage(ctx *app.Context) *server.Response {
+    imageReq := presenterProduct.ImageDeleteRequest{}
+    if !ctx.Bind(&imageReq) {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.DeleteProductImage(ctx.Context(), imageReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, http.StatusOK, "Delete Product Image Success", nil)
+}
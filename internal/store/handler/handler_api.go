@@ -1,40 +1,21 @@
 package handler
 
 import (
-    "net/http"
-
     "store-api/internal/base/app"
     presenterCart "store-api/internal/store/presenter/cart"
     presenterMember "store-api/internal/store/presenter/member"
     presenterProduct "store-api/internal/store/presenter/product"
     presenterTransaction "store-api/internal/store/presenter/transaction"
-    "store-api/pkg/data/constant"
     "store-api/pkg/server"
-
-    jsoniter "github.com/json-iterator/go"
 )
 
 func (h HTTPHandler) ListProduct(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     productReq := presenterProduct.ProductRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &productReq)
+    if !ctx.Bind(&productReq) {
+        return h.AsContextError(ctx)
+    }
 
-    result, httpStatus, err := h.StoreService.ListProduct(productReq)
+    result, httpStatus, err := h.StoreService.ListProduct(ctx.Context(), productReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -43,26 +24,12 @@ func (h HTTPHandler) ListProduct(ctx *app.Context) *server.Response {
 }
 
 func (h HTTPHandler) AddToCart(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     cartReq := presenterCart.CartRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &cartReq)
+    if !ctx.Bind(&cartReq) {
+        return h.AsContextError(ctx)
+    }
 
-    httpStatus, err := h.StoreService.AddToCart(cartReq)
+    httpStatus, err := h.StoreService.AddToCart(ctx.Context(), cartReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -71,26 +38,12 @@ func (h HTTPHandler) AddToCart(ctx *app.Context) *server.Response {
 }
 
 func (h HTTPHandler) ViewCart(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     cartReq := presenterCart.CartViewRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &cartReq)
+    if !ctx.Bind(&cartReq) {
+        return h.AsContextError(ctx)
+    }
 
-    result, httpStatus, err := h.StoreService.ViewCart(cartReq)
+    result, httpStatus, err := h.StoreService.ViewCart(ctx.Context(), cartReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -99,26 +52,12 @@ func (h HTTPHandler) ViewCart(ctx *app.Context) *server.Response {
 }
 
 func (h HTTPHandler) DeleteProductInCart(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     cartReq := presenterCart.CartProductDeleteRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &cartReq)
+    if !ctx.Bind(&cartReq) {
+        return h.AsContextError(ctx)
+    }
 
-    httpStatus, err := h.StoreService.DeleteProductInCart(cartReq)
+    httpStatus, err := h.StoreService.DeleteProductInCart(ctx.Context(), cartReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -127,26 +66,12 @@ func (h HTTPHandler) DeleteProductInCart(ctx *app.Context) *server.Response {
 }
 
 func (h HTTPHandler) CreateTransaction(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     transactionReq := presenterTransaction.TransactionRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &transactionReq)
+    if !ctx.Bind(&transactionReq) {
+        return h.AsContextError(ctx)
+    }
 
-    httpStatus, err := h.StoreService.CreateTransaction(transactionReq)
+    httpStatus, err := h.StoreService.CreateTransaction(ctx.Context(), transactionReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -155,26 +80,12 @@ func (h HTTPHandler) CreateTransaction(ctx *app.Context) *server.Response {
 }
 
 func (h HTTPHandler) Login(ctx *app.Context) *server.Response {
-    ctx.ParseJson()
-    isJson := ctx.IsContentTypeJson()
-    if !isJson {
-        return h.AsWebResponse(ctx, http.StatusBadRequest, "invalid content type", constant.EmptyArray)
-    }
-
-    jsonBody := ctx.GetJsonBody()
-    if jsonBody == nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, "Json Body is required", constant.EmptyArray)
-    }
-
-    convertToJsonString, err := jsoniter.Marshal(jsonBody)
-    if err != nil {
-        return h.AsWebResponse(ctx, http.StatusInternalServerError, err.Error(), nil)
-    }
-
     memberReq := presenterMember.LoginRequest{}
-    jsoniter.Unmarshal(convertToJsonString, &memberReq)
+    if !ctx.Bind(&memberReq) {
+        return h.AsContextError(ctx)
+    }
 
-    result, httpStatus, err := h.StoreService.Login(memberReq)
+    result, httpStatus, err := h.StoreService.Login(ctx.Context(), memberReq)
     if err != nil {
         return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
     }
@@ -0,0 +1,48 @@
+package handler
+
+import (
+    "net/http"
+
+    "store-api/internal/base/app"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/constant"
+    "store-api/pkg/server"
+)
+
+// ImportProduct multipart form: file (csv or xlsx), dry_run (optional)
+func (h HTTPHandler) ImportProduct(ctx *app.Context) *server.Response {
+    dryRun := ctx.HasParam("dry_run") && ctx.GetValueBool("dry_run")
+    if ctx.HasError() {
+        return h.AsContextError(ctx)
+    }
+
+    file, err := ctx.GetUploadFile("file")
+    if err != nil {
+        return h.AsWebResponse(ctx, http.StatusBadRequest, err.Error(), constant.EmptyArray)
+    }
+    defer file.Clean()
+
+    importReq := presenterProduct.ImportRequest{Format: file.GetExtension(), DryRun: dryRun, File: file.GetFileBody()}
+
+    result, httpStatus, err := h.StoreService.ImportProduct(ctx.Context(), importReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), result)
+    }
+
+    return h.AsWebResponse(ctx, httpStatus, "Import Product Finished", result)
+}
+
+// ExportProduct GET ?format=csv|xlsx, download every variant of the catalogue
+func (h HTTPHandler) ExportProduct(ctx *app.Context) *server.Response {
+    format := ctx.GetQuery("format")
+    if ctx.HasError() {
+        return h.AsContextError(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.ExportProduct(ctx.Context(), presenterProduct.ExportRequest{Format: format})
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.App.AsStream(ctx, result.FileName, result.Body)
+}
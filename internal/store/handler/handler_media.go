@@ -0,0 +1,45 @@
+package handler
+
+import (
+    "store-api/internal/base/app"
+    presenterMedia "store-api/internal/store/presenter/media"
+    "store-api/pkg/server"
+)
+
+func (h HTTPHandler) CreateUploadURL(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    mediaReq := presenterMedia.UploadURLRequest{}
+    if !ctx.Bind(&mediaReq) {
+        return h.AsContextError(ctx)
+    }
+    mediaReq.MemberID = ctx.GetMemberID()
+
+    result, httpStatus, err := h.StoreService.CreateUploadURL(mediaReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Create Upload URL Success", result)
+}
+
+func (h HTTPHandler) CreateDownloadURL(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    mediaReq := presenterMedia.DownloadURLRequest{}
+    if !ctx.Bind(&mediaReq) {
+        return h.AsContextError(ctx)
+    }
+    mediaReq.MemberID = ctx.GetMemberID()
+
+    result, httpStatus, err := h.StoreService.CreateDownloadURL(mediaReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Create Download URL Success", result)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+    "store-api/internal/base/app"
+    presenterMember "store-api/internal/store/presenter/member"
+    "store-api/pkg/server"
+)
+
+func (h HTTPHandler) RegisterDevice(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    deviceReq := presenterMember.DeviceRequest{}
+    if !ctx.Bind(&deviceReq) {
+        return h.AsContextError(ctx)
+    }
+    deviceReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.RegisterDevice(ctx.Context(), deviceReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Register Device Success", nil)
+}
+
+func (h HTTPHandler) UnregisterDevice(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    deviceReq := presenterMember.DeviceRequest{}
+    if !ctx.Bind(&deviceReq) {
+        return h.AsContextError(ctx)
+    }
+    deviceReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.UnregisterDevice(ctx.Context(), deviceReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Unregister Device Success", nil)
+}
+
+func (h HTTPHandler) SetNotificationOptOut(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    optOutReq := presenterMember.NotificationOptOutRequest{}
+    if !ctx.Bind(&optOutReq) {
+        return h.AsContextError(ctx)
+    }
+    optOutReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.SetNotificationOptOut(ctx.Context(), optOutReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Update Notification Setting Success", nil)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+    "net/http"
+
+    "store-api/internal/base/app"
+    presenterCart "store-api/internal/store/presenter/cart"
+    presenterProduct "store-api/internal/store/presenter/product"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+    "store-api/pkg/server"
+)
+
+// Resource style route for /api/v2/, member is from session. Response use httpStatus as the status
+
+// GetProducts GET /products?category={category}, category is optional
+func (h HTTPHandler) GetProducts(ctx *app.Context) *server.Response {
+    productReq := presenterProduct.ProductRequest{}
+    if ctx.HasQuery("category") {
+        productReq.Category = ctx.GetQuery("category")
+    }
+
+    result, httpStatus, err := h.StoreService.ListProduct(ctx.Context(), productReq)
+    if httpStatus == http.StatusNotFound {
+        return h.AsRestJson(ctx, http.StatusOK, "List Product Success", []presenterProduct.ProductResponse{})
+    }
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusOK, "List Product Success", result)
+}
+
+// GetProduct GET /products/{id}
+func (h HTTPHandler) GetProduct(ctx *app.Context) *server.Response {
+    productId := ctx.GetVarInt("id")
+    if ctx.HasError() {
+        return h.AsContextError(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.GetProduct(ctx.Context(), productId)
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusOK, "Get Product Success", result)
+}
+
+// GetCart GET /cart
+func (h HTTPHandler) GetCart(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.ViewCart(ctx.Context(), presenterCart.CartViewRequest{MemberID: ctx.GetMemberID()})
+    if httpStatus == http.StatusNotFound {
+        return h.AsRestJson(ctx, http.StatusOK, "View Cart Success", []presenterCart.CartResponse{})
+    }
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusOK, "View Cart Success", result)
+}
+
+// AddCartItem POST /cart/items
+func (h HTTPHandler) AddCartItem(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    itemReq := presenterCart.CartItemRequest{}
+    if !ctx.Bind(&itemReq) {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.AddToCart(ctx.Context(), presenterCart.CartRequest{
+        MemberID:  ctx.GetMemberID(),
+        ProductID: itemReq.ProductID,
+        VariantID: itemReq.VariantID,
+        Quantity:  itemReq.Quantity,
+    })
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusCreated, "Add To Cart Success", nil)
+}
+
+// DeleteCartItem DELETE /cart/items/{productId}?variant_id={variantId}, all variants when variant_id is empty
+func (h HTTPHandler) DeleteCartItem(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    cartReq := presenterCart.CartProductDeleteRequest{
+        MemberID:  ctx.GetMemberID(),
+        ProductID: ctx.GetVarInt("productId"),
+    }
+    if ctx.HasQuery("variant_id") {
+        cartReq.VariantID = ctx.GetQueryInt("variant_id")
+    }
+    if ctx.HasError() {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.DeleteProductInCart(ctx.Context(), cartReq)
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusOK, "Delete Cart Success", nil)
+}
+
+// CreateOrder POST /orders
+func (h HTTPHandler) CreateOrder(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    orderReq := presenterTransaction.OrderRequest{}
+    if !ctx.Bind(&orderReq) {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.CreateTransaction(ctx.Context(), presenterTransaction.TransactionRequest{
+        MemberID:     ctx.GetMemberID(),
+        ProductID:    orderReq.ProductID,
+        VariantID:    orderReq.VariantID,
+        TrxCode:      orderReq.TrxCode,
+        ChannelID:    orderReq.ChannelID,
+        ChannelRefNo: orderReq.ChannelRefNo,
+        ChannelTime:  orderReq.ChannelTime,
+        ChannelDate:  orderReq.ChannelDate,
+        Quantity:     orderReq.Quantity,
+    })
+    if err != nil {
+        return h.AsRestError(ctx, httpStatus, err)
+    }
+
+    return h.AsRestJson(ctx, http.StatusCreated, "Order Success", nil)
+}
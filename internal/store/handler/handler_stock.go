@@ -0,0 +1,50 @@
+package handler
+
+import (
+    "store-api/internal/base/app"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/constant"
+    "store-api/pkg/server"
+)
+
+func (h HTTPHandler) StockHistory(ctx *app.Context) *server.Response {
+    historyReq := presenterProduct.StockHistoryRequest{}
+    if !ctx.Bind(&historyReq) {
+        return h.AsContextError(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.StockHistory(ctx.Context(), historyReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, httpStatus, "Stock History Success", result)
+}
+
+func (h HTTPHandler) AdjustStock(ctx *app.Context) *server.Response {
+    adjustReq := presenterProduct.StockAdjustRequest{}
+    if !ctx.Bind(&adjustReq) {
+        return h.AsContextError(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.AdjustStock(ctx.Context(), adjustReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, httpStatus, "Adjust Stock Success", result)
+}
+
+func (h HTTPHandler) SetReorderThreshold(ctx *app.Context) *server.Response {
+    thresholdReq := presenterProduct.ReorderThresholdRequest{}
+    if !ctx.Bind(&thresholdReq) {
+        return h.AsContextError(ctx)
+    }
+
+    httpStatus, err := h.StoreService.SetReorderThreshold(ctx.Context(), thresholdReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsWebResponse(ctx, httpStatus, "Set Reorder Threshold Success", constant.EmptyArray)
+}
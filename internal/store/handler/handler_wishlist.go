@@ -0,0 +1,97 @@
+package handler
+
+import (
+    "store-api/internal/base/app"
+    presenterCart "store-api/internal/store/presenter/cart"
+    "store-api/pkg/server"
+)
+
+func (h HTTPHandler) AddWishlist(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    wishlistReq := presenterCart.WishlistRequest{}
+    if !ctx.Bind(&wishlistReq) {
+        return h.AsContextError(ctx)
+    }
+    wishlistReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.AddWishlist(ctx.Context(), wishlistReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Add To Wishlist Success", nil)
+}
+
+func (h HTTPHandler) DeleteWishlist(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    wishlistReq := presenterCart.WishlistRequest{}
+    if !ctx.Bind(&wishlistReq) {
+        return h.AsContextError(ctx)
+    }
+    wishlistReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.DeleteWishlist(ctx.Context(), wishlistReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Delete Wishlist Success", nil)
+}
+
+// ListWishlist wishlist of the session member, body is not required
+func (h HTTPHandler) ListWishlist(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    result, httpStatus, err := h.StoreService.ListWishlist(ctx.Context(), presenterCart.WishlistViewRequest{MemberID: ctx.GetMemberID()})
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "View Wishlist Success", result)
+}
+
+func (h HTTPHandler) MoveWishlistToCart(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    moveReq := presenterCart.WishlistMoveRequest{}
+    if !ctx.Bind(&moveReq) {
+        return h.AsContextError(ctx)
+    }
+    moveReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.MoveWishlistToCart(ctx.Context(), moveReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Move To Cart Success", nil)
+}
+
+func (h HTTPHandler) MoveCartToWishlist(ctx *app.Context) *server.Response {
+    if ctx.IsGuest() {
+        return h.Unauthorized(ctx)
+    }
+
+    moveReq := presenterCart.WishlistMoveRequest{}
+    if !ctx.Bind(&moveReq) {
+        return h.AsContextError(ctx)
+    }
+    moveReq.MemberID = ctx.GetMemberID()
+
+    httpStatus, err := h.StoreService.MoveCartToWishlist(ctx.Context(), moveReq)
+    if err != nil {
+        return h.AsWebResponse(ctx, httpStatus, err.Error(), nil)
+    }
+
+    return h.AsMobileJson(ctx, httpStatus, "Move To Wishlist Success", nil)
+}
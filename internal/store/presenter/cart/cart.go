@@ -2,24 +2,34 @@ package cart
 
 type (
     CartProductDeleteRequest struct {
-        MemberID  int `json:"member_id"`
-        ProductID int `json:"product_id"`
+        MemberID  int `json:"member_id" validate:"required"`
+        ProductID int `json:"product_id" validate:"required"`
+        VariantID int `json:"variant_id"` // Optional, delete all variant of the product if empty
     }
 
     CartViewRequest struct {
-        MemberID int `json:"member_id"`
+        MemberID int `json:"member_id" validate:"required"`
     }
 
     CartRequest struct {
-        MemberID  int `json:"member_id" gorm:"column:member_id"`
-        ProductID int `json:"product_id" gorm:"column:product_id"`
-        Quantity  int `json:"quantity" gorm:"column:quantity"`
+        MemberID  int `json:"member_id" gorm:"column:member_id" validate:"required"`
+        ProductID int `json:"product_id" gorm:"column:product_id" validate:"required"`
+        VariantID int `json:"variant_id" gorm:"column:variant_id"` // Optional, default variant of the product if empty
+        Quantity  int `json:"quantity" gorm:"column:quantity" validate:"gte=0"`
+    }
+
+    // CartItemRequest POST /api/v2/cart/items
+    CartItemRequest struct {
+        ProductID int `json:"product_id" validate:"required"`
+        VariantID int `json:"variant_id"` // Optional, default variant of the product if empty
+        Quantity  int `json:"quantity" validate:"gt=0"`
     }
 
     CartResponse struct {
         ID        int  `json:"id" gorm:"column:id"`
         MemberID  int  `json:"member_id" gorm:"column:member_id"`
         ProductID int  `json:"product_id" gorm:"column:product_id"`
+        VariantID int  `json:"variant_id" gorm:"column:variant_id"`
         Quantity  int  `json:"quantity" gorm:"column:quantity"`
         IsActive  bool `json:"is_active" gorm:"column:is_active"`
     }
@@ -0,0 +1,32 @@
+package cart
+
+import "time"
+
+type (
+    WishlistRequest struct {
+        MemberID  int `json:"-"` // From session
+        ProductID int `json:"product_id" validate:"required"`
+        VariantID int `json:"variant_id"` // Optional, default variant of the product if empty
+    }
+
+    WishlistViewRequest struct {
+        MemberID int `json:"-"` // From session
+    }
+
+    WishlistMoveRequest struct {
+        MemberID  int `json:"-"` // From session
+        ProductID int `json:"product_id" validate:"required"`
+        VariantID int `json:"variant_id"`                // Optional. To cart: default variant, to wishlist: all variants in the cart
+        Quantity  int `json:"quantity" validate:"gte=0"` // To cart only
+    }
+
+    WishlistResponse struct {
+        ID          int       `json:"id"`
+        ProductID   int       `json:"product_id"`
+        VariantID   int       `json:"variant_id"`
+        Name        string    `json:"name"`
+        Stock       int       `json:"stock"` // Stock of the variant
+        InStock     bool      `json:"in_stock"`
+        CreatedDate time.Time `json:"created_date"`
+    }
+)
@@ -0,0 +1,29 @@
+package media
+
+import "time"
+
+type (
+    UploadURLRequest struct {
+        MemberID int    `json:"-"` // From session
+        FileName string `json:"file_name" validate:"required"`
+        Size     int64  `json:"size" validate:"gt=0"` // In bytes, must be the same with uploaded file
+    }
+
+    UploadURLResponse struct {
+        Key       string            `json:"key"`
+        URL       string            `json:"url"`
+        Method    string            `json:"method"`
+        Headers   map[string]string `json:"headers"` // Must be sent with the upload request
+        ExpiredAt time.Time         `json:"expired_at"`
+    }
+
+    DownloadURLRequest struct {
+        MemberID int    `json:"-"` // From session
+        Key      string `json:"key" validate:"required"`
+    }
+
+    DownloadURLResponse struct {
+        URL       string    `json:"url"`
+        ExpiredAt time.Time `json:"expired_at"`
+    }
+)
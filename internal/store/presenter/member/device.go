@@ -0,0 +1,16 @@
+package member
+
+type (
+    DeviceRequest struct {
+        MemberID int    `json:"-"` // From session
+        Token    string `json:"token" validate:"required,max=255"`
+        Platform string `json:"platform"` // android, ios or web
+    }
+
+    NotificationOptOutRequest struct {
+        MemberID int    `json:"-"`       // From session
+        Channel  string `json:"channel"` // push when empty
+        Category string `json:"category" validate:"required"`
+        OptOut   bool   `json:"opt_out"` // false to subscribe again
+    }
+)
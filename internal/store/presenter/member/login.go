@@ -2,8 +2,8 @@ package member
 
 type (
     LoginRequest struct {
-        Username string `json:"username"`
-        Password string `json:"password"`
+        Username string `json:"username" validate:"required"`
+        Password string `json:"password" validate:"required"`
     }
 
     LoginResponse struct {
@@ -0,0 +1,37 @@
+package product
+
+import (
+    "bytes"
+    "io"
+)
+
+type (
+    ImportRequest struct {
+        Format string    `json:"format"`  // csv or xlsx
+        DryRun bool      `json:"dry_run"` // Only validate, nothing is saved
+        File   io.Reader `json:"-"`
+    }
+
+    ImportResponse struct {
+        TotalRow int              `json:"total_row"`
+        Imported int              `json:"imported"`
+        Failed   int              `json:"failed"`
+        DryRun   bool             `json:"dry_run"`
+        Errors   []ImportRowError `json:"errors"`
+    }
+
+    ImportRowError struct {
+        Row     int    `json:"row"` // Line number in the file, header is row 1
+        Column  string `json:"column"`
+        Message string `json:"message"`
+    }
+
+    ExportRequest struct {
+        Format string `json:"format"` // csv or xlsx
+    }
+
+    ExportResponse struct {
+        FileName string
+        Body     bytes.Buffer
+    }
+)
@@ -6,10 +6,43 @@ type (
     }
 
     ProductResponse struct {
-        ID       int     `json:"id" gorm:"column:id"`
-        Name     string  `json:"name" gorm:"column:name"`
-        Category string  `json:"category" gorm:"column:category"`
-        Price    float64 `json:"price" gorm:"column:price"`
-        Stock    int     `json:"stock" gorm:"column:stock"`
+        ID       int               `json:"id" gorm:"column:id"`
+        Name     string            `json:"name" gorm:"column:name"`
+        Category string            `json:"category" gorm:"column:category"`
+        Price    float64           `json:"price" gorm:"column:price"`
+        Stock    int               `json:"stock" gorm:"column:stock"`
+        Variants []VariantResponse `json:"variants"`
+        Images   []ImageResponse   `json:"images"`
+    }
+
+    VariantResponse struct {
+        ID     int     `json:"id" gorm:"column:id"`
+        SKU    string  `json:"sku" gorm:"column:sku"`
+        Size   string  `json:"size" gorm:"column:size"`
+        Colour string  `json:"colour" gorm:"column:colour"`
+        Price  float64 `json:"price" gorm:"column:price"`
+        Stock  int     `json:"stock" gorm:"column:stock"`
+    }
+
+    ImageUploadRequest struct {
+        ProductID int `json:"product_id"`
+    }
+
+    ImageReorderRequest struct {
+        ProductID int   `json:"product_id" validate:"required"`
+        ImageIDs  []int `json:"image_ids" validate:"required,min=1"` // All image id of the product, in the new order
+    }
+
+    ImageDeleteRequest struct {
+        ProductID int `json:"product_id" validate:"required"`
+        ImageID   int `json:"image_id" validate:"required"`
+    }
+
+    ImageResponse struct {
+        ID        int    `json:"id"`
+        URL       string `json:"url"` // Large rendition
+        Thumbnail string `json:"thumbnail"`
+        Medium    string `json:"medium"`
+        Position  int    `json:"position"`
     }
 )
@@ -0,0 +1,48 @@
+package product
+
+import "time"
+
+type (
+    StockHistoryRequest struct {
+        ProductID int `json:"product_id" validate:"required"`
+        VariantID int `json:"variant_id"` // Optional, all variants when empty
+        Page      int `json:"page" validate:"gte=0"`
+        PerPage   int `json:"per_page" validate:"gte=0"`
+    }
+
+    StockHistoryResponse struct {
+        ProductID   int                     `json:"product_id"`
+        Stock       int                     `json:"stock"`
+        LedgerStock int                     `json:"ledger_stock"` // Sum of the movements, must be the same as stock
+        Page        int                     `json:"page"`
+        PerPage     int                     `json:"per_page"`
+        Total       int                     `json:"total"`
+        Movements   []StockMovementResponse `json:"movements"`
+    }
+
+    StockMovementResponse struct {
+        ID          int       `json:"id"`
+        VariantID   int       `json:"variant_id"`
+        Type        string    `json:"type"`
+        Quantity    int       `json:"quantity"`
+        StockAfter  int       `json:"stock_after"`
+        Reference   string    `json:"reference"`
+        Note        string    `json:"note"`
+        CreatedDate time.Time `json:"created_date"`
+    }
+
+    StockAdjustRequest struct {
+        VariantID int    `json:"variant_id" validate:"required"`
+        Type      string `json:"type" validate:"oneof=restock adjustment refund"`
+        Quantity  int    `json:"quantity" validate:"required"` // Signed, negative to reduce stock
+        Reference string `json:"reference"`
+        Note      string `json:"note"`
+    }
+)
+
+type (
+    ReorderThresholdRequest struct {
+        ProductID        int `json:"product_id" validate:"required"`
+        ReorderThreshold int `json:"reorder_threshold" validate:"gte=0"` // 0 disable low stock alert
+    }
+)
@@ -4,8 +4,9 @@ import "time"
 
 type (
     TransactionRequest struct {
-        MemberID     int       `json:"member_id" gorm:"column:member_id"`
-        ProductID    int       `json:"product_id" gorm:"column:product_id"`
+        MemberID     int       `json:"member_id" gorm:"column:member_id" validate:"required"`
+        ProductID    int       `json:"product_id" gorm:"column:product_id" validate:"required"`
+        VariantID    int       `json:"variant_id" gorm:"column:variant_id"` // Optional, default variant of the product if empty
         TrxCode      string    `json:"trx_code" gorm:"column:trx_code"`
         ChannelID    string    `json:"channel_id" gorm:"column:channel_id"`
         ChannelRefNo string    `json:"channel_ref_no" gorm:"column:channel_ref_no"`
@@ -16,6 +17,18 @@ type (
         UpdatedDate  time.Time `json:"updated_date" gorm:"column:updated_date"`
     }
 
+    // OrderRequest POST /api/v2/orders, the member is from session
+    OrderRequest struct {
+        ProductID    int    `json:"product_id" validate:"required"`
+        VariantID    int    `json:"variant_id"` // Optional, default variant of the product if empty
+        TrxCode      string `json:"trx_code" validate:"required"`
+        ChannelID    string `json:"channel_id"`
+        ChannelRefNo string `json:"channel_ref_no"`
+        ChannelTime  string `json:"channel_time"`
+        ChannelDate  string `json:"channel_date"`
+        Quantity     int    `json:"quantity" validate:"gt=0"`
+    }
+
     TransactionResponse struct {
         ID       int     `json:"id" gorm:"column:id"`
         Name     string  `json:"name" gorm:"column:name"`
@@ -24,3 +37,12 @@ type (
         Stock    int     `json:"stock" gorm:"column:stock"`
     }
 )
+
+type (
+    // PaymentStatusRequest payment result sent by the payment channel
+    PaymentStatusRequest struct {
+        TrxCode      string `json:"trx_code"`
+        Status       string `json:"status"` // success or failed
+        ChannelRefNo string `json:"channel_ref_no"`
+    }
+)
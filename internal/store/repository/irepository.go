@@ -1,19 +1,65 @@
 package repository
 
 import (
+    "context"
+    "time"
+
+    "store-api/internal/base/service/notification"
     modelCart "store-api/internal/store/domain/cart"
     modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
     modelProduct "store-api/internal/store/domain/product"
     modelTransaction "store-api/internal/store/domain/transaction"
 )
 
 type StoreRepository interface {
-    ListProduct(category string) (result []modelProduct.Product, err error)
-    GetProduct(productId int) (result modelProduct.Product, err error)
-    CreateCart(model modelCart.Cart) (err error)
-    GetCart(memberId int) (result []modelCart.Cart, err error)
-    DeleteProductInCart(memberId, productId int) (err error)
-    CreateTransaction(model modelTransaction.Transactions, deductedStockProduct int) (err error)
-    GetMemberByUsername(username string) (result modelMember.Member, err error)
-    InsertFailedTransaction(model modelTransaction.Transactions) (err error)
+    ListProduct(ctx context.Context, category string) (result []modelProduct.Product, err error)
+    GetProduct(ctx context.Context, productId int) (result modelProduct.Product, err error)
+    ListProductByID(ctx context.Context, productIds []int) (result []modelProduct.Product, err error)
+    ListVariant(ctx context.Context, productIds []int) (result []modelProduct.Variant, err error)
+    GetVariant(ctx context.Context, variantId int) (result modelProduct.Variant, err error)
+    GetDefaultVariant(ctx context.Context, productId int) (result modelProduct.Variant, err error)
+    ListImage(ctx context.Context, productIds []int) (result []modelProduct.Image, err error)
+    GetImage(ctx context.Context, imageId int) (result modelProduct.Image, err error)
+    CreateImage(ctx context.Context, model modelProduct.Image) (id int, err error)
+    UpdateImagePosition(ctx context.Context, productId int, imageIds []int) (err error)
+    DeleteImage(ctx context.Context, imageId int) (err error)
+    ListCatalogue(ctx context.Context) (result []modelProduct.CatalogueRow, err error)
+    ListCatalogueBySKU(ctx context.Context, skus []string) (result []modelProduct.CatalogueRow, err error)
+    UpsertCatalogue(ctx context.Context, rows []modelProduct.CatalogueRow) (err error)
+    ListStockMovement(ctx context.Context, productId, variantId, limit, offset int) (result []modelProduct.StockMovement, err error)
+    CountStockMovement(ctx context.Context, productId, variantId int) (total int, err error)
+    GetLedgerStock(ctx context.Context, productId int) (stock int, err error)
+    CreateStockMovement(ctx context.Context, movement modelProduct.StockMovement) (result modelProduct.StockMovement, err error)
+    CreateCartWithReservation(ctx context.Context, cart modelCart.Cart, reservation modelCart.Reservation) (result modelCart.Reservation, err error)
+    ReleaseReservation(ctx context.Context, memberId, productId, variantId int) (err error)
+    ReleaseExpiredReservation(ctx context.Context, now time.Time, limit int) (released int, err error)
+    SumActiveReservation(ctx context.Context, memberId, variantId int) (quantity int, err error)
+    MarkLowStockAlert(ctx context.Context, productId int) (result modelProduct.Product, alerted bool, err error)
+    ResetLowStockAlert(ctx context.Context, productId int) (err error)
+    UpdateReorderThreshold(ctx context.Context, productId, threshold int) (err error)
+    ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) (result []modelOutbox.Event, err error)
+    MarkOutboxSent(ctx context.Context, id int64, sentAt time.Time) (err error)
+    MarkOutboxFailed(ctx context.Context, model modelOutbox.Event) (err error)
+    UpdateTransactionStatus(ctx context.Context, trxCode, status, channelRefNo string) (result modelTransaction.Transactions, err error)
+    UpsertDevice(ctx context.Context, model modelMember.Device) (err error)
+    DeleteDevice(ctx context.Context, memberId int, token string) (err error)
+    DeleteDeviceToken(ctx context.Context, tokens []string) (err error)
+    ListDevice(ctx context.Context, memberId int) (result []modelMember.Device, err error)
+    SetNotificationOptOut(ctx context.Context, model modelMember.NotificationOptOut, optOut bool) (err error)
+    IsNotificationOptOut(ctx context.Context, memberId int, channel, category string) (optOut bool, err error)
+    CreateNotificationLog(ctx context.Context, model notification.Log) (err error)
+    AddWishlist(ctx context.Context, model modelCart.Wishlist) (err error)
+    DeleteWishlist(ctx context.Context, memberId, productId, variantId int) (err error)
+    ListWishlist(ctx context.Context, memberId int) (result []modelCart.Wishlist, err error)
+    ListWishlistMember(ctx context.Context, variantId int) (result []int, err error)
+    MoveWishlistToCart(ctx context.Context, cart modelCart.Cart, reservation *modelCart.Reservation) (err error)
+    MoveCartToWishlist(ctx context.Context, memberId, productId, variantId int) (err error)
+    CreateCart(ctx context.Context, model modelCart.Cart) (err error)
+    GetCart(ctx context.Context, memberId int) (result []modelCart.Cart, err error)
+    DeleteProductInCart(ctx context.Context, memberId, productId, variantId int) (err error)
+    CreateTransaction(ctx context.Context, model modelTransaction.Transactions) (err error)
+    GetMemberByUsername(ctx context.Context, username string) (result modelMember.Member, err error)
+    GetMember(ctx context.Context, memberId int) (result modelMember.Member, err error)
+    InsertFailedTransaction(ctx context.Context, model modelTransaction.Transactions) (err error)
 }
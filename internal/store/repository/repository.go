@@ -1,6 +1,7 @@
 package repository
 
 import (
+    "context"
     "fmt"
     "time"
 
@@ -21,36 +22,84 @@ type repo struct {
     db *sqlx.DB
 }
 
-func (r repo) ListProduct(category string) (result []modelProduct.Product, err error) {
+func (r repo) ListProduct(ctx context.Context, category string) (result []modelProduct.Product, err error) {
     query := fmt.Sprintf("SELECT id, name, category, price, stock FROM %s", modelProduct.TableName)
     if category != "" {
         query += fmt.Sprintf(" WHERE category = '%s'", category)
     }
 
-    err = r.db.Select(&result, query)
+    err = r.db.SelectContext(ctx, &result, query)
     return
 }
 
-func (r repo) GetProduct(productId int) (result modelProduct.Product, err error) {
+func (r repo) GetProduct(ctx context.Context, productId int) (result modelProduct.Product, err error) {
     query := fmt.Sprintf("SELECT id, name, category, price, stock FROM %s", modelProduct.TableName)
     query += fmt.Sprintf(" WHERE id = %d", productId)
 
-    err = r.db.Get(&result, query)
+    err = r.db.GetContext(ctx, &result, query)
     return
 }
 
-func (r repo) CreateCart(model modelCart.Cart) (err error) {
+func (r repo) ListProductByID(ctx context.Context, productIds []int) (result []modelProduct.Product, err error) {
+    if len(productIds) == 0 {
+        return
+    }
+
+    query, args, err := sqlx.In(fmt.Sprintf("SELECT id, name, category, price, stock FROM %s WHERE id IN (?)",
+        modelProduct.TableName), productIds)
+    if err != nil {
+        return
+    }
+
+    err = r.db.SelectContext(ctx, &result, r.db.Rebind(query), args...)
+    return
+}
+
+func (r repo) ListVariant(ctx context.Context, productIds []int) (result []modelProduct.Variant, err error) {
+    if len(productIds) == 0 {
+        return
+    }
+
+    query, args, err := sqlx.In(fmt.Sprintf(`SELECT id, product_id, sku, size, colour, price_override, stock, is_active 
+FROM %s WHERE is_active = true AND product_id IN (?) ORDER BY product_id, id`, modelProduct.TableNameVariant), productIds)
+    if err != nil {
+        return
+    }
+
+    err = r.db.SelectContext(ctx, &result, r.db.Rebind(query), args...)
+    return
+}
+
+func (r repo) GetVariant(ctx context.Context, variantId int) (result modelProduct.Variant, err error) {
+    query := fmt.Sprintf("SELECT id, product_id, sku, size, colour, price_override, stock, is_active FROM %s", modelProduct.TableNameVariant)
+    query += fmt.Sprintf(" WHERE id = %d AND is_active = true", variantId)
+
+    err = r.db.GetContext(ctx, &result, query)
+    return
+}
+
+// GetDefaultVariant first active variant of the product, used by client which doesn't send variant_id yet
+func (r repo) GetDefaultVariant(ctx context.Context, productId int) (result modelProduct.Variant, err error) {
+    query := fmt.Sprintf("SELECT id, product_id, sku, size, colour, price_override, stock, is_active FROM %s", modelProduct.TableNameVariant)
+    query += fmt.Sprintf(" WHERE product_id = %d AND is_active = true ORDER BY id LIMIT 1", productId)
+
+    err = r.db.GetContext(ctx, &result, query)
+    return
+}
+
+func (r repo) CreateCart(ctx context.Context, model modelCart.Cart) (err error) {
     arg := map[string]interface{}{
         "member_id":  model.MemberID,
         "product_id": model.ProductID,
+        "variant_id": model.VariantID,
         "quantity":   model.Quantity,
         "is_active":  true,
     }
 
     query := fmt.Sprintf(`INSERT INTO %s SET member_id = :member_id, product_id = :product_id, 
-quantity = :quantity, is_active = :is_active`, modelCart.TableName)
+variant_id = :variant_id, quantity = :quantity, is_active = :is_active`, modelCart.TableName)
 
-    _, err = r.db.NamedExec(query, arg)
+    _, err = r.db.NamedExecContext(ctx, query, arg)
     if err != nil {
         return err
     }
@@ -58,29 +107,33 @@ quantity = :quantity, is_active = :is_active`, modelCart.TableName)
     return
 }
 
-func (r repo) GetCart(memberId int) (result []modelCart.Cart, err error) {
-    query := fmt.Sprintf("SELECT id, member_id, product_id, quantity, is_active FROM %s", modelCart.TableName)
+func (r repo) GetCart(ctx context.Context, memberId int) (result []modelCart.Cart, err error) {
+    query := fmt.Sprintf("SELECT id, member_id, product_id, variant_id, quantity, is_active FROM %s", modelCart.TableName)
     query += fmt.Sprintf(" WHERE member_id = %d", memberId)
 
-    err = r.db.Select(&result, query)
+    err = r.db.SelectContext(ctx, &result, query)
     return
 }
 
-func (r repo) DeleteProductInCart(memberId, productId int) (err error) {
+func (r repo) DeleteProductInCart(ctx context.Context, memberId, productId, variantId int) (err error) {
     query := fmt.Sprintf("UPDATE %s SET is_active = false", modelCart.TableName)
     query += fmt.Sprintf(" WHERE member_id = %d AND product_id = %d", memberId, productId)
+    if variantId != 0 {
+        query += fmt.Sprintf(" AND variant_id = %d", variantId)
+    }
 
-    _, err = r.db.Exec(query)
+    _, err = r.db.ExecContext(ctx, query)
     if err != nil {
         return
     }
     return
 }
 
-func (r repo) CreateTransaction(model modelTransaction.Transactions, deductedStockProduct int) (err error) {
+func (r repo) CreateTransaction(ctx context.Context, model modelTransaction.Transactions) (err error) {
     arg := map[string]interface{}{
         "member_id":      model.MemberID,
         "product_id":     model.ProductID,
+        "variant_id":     model.VariantID,
         "trx_code":       model.TrxCode,
         "channel_id":     model.ChannelID,
         "channel_ref_no": model.ChannelRefNo,
@@ -94,58 +147,86 @@ func (r repo) CreateTransaction(model modelTransaction.Transactions, deductedSto
         "updated_date":   time.Time{},
     }
 
-    tx, err := r.db.Beginx()
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
     defer func() {
         if err == nil {
             err = tx.Commit()
         } else {
-            err = tx.Rollback()
+            tx.Rollback()
         }
     }()
 
     // Delete product in cart
     query := fmt.Sprintf("UPDATE %s SET is_active = false", modelCart.TableName)
-    query += fmt.Sprintf(" WHERE member_id = %d AND product_id = %d", model.MemberID, model.ProductID)
-    _, err = tx.Exec(query)
+    query += fmt.Sprintf(" WHERE member_id = %d AND product_id = %d AND variant_id = %d", model.MemberID, model.ProductID, model.VariantID)
+    _, err = tx.ExecContext(ctx, query)
+    if err != nil {
+        return
+    }
+
+    // Held stock of the member goes back first, then it is taken by the sale
+    err = convertReservation(ctx, tx, model.MemberID, model.VariantID)
     if err != nil {
         return
     }
 
-    // Deduct Stock in Product
-    query = fmt.Sprintf("UPDATE %s SET stock = %d where id = %d", modelProduct.TableName, deductedStockProduct, model.ProductID)
-    _, err = tx.Exec(query)
+    // Deduct Stock in Variant, checked again under lock
+    _, err = applyStockMovement(ctx, tx, modelProduct.StockMovement{
+        VariantID: model.VariantID,
+        Type:      modelProduct.MovementSale,
+        Quantity:  -model.Quantity,
+        Reference: model.TrxCode,
+    })
     if err != nil {
-        fmt.Println(err)
         return
     }
 
     // Create Transaction
-    query = fmt.Sprintf(`INSERT INTO %s SET member_id = :member_id, product_id = :product_id, 
+    query = fmt.Sprintf(`INSERT INTO %s SET member_id = :member_id, product_id = :product_id, variant_id = :variant_id,
     trx_code = :trx_code, channel_id = :channel_id, channel_ref_no = :channel_ref_no, channel_time = :channel_time, 
     channel_date = :channel_date, amount = :amount, amount_fee = :amount_fee, status = :status,
     quantity = :quantity, created_date = :created_date, updated_date = :updated_date`, modelTransaction.TableName)
-    _, err = tx.NamedExec(query, arg)
+    res, err := tx.NamedExecContext(ctx, query, arg)
     if err != nil {
         return err
     }
 
+    id, err := res.LastInsertId()
+    if err != nil {
+        return
+    }
+    model.ID = int(id)
+
+    // Published by outbox-relay once committed
+    err = insertOrderOutbox(ctx, tx, model)
     return
 }
 
-func (r repo) GetMemberByUsername(username string) (result modelMember.Member, err error) {
+func (r repo) GetMemberByUsername(ctx context.Context, username string) (result modelMember.Member, err error) {
     query := fmt.Sprintf("SELECT id, channel_id, username, credential, salt, created_date FROM %s WHERE username = '%s'", modelMember.TableName, username)
 
-    err = r.db.Get(&result, query)
+    err = r.db.GetContext(ctx, &result, query)
     if err != nil {
         return
     }
     return
 }
 
-func (r repo) InsertFailedTransaction(model modelTransaction.Transactions) (err error) {
+func (r repo) GetMember(ctx context.Context, memberId int) (result modelMember.Member, err error) {
+    query := fmt.Sprintf("SELECT id, channel_id, username, created_date FROM %s WHERE id = ?", modelMember.TableName)
+
+    err = r.db.GetContext(ctx, &result, query, memberId)
+    return
+}
+
+func (r repo) InsertFailedTransaction(ctx context.Context, model modelTransaction.Transactions) (err error) {
     arg := map[string]interface{}{
         "member_id":      model.MemberID,
         "product_id":     model.ProductID,
+        "variant_id":     model.VariantID,
         "trx_code":       model.TrxCode,
         "channel_id":     model.ChannelID,
         "channel_ref_no": model.ChannelRefNo,
@@ -159,15 +240,26 @@ func (r repo) InsertFailedTransaction(model modelTransaction.Transactions) (err
         "updated_date":   time.Time{},
     }
 
-    query := fmt.Sprintf(`INSERT INTO %s SET member_id = :member_id, product_id = :product_id, 
+    query := fmt.Sprintf(`INSERT INTO %s SET member_id = :member_id, product_id = :product_id, variant_id = :variant_id,
     trx_code = :trx_code, channel_id = :channel_id, channel_ref_no = :channel_ref_no, channel_time = :channel_time, 
     channel_date = :channel_date, amount = :amount, amount_fee = :amount_fee, status = :status,
     quantity = :quantity, created_date = :created_date, updated_date = :updated_date`, modelTransaction.TableName)
 
-    _, err = r.db.NamedExec(query, arg)
+    _, err = r.db.NamedExecContext(ctx, query, arg)
     if err != nil {
         return err
     }
 
     return
 }
+
+// syncProductStock set product stock to the sum of its variants. Low stock alert flag is reset once stock is back
+// to the reorder threshold, MySQL assign from left to right so the new stock is compared.
+func syncProductStock(ctx context.Context, tx *sqlx.Tx, productId int) (err error) {
+    query := fmt.Sprintf(`UPDATE %s SET stock = (SELECT COALESCE(SUM(stock), 0) FROM %s WHERE product_id = %d AND is_active = true), 
+low_stock_alerted = IF(stock >= reorder_threshold, false, low_stock_alerted) WHERE id = %d`,
+        modelProduct.TableName, modelProduct.TableNameVariant, productId, productId)
+
+    _, err = tx.ExecContext(ctx, query)
+    return
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+// MarkLowStockAlert flag product whose stock is below its reorder threshold. Only the first caller get alerted true,
+// product is not alerted again until it is restocked.
+func (r repo) MarkLowStockAlert(ctx context.Context, productId int) (result modelProduct.Product, alerted bool, err error) {
+    query := fmt.Sprintf(`UPDATE %s SET low_stock_alerted = true 
+WHERE id = ? AND reorder_threshold > 0 AND stock < reorder_threshold AND low_stock_alerted = false`, modelProduct.TableName)
+    res, err := r.db.ExecContext(ctx, query, productId)
+    if err != nil {
+        return
+    }
+
+    affected, err := res.RowsAffected()
+    if err != nil || affected == 0 {
+        return
+    }
+
+    query = fmt.Sprintf("SELECT id, name, category, price, stock, reorder_threshold, low_stock_alerted FROM %s WHERE id = ?",
+        modelProduct.TableName)
+    err = r.db.GetContext(ctx, &result, query, productId)
+    alerted = err == nil
+    return
+}
+
+// ResetLowStockAlert allow the product to alert again, ex: alert cannot be sent
+func (r repo) ResetLowStockAlert(ctx context.Context, productId int) (err error) {
+    query := fmt.Sprintf("UPDATE %s SET low_stock_alerted = false WHERE id = ?", modelProduct.TableName)
+    _, err = r.db.ExecContext(ctx, query, productId)
+    return
+}
+
+// UpdateReorderThreshold product which is not low with the new threshold can alert again
+func (r repo) UpdateReorderThreshold(ctx context.Context, productId, threshold int) (err error) {
+    query := fmt.Sprintf(`UPDATE %s SET reorder_threshold = ?, low_stock_alerted = IF(stock >= ?, false, low_stock_alerted) 
+WHERE id = ?`, modelProduct.TableName)
+    _, err = r.db.ExecContext(ctx, query, threshold, threshold, productId)
+    return
+}
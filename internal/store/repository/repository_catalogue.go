@@ -0,0 +1,165 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    "github.com/jmoiron/sqlx"
+
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+type catalogueRow struct {
+    ProductID     int             `db:"product_id"`
+    Name          string          `db:"name"`
+    Category      string          `db:"category"`
+    Price         float64         `db:"price"`
+    VariantID     int             `db:"variant_id"`
+    SKU           string          `db:"sku"`
+    Size          string          `db:"size"`
+    Colour        string          `db:"colour"`
+    PriceOverride sql.NullFloat64 `db:"price_override"`
+    Stock         int             `db:"stock"`
+    IsActive      bool            `db:"is_active"`
+}
+
+func (m catalogueRow) toModel() modelProduct.CatalogueRow {
+    return modelProduct.CatalogueRow{
+        Product: modelProduct.Product{ID: m.ProductID, Name: m.Name, Category: m.Category, Price: m.Price},
+        Variant: modelProduct.Variant{ID: m.VariantID, ProductID: m.ProductID, SKU: m.SKU, Size: m.Size,
+            Colour: m.Colour, PriceOverride: m.PriceOverride, Stock: m.Stock, IsActive: m.IsActive},
+    }
+}
+
+func catalogueQuery() string {
+    return fmt.Sprintf(`SELECT p.id AS product_id, p.name, p.category, p.price, v.id AS variant_id, v.sku, v.size, v.colour, 
+v.price_override, v.stock, v.is_active FROM %s p JOIN %s v ON v.product_id = p.id`, modelProduct.TableName, modelProduct.TableNameVariant)
+}
+
+// ListCatalogue every variant including inactive one, ordered by product
+func (r repo) ListCatalogue(ctx context.Context) (result []modelProduct.CatalogueRow, err error) {
+    var rows []catalogueRow
+    err = r.db.SelectContext(ctx, &rows, catalogueQuery()+" ORDER BY p.id, v.id")
+    if err != nil {
+        return
+    }
+
+    for _, row := range rows {
+        result = append(result, row.toModel())
+    }
+    return
+}
+
+func (r repo) ListCatalogueBySKU(ctx context.Context, skus []string) (result []modelProduct.CatalogueRow, err error) {
+    if len(skus) == 0 {
+        return
+    }
+
+    query, args, err := sqlx.In(catalogueQuery()+" WHERE v.sku IN (?)", skus)
+    if err != nil {
+        return
+    }
+
+    var rows []catalogueRow
+    err = r.db.SelectContext(ctx, &rows, r.db.Rebind(query), args...)
+    if err != nil {
+        return
+    }
+
+    for _, row := range rows {
+        result = append(result, row.toModel())
+    }
+    return
+}
+
+// UpsertCatalogue create or update products (by name and category) and variants (by sku) in one transaction
+func (r repo) UpsertCatalogue(ctx context.Context, rows []modelProduct.CatalogueRow) (err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    productIds := make(map[string]int)
+    for _, row := range rows {
+        key := row.Product.Name + "\x00" + row.Product.Category
+        productId, ok := productIds[key]
+        if !ok {
+            productId, err = upsertProduct(ctx, tx, row.Product)
+            if err != nil {
+                return
+            }
+            productIds[key] = productId
+        }
+
+        // New variant start from 0, stock is set through the ledger
+        query := fmt.Sprintf(`INSERT INTO %s (product_id, sku, size, colour, price_override, stock, is_active) 
+VALUES (?, ?, ?, ?, ?, 0, ?) ON DUPLICATE KEY UPDATE size = VALUES(size), colour = VALUES(colour), 
+price_override = VALUES(price_override), is_active = VALUES(is_active)`, modelProduct.TableNameVariant)
+        _, err = tx.ExecContext(ctx, query, productId, row.Variant.SKU, row.Variant.Size, row.Variant.Colour,
+            row.Variant.PriceOverride, row.Variant.IsActive)
+        if err != nil {
+            return
+        }
+
+        var variant modelProduct.Variant
+        query = fmt.Sprintf("SELECT id, stock FROM %s WHERE sku = ? FOR UPDATE", modelProduct.TableNameVariant)
+        err = tx.GetContext(ctx, &variant, query, row.Variant.SKU)
+        if err != nil {
+            return
+        }
+
+        if delta := row.Variant.Stock - variant.Stock; delta != 0 {
+            _, err = applyStockMovement(ctx, tx, modelProduct.StockMovement{
+                VariantID: variant.ID,
+                Type:      modelProduct.MovementAdjustment,
+                Quantity:  delta,
+                Note:      "Bulk import",
+            })
+            if err != nil {
+                return
+            }
+        }
+    }
+
+    // is_active of the variants might change
+    for _, productId := range productIds {
+        err = syncProductStock(ctx, tx, productId)
+        if err != nil {
+            return
+        }
+    }
+    return
+}
+
+// upsertProduct update price of product with same name and category, otherwise create it
+func upsertProduct(ctx context.Context, tx *sqlx.Tx, model modelProduct.Product) (productId int, err error) {
+    query := fmt.Sprintf("SELECT id FROM %s WHERE name = ? AND category = ? ORDER BY id LIMIT 1 FOR UPDATE", modelProduct.TableName)
+    err = tx.GetContext(ctx, &productId, query, model.Name, model.Category)
+    if err == nil {
+        query = fmt.Sprintf("UPDATE %s SET price = ? WHERE id = ?", modelProduct.TableName)
+        _, err = tx.ExecContext(ctx, query, model.Price, productId)
+        return
+    }
+    if err != sql.ErrNoRows {
+        return
+    }
+
+    // Stock is synced from the variants
+    query = fmt.Sprintf("INSERT INTO %s (name, category, price, stock) VALUES (?, ?, ?, 0)", modelProduct.TableName)
+    res, err := tx.ExecContext(ctx, query, model.Name, model.Category, model.Price)
+    if err != nil {
+        return
+    }
+
+    lastId, err := res.LastInsertId()
+    productId = int(lastId)
+    return
+}
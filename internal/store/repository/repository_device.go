@@ -0,0 +1,63 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+
+    modelMember "store-api/internal/store/domain/member"
+
+    "github.com/jmoiron/sqlx"
+)
+
+// UpsertDevice register the token, token already registered is moved to the member
+func (r repo) UpsertDevice(ctx context.Context, model modelMember.Device) (err error) {
+    query := fmt.Sprintf(`INSERT INTO %s (member_id, token, platform) VALUES (?, ?, ?)
+ON DUPLICATE KEY UPDATE member_id = VALUES(member_id), platform = VALUES(platform)`, modelMember.TableNameDevice)
+    _, err = r.db.ExecContext(ctx, query, model.MemberID, model.Token, model.Platform)
+    return
+}
+
+func (r repo) DeleteDevice(ctx context.Context, memberId int, token string) (err error) {
+    query := fmt.Sprintf("DELETE FROM %s WHERE member_id = ? AND token = ?", modelMember.TableNameDevice)
+    _, err = r.db.ExecContext(ctx, query, memberId, token)
+    return
+}
+
+// DeleteDeviceToken prune token rejected by FCM
+func (r repo) DeleteDeviceToken(ctx context.Context, tokens []string) (err error) {
+    if len(tokens) == 0 {
+        return
+    }
+
+    query, args, err := sqlx.In(fmt.Sprintf("DELETE FROM %s WHERE token IN (?)", modelMember.TableNameDevice), tokens)
+    if err != nil {
+        return
+    }
+    _, err = r.db.ExecContext(ctx, query, args...)
+    return
+}
+
+func (r repo) ListDevice(ctx context.Context, memberId int) (result []modelMember.Device, err error) {
+    query := fmt.Sprintf("SELECT id, member_id, token, platform, created_date, updated_date FROM %s WHERE member_id = ? ORDER BY id",
+        modelMember.TableNameDevice)
+    err = r.db.SelectContext(ctx, &result, query, memberId)
+    return
+}
+
+func (r repo) SetNotificationOptOut(ctx context.Context, model modelMember.NotificationOptOut, optOut bool) (err error) {
+    query := fmt.Sprintf("INSERT IGNORE INTO %s (member_id, channel, category) VALUES (?, ?, ?)",
+        modelMember.TableNameNotificationOptOut)
+    if !optOut {
+        query = fmt.Sprintf("DELETE FROM %s WHERE member_id = ? AND channel = ? AND category = ?",
+            modelMember.TableNameNotificationOptOut)
+    }
+    _, err = r.db.ExecContext(ctx, query, model.MemberID, model.Channel, model.Category)
+    return
+}
+
+func (r repo) IsNotificationOptOut(ctx context.Context, memberId int, channel, category string) (optOut bool, err error) {
+    query := fmt.Sprintf("SELECT EXISTS(SELECT 1 FROM %s WHERE member_id = ? AND channel = ? AND category = ?)",
+        modelMember.TableNameNotificationOptOut)
+    err = r.db.GetContext(ctx, &optOut, query, memberId, channel, category)
+    return
+}
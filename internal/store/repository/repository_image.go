@@ -0,0 +1,80 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/jmoiron/sqlx"
+
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+func (r repo) ListImage(ctx context.Context, productIds []int) (result []modelProduct.Image, err error) {
+    if len(productIds) == 0 {
+        return
+    }
+
+    query, args, err := sqlx.In(fmt.Sprintf(`SELECT id, product_id, s3_name, position, created_date 
+FROM %s WHERE product_id IN (?) ORDER BY product_id, position, id`, modelProduct.TableNameImage), productIds)
+    if err != nil {
+        return
+    }
+
+    err = r.db.SelectContext(ctx, &result, r.db.Rebind(query), args...)
+    return
+}
+
+func (r repo) GetImage(ctx context.Context, imageId int) (result modelProduct.Image, err error) {
+    query := fmt.Sprintf("SELECT id, product_id, s3_name, position, created_date FROM %s", modelProduct.TableNameImage)
+    query += fmt.Sprintf(" WHERE id = %d", imageId)
+
+    err = r.db.GetContext(ctx, &result, query)
+    return
+}
+
+// CreateImage append image to the last position of the product
+func (r repo) CreateImage(ctx context.Context, model modelProduct.Image) (id int, err error) {
+    query := fmt.Sprintf(`INSERT INTO %s (product_id, s3_name, position) 
+SELECT ?, ?, COALESCE(MAX(position) + 1, 0) FROM %s WHERE product_id = ?`, modelProduct.TableNameImage, modelProduct.TableNameImage)
+
+    res, err := r.db.ExecContext(ctx, query, model.ProductID, model.S3Name, model.ProductID)
+    if err != nil {
+        return
+    }
+
+    lastId, err := res.LastInsertId()
+    id = int(lastId)
+    return
+}
+
+// UpdateImagePosition set position of the images following imageIds order
+func (r repo) UpdateImagePosition(ctx context.Context, productId int, imageIds []int) (err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    for position, imageId := range imageIds {
+        query := fmt.Sprintf("UPDATE %s SET position = ? WHERE id = ? AND product_id = ?", modelProduct.TableNameImage)
+        _, err = tx.ExecContext(ctx, query, position, imageId, productId)
+        if err != nil {
+            return
+        }
+    }
+
+    return
+}
+
+func (r repo) DeleteImage(ctx context.Context, imageId int) (err error) {
+    query := fmt.Sprintf("DELETE FROM %s WHERE id = %d", modelProduct.TableNameImage, imageId)
+
+    _, err = r.db.ExecContext(ctx, query)
+    return
+}
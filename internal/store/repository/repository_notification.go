@@ -0,0 +1,17 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+
+    "store-api/internal/base/service/notification"
+)
+
+// CreateNotificationLog record notification send, repository is the notification.Recorder
+func (r repo) CreateNotificationLog(ctx context.Context, model notification.Log) (err error) {
+    query := fmt.Sprintf(`INSERT INTO %s (channel, event_type, recipient, subject, status, attempts, last_error)
+VALUES (?, ?, ?, ?, ?, ?, ?)`, notification.TableNameLog)
+    _, err = r.db.ExecContext(ctx, query, model.Channel, model.EventType, model.Recipient, model.Subject, model.Status, model.Attempts,
+        model.LastError)
+    return
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "strconv"
+    "time"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+
+    "github.com/jmoiron/sqlx"
+)
+
+const outboxColumns = "id, topic, event_type, aggregate_id, payload, status, attempts, last_error, available_at, sent_at, created_date"
+
+// ClaimOutbox pending events available before now, claimed events are not available to other relay until lease end.
+// Row locked by other relay is skipped, so several relays can run at the same time.
+func (r repo) ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) (result []modelOutbox.Event, err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE status = ? AND available_at <= ? ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED",
+        outboxColumns, modelOutbox.TableName)
+    err = tx.SelectContext(ctx, &result, query, modelOutbox.StatusPending, now, limit)
+    if err != nil || len(result) == 0 {
+        return
+    }
+
+    ids := make([]int64, len(result))
+    for i, event := range result {
+        ids[i] = event.ID
+    }
+    query, args, err := sqlx.In(fmt.Sprintf("UPDATE %s SET available_at = ? WHERE id IN (?)", modelOutbox.TableName),
+        now.Add(lease), ids)
+    if err != nil {
+        return
+    }
+    _, err = tx.ExecContext(ctx, query, args...)
+    return
+}
+
+func (r repo) MarkOutboxSent(ctx context.Context, id int64, sentAt time.Time) (err error) {
+    query := fmt.Sprintf("UPDATE %s SET status = ?, attempts = attempts + 1, last_error = '', sent_at = ? WHERE id = ?",
+        modelOutbox.TableName)
+    _, err = r.db.ExecContext(ctx, query, modelOutbox.StatusSent, sentAt, id)
+    return
+}
+
+// MarkOutboxFailed save the attempt, event is published again at available_at while it is still pending
+func (r repo) MarkOutboxFailed(ctx context.Context, model modelOutbox.Event) (err error) {
+    query := fmt.Sprintf("UPDATE %s SET status = ?, attempts = ?, last_error = ?, available_at = ? WHERE id = ?",
+        modelOutbox.TableName)
+    _, err = r.db.ExecContext(ctx, query, model.Status, model.Attempts, model.LastError, model.AvailableAt, model.ID)
+    return
+}
+
+func insertOutbox(ctx context.Context, tx *sqlx.Tx, event modelOutbox.Event) (err error) {
+    query := fmt.Sprintf(`INSERT INTO %s (topic, event_type, aggregate_id, payload, status, available_at) 
+VALUES (?, ?, ?, ?, ?, ?)`, modelOutbox.TableName)
+    // Json column refuse binary string, payload is sent as text
+    _, err = tx.ExecContext(ctx, query, event.Topic, event.EventType, event.AggregateID, string(event.Payload), event.Status,
+        event.AvailableAt)
+    return
+}
+
+// insertOrderOutbox order.created, and order.paid when transaction is already paid
+func insertOrderOutbox(ctx context.Context, tx *sqlx.Tx, model modelTransaction.Transactions) (err error) {
+    err = insertOrderEvent(ctx, tx, modelOutbox.EventOrderCreated, model)
+    if err != nil || model.Status != modelTransaction.StatusSuccess {
+        return
+    }
+    return insertOrderEvent(ctx, tx, modelOutbox.EventOrderPaid, model)
+}
+
+func insertOrderEvent(ctx context.Context, tx *sqlx.Tx, eventType string, model modelTransaction.Transactions) (err error) {
+    payload := modelOutbox.OrderPayload{
+        TransactionID: model.ID,
+        TrxCode:       model.TrxCode,
+        MemberID:      model.MemberID,
+        ProductID:     model.ProductID,
+        VariantID:     model.VariantID,
+        Quantity:      model.Quantity,
+        Amount:        model.Amount,
+        Status:        model.Status,
+    }
+
+    event, err := modelOutbox.NewEvent(modelOutbox.TopicOrders, eventType, strconv.Itoa(model.ID), payload)
+    if err != nil {
+        return
+    }
+    return insertOutbox(ctx, tx, event)
+}
+
+func insertStockOutbox(ctx context.Context, tx *sqlx.Tx, eventType string, movement modelProduct.StockMovement) (err error) {
+    event, err := modelOutbox.NewEvent(modelOutbox.TopicStock, eventType, strconv.Itoa(movement.VariantID),
+        modelOutbox.StockPayload{
+            MovementID: movement.ID,
+            ProductID:  movement.ProductID,
+            VariantID:  movement.VariantID,
+            Type:       movement.Type,
+            Quantity:   movement.Quantity,
+            StockAfter: movement.StockAfter,
+            Reference:  movement.Reference,
+        })
+    if err != nil {
+        return
+    }
+    return insertOutbox(ctx, tx, event)
+}
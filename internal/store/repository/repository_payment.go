@@ -0,0 +1,76 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+)
+
+// UpdateTransactionStatus apply payment status of the channel. Updating to the same status does nothing so a
+// redelivered payment message is accepted, order.paid is written to the outbox when transaction become success.
+// Stock of the failed payment is given back with a refund movement.
+func (r repo) UpdateTransactionStatus(ctx context.Context, trxCode, status, channelRefNo string) (result modelTransaction.Transactions, err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    query := fmt.Sprintf(`SELECT id, member_id, product_id, variant_id, trx_code, channel_id, channel_ref_no, amount, status, 
+quantity FROM %s WHERE trx_code = ? ORDER BY id DESC FOR UPDATE`, modelTransaction.TableName)
+    var transactions []modelTransaction.Transactions
+    err = tx.SelectContext(ctx, &transactions, query, trxCode)
+    if err != nil {
+        return
+    }
+    result, ok := modelTransaction.CurrentOrder(transactions)
+    if !ok {
+        err = sql.ErrNoRows
+        return
+    }
+    if !modelTransaction.CanChangeStatus(result.Status, status) {
+        err = modelTransaction.ErrInvalidStatus
+        return
+    }
+    if result.Status == status {
+        return
+    }
+
+    if channelRefNo == "" {
+        channelRefNo = result.ChannelRefNo
+    }
+    query = fmt.Sprintf("UPDATE %s SET status = ?, channel_ref_no = ? WHERE id = ?", modelTransaction.TableName)
+    _, err = tx.ExecContext(ctx, query, status, channelRefNo, result.ID)
+    if err != nil {
+        return
+    }
+    result.Status = status
+    result.ChannelRefNo = channelRefNo
+
+    switch status {
+    case modelTransaction.StatusSuccess:
+        err = insertOrderEvent(ctx, tx, modelOutbox.EventOrderPaid, result)
+    case modelTransaction.StatusFailed:
+        if result.VariantID == 0 {
+            return // Order before variant, its stock is not in the ledger
+        }
+        _, err = applyStockMovement(ctx, tx, modelProduct.StockMovement{
+            VariantID: result.VariantID,
+            Type:      modelProduct.MovementRefund,
+            Quantity:  result.Quantity,
+            Reference: result.TrxCode,
+            Note:      "Payment failed",
+        })
+    }
+    return
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+    "context"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/stretchr/testify/assert"
+
+    modelTransaction "store-api/internal/store/domain/transaction"
+)
+
+func TestRepository_UpdateTransactionStatus_FailedRetry(t *testing.T) {
+    repo, mock := newMockRepository(t)
+
+    columns := []string{"id", "member_id", "product_id", "variant_id", "trx_code", "channel_id", "channel_ref_no", "amount",
+        "status", "quantity"}
+    mock.ExpectBegin()
+    // Retry of the trx code failed after the order was created, its row is the newest
+    mock.ExpectQuery("SELECT .+ FROM transaction WHERE trx_code = \\? ORDER BY id DESC FOR UPDATE").WithArgs("TRX-1").
+        WillReturnRows(sqlmock.NewRows(columns).
+            AddRow(4, 7, 1, 2, "TRX-1", "", "", 200, modelTransaction.StatusFailed, 2).
+            AddRow(3, 7, 1, 2, "TRX-1", "", "", 200, modelTransaction.StatusPending, 2))
+    mock.ExpectExec("UPDATE transaction SET status = \\?, channel_ref_no = \\? WHERE id = \\?").
+        WithArgs(modelTransaction.StatusSuccess, "REF-1", 3).WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectExec("INSERT INTO outbox_event").WillReturnResult(sqlmock.NewResult(1, 1))
+    mock.ExpectCommit()
+
+    result, err := repo.UpdateTransactionStatus(context.Background(), "TRX-1", modelTransaction.StatusSuccess, "REF-1")
+    assert.Nil(t, err)
+    assert.Equal(t, 3, result.ID)
+    assert.Equal(t, modelTransaction.StatusSuccess, result.Status)
+    assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jmoiron/sqlx"
+
+    modelCart "store-api/internal/store/domain/cart"
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+const reservationColumns = "id, member_id, product_id, variant_id, quantity, status, expired_at, created_date, updated_date"
+
+// CreateCartWithReservation add cart item and hold its stock until reservation.ExpiredAt.
+// Return modelProduct.ErrInsufficientStock when available stock is not enough, cart is not created.
+func (r repo) CreateCartWithReservation(ctx context.Context, cart modelCart.Cart, reservation modelCart.Reservation) (result modelCart.Reservation, err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    result, err = insertCartWithReservation(ctx, tx, cart, reservation)
+    return
+}
+
+// ReleaseReservation give back active hold of member, all variants of the product when variantId is 0
+func (r repo) ReleaseReservation(ctx context.Context, memberId, productId, variantId int) (err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    err = releaseMemberReservation(ctx, tx, memberId, productId, variantId)
+    return
+}
+
+// ReleaseExpiredReservation release at most limit holds which expired before now. Row locked by other
+// worker is skipped, so several workers can run at the same time.
+func (r repo) ReleaseExpiredReservation(ctx context.Context, now time.Time, limit int) (released int, err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE status = ? AND expired_at <= ? ORDER BY expired_at LIMIT ? FOR UPDATE SKIP LOCKED",
+        reservationColumns, modelCart.TableNameReservation)
+
+    var reservations []modelCart.Reservation
+    err = tx.SelectContext(ctx, &reservations, query, modelCart.ReservationActive, now, limit)
+    if err != nil {
+        return
+    }
+
+    err = releaseReservations(ctx, tx, reservations, modelCart.ReservationExpired)
+    if err != nil {
+        return
+    }
+
+    released = len(reservations)
+    return
+}
+
+// SumActiveReservation stock held by the member for the variant
+func (r repo) SumActiveReservation(ctx context.Context, memberId, variantId int) (quantity int, err error) {
+    query := fmt.Sprintf("SELECT COALESCE(SUM(quantity), 0) FROM %s WHERE member_id = ? AND variant_id = ? AND status = ?",
+        modelCart.TableNameReservation)
+
+    err = r.db.GetContext(ctx, &quantity, query, memberId, variantId, modelCart.ReservationActive)
+    return
+}
+
+// insertCartWithReservation add cart item and hold its stock inside tx
+func insertCartWithReservation(ctx context.Context, tx *sqlx.Tx, cart modelCart.Cart, reservation modelCart.Reservation) (result modelCart.Reservation, err error) {
+    query := fmt.Sprintf(`INSERT INTO %s SET member_id = ?, product_id = ?, variant_id = ?, quantity = ?, 
+is_active = true`, modelCart.TableName)
+    _, err = tx.ExecContext(ctx, query, cart.MemberID, cart.ProductID, cart.VariantID, cart.Quantity)
+    if err != nil {
+        return
+    }
+
+    query = fmt.Sprintf(`INSERT INTO %s (member_id, product_id, variant_id, quantity, status, expired_at) 
+VALUES (?, ?, ?, ?, ?, ?)`, modelCart.TableNameReservation)
+    res, err := tx.ExecContext(ctx, query, reservation.MemberID, reservation.ProductID, reservation.VariantID, reservation.Quantity,
+        modelCart.ReservationActive, reservation.ExpiredAt)
+    if err != nil {
+        return
+    }
+
+    id, err := res.LastInsertId()
+    if err != nil {
+        return
+    }
+
+    result = reservation
+    result.ID = int(id)
+    result.Status = modelCart.ReservationActive
+
+    _, err = applyStockMovement(ctx, tx, modelProduct.StockMovement{
+        VariantID: reservation.VariantID,
+        Type:      modelProduct.MovementReservation,
+        Quantity:  -reservation.Quantity,
+        Reference: reservationReference(result.ID),
+        Note:      "Hold",
+    })
+    return
+}
+
+// releaseMemberReservation release active hold of member inside tx, all variants of the product when variantId is 0
+func releaseMemberReservation(ctx context.Context, tx *sqlx.Tx, memberId, productId, variantId int) (err error) {
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE member_id = ? AND product_id = ? AND status = ?",
+        reservationColumns, modelCart.TableNameReservation)
+    args := []interface{}{memberId, productId, modelCart.ReservationActive}
+    if variantId != 0 {
+        query += " AND variant_id = ?"
+        args = append(args, variantId)
+    }
+
+    var reservations []modelCart.Reservation
+    err = tx.SelectContext(ctx, &reservations, query+" FOR UPDATE", args...)
+    if err != nil {
+        return
+    }
+
+    return releaseReservations(ctx, tx, reservations, modelCart.ReservationReleased)
+}
+
+// convertReservation give back the holds of member for the variant, the sale movement take the stock instead
+func convertReservation(ctx context.Context, tx *sqlx.Tx, memberId, variantId int) (err error) {
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE member_id = ? AND variant_id = ? AND status = ? FOR UPDATE",
+        reservationColumns, modelCart.TableNameReservation)
+
+    var reservations []modelCart.Reservation
+    err = tx.SelectContext(ctx, &reservations, query, memberId, variantId, modelCart.ReservationActive)
+    if err != nil {
+        return
+    }
+
+    return releaseReservations(ctx, tx, reservations, modelCart.ReservationConverted)
+}
+
+func releaseReservations(ctx context.Context, tx *sqlx.Tx, reservations []modelCart.Reservation, status string) (err error) {
+    for _, reservation := range reservations {
+        query := fmt.Sprintf("UPDATE %s SET status = ? WHERE id = ?", modelCart.TableNameReservation)
+        _, err = tx.ExecContext(ctx, query, status, reservation.ID)
+        if err != nil {
+            return
+        }
+
+        _, err = applyStockMovement(ctx, tx, modelProduct.StockMovement{
+            VariantID: reservation.VariantID,
+            Type:      modelProduct.MovementReservation,
+            Quantity:  reservation.Quantity,
+            Reference: reservationReference(reservation.ID),
+            Note:      "Release " + status,
+        })
+        if err != nil {
+            return
+        }
+    }
+    return
+}
+
+func reservationReference(reservationId int) string {
+    return fmt.Sprintf("RSV-%d", reservationId)
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/jmoiron/sqlx"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+func (r repo) ListStockMovement(ctx context.Context, productId, variantId, limit, offset int) (result []modelProduct.StockMovement, err error) {
+    query := fmt.Sprintf(`SELECT id, product_id, variant_id, type, quantity, stock_after, reference, note, created_date 
+FROM %s WHERE product_id = ?`, modelProduct.TableNameStockMovement)
+    args := []interface{}{productId}
+    if variantId != 0 {
+        query += " AND variant_id = ?"
+        args = append(args, variantId)
+    }
+    query += " ORDER BY id DESC LIMIT ? OFFSET ?"
+    args = append(args, limit, offset)
+
+    err = r.db.SelectContext(ctx, &result, query, args...)
+    return
+}
+
+func (r repo) CountStockMovement(ctx context.Context, productId, variantId int) (total int, err error) {
+    query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE product_id = ?", modelProduct.TableNameStockMovement)
+    args := []interface{}{productId}
+    if variantId != 0 {
+        query += " AND variant_id = ?"
+        args = append(args, variantId)
+    }
+
+    err = r.db.GetContext(ctx, &total, query, args...)
+    return
+}
+
+// GetLedgerStock product stock derived from the ledger, must be the same as product.stock
+func (r repo) GetLedgerStock(ctx context.Context, productId int) (stock int, err error) {
+    query := fmt.Sprintf(`SELECT COALESCE(SUM(m.quantity), 0) FROM %s m JOIN %s v ON v.id = m.variant_id 
+WHERE m.product_id = ? AND v.is_active = true`, modelProduct.TableNameStockMovement, modelProduct.TableNameVariant)
+
+    err = r.db.GetContext(ctx, &stock, query, productId)
+    return
+}
+
+// CreateStockMovement apply single movement in its own transaction, ex: restock by admin
+func (r repo) CreateStockMovement(ctx context.Context, movement modelProduct.StockMovement) (result modelProduct.StockMovement, err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    result, err = applyStockMovement(ctx, tx, movement)
+    return
+}
+
+// applyStockMovement lock the variant, change its stock by movement.Quantity, sync product stock and append the ledger.
+// Every stock change must go through here, inside the transaction of the change itself.
+func applyStockMovement(ctx context.Context, tx *sqlx.Tx, movement modelProduct.StockMovement) (result modelProduct.StockMovement, err error) {
+    var variant modelProduct.Variant
+    query := fmt.Sprintf("SELECT id, product_id, stock FROM %s WHERE id = ? FOR UPDATE", modelProduct.TableNameVariant)
+    err = tx.GetContext(ctx, &variant, query, movement.VariantID)
+    if err != nil {
+        return
+    }
+
+    result = movement
+    result.ProductID = variant.ProductID
+    result.StockAfter = variant.Stock + movement.Quantity
+    if result.StockAfter < 0 {
+        err = modelProduct.ErrInsufficientStock
+        return
+    }
+
+    query = fmt.Sprintf("UPDATE %s SET stock = ? WHERE id = ?", modelProduct.TableNameVariant)
+    _, err = tx.ExecContext(ctx, query, result.StockAfter, variant.ID)
+    if err != nil {
+        return
+    }
+
+    // Product stock is the sum of its variants
+    err = syncProductStock(ctx, tx, variant.ProductID)
+    if err != nil {
+        return
+    }
+
+    query = fmt.Sprintf(`INSERT INTO %s (product_id, variant_id, type, quantity, stock_after, reference, note) 
+VALUES (?, ?, ?, ?, ?, ?, ?)`, modelProduct.TableNameStockMovement)
+    res, err := tx.ExecContext(ctx, query, result.ProductID, result.VariantID, result.Type, result.Quantity, result.StockAfter,
+        result.Reference, result.Note)
+    if err != nil {
+        return
+    }
+
+    id, err := res.LastInsertId()
+    if err != nil {
+        return
+    }
+    result.ID = int(id)
+    result.CreatedDate = time.Now()
+
+    err = insertStockOutbox(ctx, tx, modelOutbox.EventStockChanged, result)
+    if err != nil {
+        return
+    }
+
+    // Wishlist member is notified by the consumer of the event
+    if variant.Stock <= 0 && result.StockAfter > 0 && modelProduct.IsRestockMovement(result.Type) {
+        err = insertStockOutbox(ctx, tx, modelOutbox.EventBackInStock, result)
+    }
+    return
+}
@@ -0,0 +1,55 @@
+package repository
+
+import (
+    "context"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/stretchr/testify/assert"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+)
+
+func TestRepository_CreateStockMovement_BackInStock(t *testing.T) {
+    tests := []struct {
+        name         string
+        movementType string
+        backInStock  bool
+    }{
+        {"Restock", modelProduct.MovementRestock, true},
+        {"Adjustment", modelProduct.MovementAdjustment, true},
+        {"Released hold", modelProduct.MovementReservation, false},
+        {"Refund", modelProduct.MovementRefund, false},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            repo, mock := newMockRepository(t)
+
+            mock.ExpectBegin()
+            mock.ExpectQuery("SELECT id, product_id, stock FROM product_variant WHERE id = \\? FOR UPDATE").WithArgs(2).
+                WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "stock"}).AddRow(2, 1, 0))
+            mock.ExpectExec("UPDATE product_variant SET stock = \\?").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectExec("UPDATE product SET stock").WillReturnResult(sqlmock.NewResult(0, 1))
+            mock.ExpectExec("INSERT INTO stock_movement").WillReturnResult(sqlmock.NewResult(9, 1))
+            mock.ExpectExec("INSERT INTO outbox_event").
+                WithArgs(modelOutbox.TopicStock, modelOutbox.EventStockChanged, "2", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+                WillReturnResult(sqlmock.NewResult(1, 1))
+            if tt.backInStock {
+                mock.ExpectExec("INSERT INTO outbox_event").
+                    WithArgs(modelOutbox.TopicStock, modelOutbox.EventBackInStock, "2", sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+                    WillReturnResult(sqlmock.NewResult(2, 1))
+            }
+            mock.ExpectCommit()
+
+            result, err := repo.CreateStockMovement(context.Background(), modelProduct.StockMovement{
+                VariantID: 2,
+                Type:      tt.movementType,
+                Quantity:  1,
+            })
+            assert.Nil(t, err)
+            assert.Equal(t, 1, result.StockAfter)
+            assert.Nil(t, mock.ExpectationsWereMet())
+        })
+    }
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "database/sql/driver"
+    "io"
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+
+    "github.com/DATA-DOG/go-sqlmock"
+    "github.com/jmoiron/sqlx"
+    "github.com/stretchr/testify/assert"
+
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    "store-api/pkg/middleware"
+    "store-api/pkg/tracing"
+)
+
+// fakeConnector answer every query with one row, the locked variant for SELECT ... FOR UPDATE, the product otherwise
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{query: query}, nil }
+func (fakeConn) Close() error                              { return nil }
+func (fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+type fakeStmt struct {
+    query string
+}
+
+func (fakeStmt) Close() error                                    { return nil }
+func (fakeStmt) NumInput() int                                   { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return fakeResult{}, nil }
+func (s fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+    if strings.Contains(s.query, "FOR UPDATE") {
+        return &fakeRows{columns: []string{"id", "product_id", "stock"}, values: []driver.Value{int64(1), int64(1), int64(5)}}, nil
+    }
+    return &fakeRows{columns: []string{"id", "name", "category", "price", "stock"},
+        values: []driver.Value{int64(1), "Shirt", "fashion", float64(100), int64(5)}}, nil
+}
+
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 1, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeRows struct {
+    columns []string
+    values  []driver.Value
+    read    bool
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+    if r.read {
+        return io.EOF
+    }
+    r.read = true
+    copy(dest, r.values)
+    return nil
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func TestRepository_SQLSpanInRequestTrace(t *testing.T) {
+    tracer := tracing.NewMemoryTracer()
+    tracing.SetTracer(tracer)
+    defer tracing.SetTracer(nil)
+
+    db := sqlx.NewDb(sql.OpenDB(tracing.WrapConnector(fakeConnector{}, "mysql")), "mysql")
+    defer db.Close()
+    repo := NewStoreRepository(db)
+
+    serve := func(handler func(r *http.Request) error) tracing.MemorySpan {
+        tracer.Reset()
+        rw := httptest.NewRecorder()
+        middleware.Tracing()(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+            assert.Nil(t, handler(r))
+        })).ServeHTTP(rw, httptest.NewRequest(http.MethodPost, "/api/v2/stock/adjust", nil))
+
+        spans := tracer.Spans("http.request")
+        assert.Len(t, spans, 1)
+        return spans[0]
+    }
+
+    request := serve(func(r *http.Request) error {
+        product, err := repo.GetProduct(r.Context(), 1)
+        assert.Equal(t, "Shirt", product.Name)
+        return err
+    })
+    query := tracer.Spans("sql.query")
+    assert.Len(t, query, 1)
+    assert.Equal(t, request.SpanID, query[0].ParentID)
+    assert.Equal(t, request.TraceID, query[0].TraceID)
+
+    request = serve(func(r *http.Request) error {
+        _, err := repo.ListProduct(r.Context(), "")
+        return err
+    })
+    query = tracer.Spans("sql.query")
+    assert.Len(t, query, 1)
+    assert.Equal(t, request.SpanID, query[0].ParentID)
+
+    // The ledger write run in a transaction, each statement is a child of the request span as well
+    request = serve(func(r *http.Request) error {
+        movement, err := repo.CreateStockMovement(r.Context(), modelProduct.StockMovement{
+            VariantID: 1,
+            Type:      modelProduct.MovementRestock,
+            Quantity:  3,
+        })
+        assert.Equal(t, 8, movement.StockAfter)
+        return err
+    })
+    assert.Len(t, tracer.Spans("sql.begin"), 1)
+    assert.Len(t, tracer.Spans("sql.commit"), 1)
+    for _, span := range tracer.Spans("") {
+        if span.Operation == "http.request" {
+            continue
+        }
+        assert.Equal(t, request.SpanID, span.ParentID, span.Operation+" "+span.Resource)
+        assert.Equal(t, request.TraceID, span.TraceID)
+    }
+}
+
+func newMockRepository(t *testing.T) (StoreRepository, sqlmock.Sqlmock) {
+    db, mock, err := sqlmock.New()
+    assert.Nil(t, err)
+    t.Cleanup(func() { db.Close() })
+    return NewStoreRepository(sqlx.NewDb(db, "mysql")), mock
+}
+
+func TestRepository_CreateTransaction_InsufficientStock(t *testing.T) {
+    repo, mock := newMockRepository(t)
+
+    mock.ExpectBegin()
+    mock.ExpectExec("UPDATE cart SET is_active = false").WillReturnResult(sqlmock.NewResult(0, 1))
+    mock.ExpectQuery("SELECT .+ FROM cart_reservation WHERE member_id = \\? AND variant_id = \\? AND status = \\? FOR UPDATE").
+        WillReturnRows(sqlmock.NewRows([]string{"id"}))
+    // Another order took the stock after the check of the service, the row lock see only 1 left
+    mock.ExpectQuery("SELECT id, product_id, stock FROM product_variant WHERE id = \\? FOR UPDATE").WithArgs(2).
+        WillReturnRows(sqlmock.NewRows([]string{"id", "product_id", "stock"}).AddRow(2, 1, 1))
+    mock.ExpectRollback()
+
+    err := repo.CreateTransaction(context.Background(), modelTransaction.Transactions{
+        MemberID:  7,
+        ProductID: 1,
+        VariantID: 2,
+        TrxCode:   "TRX-1",
+        Quantity:  2,
+    })
+    assert.ErrorIs(t, err, modelProduct.ErrInsufficientStock, "Error must not be replaced by the rollback result")
+    assert.Nil(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+
+    modelCart "store-api/internal/store/domain/cart"
+
+    "github.com/jmoiron/sqlx"
+)
+
+const wishlistColumns = "id, member_id, product_id, variant_id, created_date"
+
+// AddWishlist save the variant for later, variant already in the wishlist is kept
+func (r repo) AddWishlist(ctx context.Context, model modelCart.Wishlist) (err error) {
+    query := fmt.Sprintf("INSERT IGNORE INTO %s (member_id, product_id, variant_id) VALUES (?, ?, ?)",
+        modelCart.TableNameWishlist)
+    _, err = r.db.ExecContext(ctx, query, model.MemberID, model.ProductID, model.VariantID)
+    return
+}
+
+// DeleteWishlist remove the variant, all variants of the product when variantId is 0
+func (r repo) DeleteWishlist(ctx context.Context, memberId, productId, variantId int) (err error) {
+    query := fmt.Sprintf("DELETE FROM %s WHERE member_id = ? AND product_id = ?", modelCart.TableNameWishlist)
+    args := []interface{}{memberId, productId}
+    if variantId != 0 {
+        query += " AND variant_id = ?"
+        args = append(args, variantId)
+    }
+
+    _, err = r.db.ExecContext(ctx, query, args...)
+    return
+}
+
+func (r repo) ListWishlist(ctx context.Context, memberId int) (result []modelCart.Wishlist, err error) {
+    query := fmt.Sprintf("SELECT %s FROM %s WHERE member_id = ? ORDER BY id DESC", wishlistColumns, modelCart.TableNameWishlist)
+    err = r.db.SelectContext(ctx, &result, query, memberId)
+    return
+}
+
+// ListWishlistMember member who saved the variant, notified when it is back in stock
+func (r repo) ListWishlistMember(ctx context.Context, variantId int) (result []int, err error) {
+    query := fmt.Sprintf("SELECT member_id FROM %s WHERE variant_id = ? ORDER BY id", modelCart.TableNameWishlist)
+    err = r.db.SelectContext(ctx, &result, query, variantId)
+    return
+}
+
+// MoveWishlistToCart remove the variant from the wishlist and add it to the cart in one transaction. Stock is held
+// when reservation is not nil. Return sql.ErrNoRows when the variant is not in the wishlist.
+func (r repo) MoveWishlistToCart(ctx context.Context, cart modelCart.Cart, reservation *modelCart.Reservation) (err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    query := fmt.Sprintf("DELETE FROM %s WHERE member_id = ? AND variant_id = ?", modelCart.TableNameWishlist)
+    res, err := tx.ExecContext(ctx, query, cart.MemberID, cart.VariantID)
+    if err != nil {
+        return
+    }
+    if err = requireAffected(res); err != nil {
+        return
+    }
+
+    if reservation != nil {
+        _, err = insertCartWithReservation(ctx, tx, cart, *reservation)
+        return
+    }
+
+    query = fmt.Sprintf("INSERT INTO %s SET member_id = ?, product_id = ?, variant_id = ?, quantity = ?, is_active = true",
+        modelCart.TableName)
+    _, err = tx.ExecContext(ctx, query, cart.MemberID, cart.ProductID, cart.VariantID, cart.Quantity)
+    return
+}
+
+// MoveCartToWishlist deactivate the cart item, release its hold and save it in the wishlist in one transaction,
+// all variants of the product when variantId is 0. Return sql.ErrNoRows when the item is not in the cart.
+func (r repo) MoveCartToWishlist(ctx context.Context, memberId, productId, variantId int) (err error) {
+    tx, err := r.db.BeginTxx(ctx, nil)
+    if err != nil {
+        return
+    }
+    defer func() {
+        if err == nil {
+            err = tx.Commit()
+        } else {
+            tx.Rollback()
+        }
+    }()
+
+    query := fmt.Sprintf("SELECT id, member_id, product_id, variant_id, quantity, is_active FROM %s "+
+        "WHERE member_id = ? AND product_id = ? AND is_active = true", modelCart.TableName)
+    args := []interface{}{memberId, productId}
+    if variantId != 0 {
+        query += " AND variant_id = ?"
+        args = append(args, variantId)
+    }
+
+    var carts []modelCart.Cart
+    err = tx.SelectContext(ctx, &carts, query+" FOR UPDATE", args...)
+    if err != nil {
+        return
+    }
+    if len(carts) == 0 {
+        err = sql.ErrNoRows
+        return
+    }
+
+    for _, cart := range carts {
+        err = moveCartItemToWishlist(ctx, tx, cart)
+        if err != nil {
+            return
+        }
+    }
+
+    err = releaseMemberReservation(ctx, tx, memberId, productId, variantId)
+    return
+}
+
+func moveCartItemToWishlist(ctx context.Context, tx *sqlx.Tx, cart modelCart.Cart) (err error) {
+    query := fmt.Sprintf("UPDATE %s SET is_active = false WHERE id = ?", modelCart.TableName)
+    _, err = tx.ExecContext(ctx, query, cart.ID)
+    if err != nil {
+        return
+    }
+
+    query = fmt.Sprintf("INSERT IGNORE INTO %s (member_id, product_id, variant_id) VALUES (?, ?, ?)",
+        modelCart.TableNameWishlist)
+    _, err = tx.ExecContext(ctx, query, cart.MemberID, cart.ProductID, cart.VariantID)
+    return
+}
+
+// requireAffected sql.ErrNoRows when no row is changed
+func requireAffected(res sql.Result) error {
+    affected, err := res.RowsAffected()
+    if err != nil {
+        return err
+    }
+    if affected == 0 {
+        return sql.ErrNoRows
+    }
+    return nil
+}
@@ -1,17 +1,46 @@
 package service
 
 import (
+    "context"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
     presenterCart "store-api/internal/store/presenter/cart"
+    presenterMedia "store-api/internal/store/presenter/media"
     presenterMember "store-api/internal/store/presenter/member"
     presenterProduct "store-api/internal/store/presenter/product"
     presenterTransaction "store-api/internal/store/presenter/transaction"
+    "store-api/pkg/data/filedata"
 )
 
 type StoreService interface {
-    ListProduct(request presenterProduct.ProductRequest) (result []presenterProduct.ProductResponse, httpStatus int, err error)
-    AddToCart(request presenterCart.CartRequest) (httpStatus int, err error)
-    ViewCart(request presenterCart.CartViewRequest) (result []presenterCart.CartResponse, httpStatus int, err error)
-    DeleteProductInCart(request presenterCart.CartProductDeleteRequest) (httpStatus int, err error)
-    CreateTransaction(request presenterTransaction.TransactionRequest) (httpStatus int, err error)
-    Login(request presenterMember.LoginRequest) (result presenterMember.LoginResponse, httpStatus int, err error)
+    ListProduct(ctx context.Context, request presenterProduct.ProductRequest) (result []presenterProduct.ProductResponse, httpStatus int, err error)
+    GetProduct(ctx context.Context, productId int) (result presenterProduct.ProductResponse, httpStatus int, err error)
+    AddToCart(ctx context.Context, request presenterCart.CartRequest) (httpStatus int, err error)
+    ViewCart(ctx context.Context, request presenterCart.CartViewRequest) (result []presenterCart.CartResponse, httpStatus int, err error)
+    DeleteProductInCart(ctx context.Context, request presenterCart.CartProductDeleteRequest) (httpStatus int, err error)
+    CreateTransaction(ctx context.Context, request presenterTransaction.TransactionRequest) (httpStatus int, err error)
+    UploadProductImage(ctx context.Context, request presenterProduct.ImageUploadRequest, file *filedata.UploadFile) (result presenterProduct.ImageResponse, httpStatus int, err error)
+    ReorderProductImage(ctx context.Context, request presenterProduct.ImageReorderRequest) (httpStatus int, err error)
+    DeleteProductImage(ctx context.Context, request presenterProduct.ImageDeleteRequest) (httpStatus int, err error)
+    ImportProduct(ctx context.Context, request presenterProduct.ImportRequest) (result presenterProduct.ImportResponse, httpStatus int, err error)
+    ExportProduct(ctx context.Context, request presenterProduct.ExportRequest) (result presenterProduct.ExportResponse, httpStatus int, err error)
+    StockHistory(ctx context.Context, request presenterProduct.StockHistoryRequest) (result presenterProduct.StockHistoryResponse, httpStatus int, err error)
+    AdjustStock(ctx context.Context, request presenterProduct.StockAdjustRequest) (result presenterProduct.StockMovementResponse, httpStatus int, err error)
+    SetReorderThreshold(ctx context.Context, request presenterProduct.ReorderThresholdRequest) (httpStatus int, err error)
+    CreateUploadURL(request presenterMedia.UploadURLRequest) (result presenterMedia.UploadURLResponse, httpStatus int, err error)
+    CreateDownloadURL(request presenterMedia.DownloadURLRequest) (result presenterMedia.DownloadURLResponse, httpStatus int, err error)
+    ReleaseExpiredReservation(ctx context.Context) (released int, httpStatus int, err error)
+    RelayOutbox(ctx context.Context) (sent int, httpStatus int, err error)
+    UpdateTransactionStatus(ctx context.Context, request presenterTransaction.PaymentStatusRequest) (httpStatus int, err error)
+    RegisterDevice(ctx context.Context, request presenterMember.DeviceRequest) (httpStatus int, err error)
+    UnregisterDevice(ctx context.Context, request presenterMember.DeviceRequest) (httpStatus int, err error)
+    SetNotificationOptOut(ctx context.Context, request presenterMember.NotificationOptOutRequest) (httpStatus int, err error)
+    SendOrderNotification(ctx context.Context, eventType string, order modelOutbox.OrderPayload) (sent int, httpStatus int, err error)
+    AddWishlist(ctx context.Context, request presenterCart.WishlistRequest) (httpStatus int, err error)
+    DeleteWishlist(ctx context.Context, request presenterCart.WishlistRequest) (httpStatus int, err error)
+    ListWishlist(ctx context.Context, request presenterCart.WishlistViewRequest) (result []presenterCart.WishlistResponse, httpStatus int, err error)
+    MoveWishlistToCart(ctx context.Context, request presenterCart.WishlistMoveRequest) (httpStatus int, err error)
+    MoveCartToWishlist(ctx context.Context, request presenterCart.WishlistMoveRequest) (httpStatus int, err error)
+    NotifyBackInStock(ctx context.Context, stock modelOutbox.StockPayload) (notified int, httpStatus int, err error)
+    Login(ctx context.Context, request presenterMember.LoginRequest) (result presenterMember.LoginResponse, httpStatus int, err error)
 }
@@ -1,18 +1,27 @@
 package service
 
 import (
+    "context"
     "database/sql"
     "errors"
     "net/http"
     "time"
 
+    "store-api/internal/base/service/notification"
     modelCart "store-api/internal/store/domain/cart"
+    modelProduct "store-api/internal/store/domain/product"
     modelTransaction "store-api/internal/store/domain/transaction"
     presenterCart "store-api/internal/store/presenter/cart"
     presenterMember "store-api/internal/store/presenter/member"
     presenterProduct "store-api/internal/store/presenter/product"
     presenterTransaction "store-api/internal/store/presenter/transaction"
     "store-api/internal/store/repository"
+    "store-api/pkg/awsutil"
+    "store-api/pkg/imageproc"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/flockhook"
+    "store-api/pkg/metric"
+    "store-api/pkg/middleware"
     "store-api/pkg/security"
 
     "github.com/jinzhu/copier"
@@ -20,19 +29,81 @@ import (
     "golang.org/x/crypto/bcrypt"
 )
 
+const (
+    DefaultReservationTTL = 15 * time.Minute
+    DefaultEventSource    = "store-api"
+)
+
+// Config optional feature of the service
+type Config struct {
+    ReservationEnabled bool          // Hold stock when product is added to cart
+    ReservationTTL     time.Duration // How long stock is held, DefaultReservationTTL when empty
+
+    Publisher     messaging.KafkaPublisher // Low stock alert is published when set, required by RelayOutbox
+    LowStockTopic string                   // modelProduct.LowStockTopic when empty
+    Flock         *flockhook.Hook          // Low stock alert is sent to the channel when set
+    EventSource   string                   // Source of the published event, DefaultEventSource when empty
+
+    Push     notification.Sender   // Order status push notification, the dispatcher with the push channel
+    Notifier notification.Notifier // Order status in-app notification
+    Email    bool                  // Notifier has the email channel, email is sent to member signed up with email
+
+    Metric metric.StatsdMonitoring // Order and failed transaction metric is recorded when set
+}
+
 // NewService creates new user service
-func NewService(repo repository.StoreRepository) StoreService {
-    return &service{
-        repo: repo,
+func NewService(repo repository.StoreRepository, storage *awsutil.AWSService, config Config) StoreService {
+    if config.ReservationTTL <= 0 {
+        config.ReservationTTL = DefaultReservationTTL
     }
+    if config.EventSource == "" {
+        config.EventSource = DefaultEventSource
+    }
+    if config.LowStockTopic == "" {
+        config.LowStockTopic = modelProduct.LowStockTopic
+    }
+
+    svc := &service{
+        repo:    repo,
+        storage: storage,
+        config:  config,
+    }
+    if storage != nil {
+        svc.imageProcessor = imageproc.New(storage)
+    }
+    return svc
 }
 
 type service struct {
-    repo repository.StoreRepository
+    repo    repository.StoreRepository
+    storage *awsutil.AWSService
+    config  Config
+
+    imageProcessor *imageproc.Processor
+}
+
+func (s service) ListProduct(ctx context.Context, request presenterProduct.ProductRequest) (result []presenterProduct.ProductResponse, httpStatus int, err error) {
+    findAllProduct, err := s.repo.ListProduct(ctx, request.Category)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    result, err = s.toProductResponses(ctx, findAllProduct)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    return
 }
 
-func (s service) ListProduct(request presenterProduct.ProductRequest) (result []presenterProduct.ProductResponse, httpStatus int, err error) {
-    findAllProduct, err := s.repo.ListProduct(request.Category)
+// GetProduct product detail with the variants and images
+func (s service) GetProduct(ctx context.Context, productId int) (result presenterProduct.ProductResponse, httpStatus int, err error) {
+    findProduct, err := s.repo.GetProduct(ctx, productId)
     if err == sql.ErrNoRows {
         httpStatus = http.StatusNotFound
         err = errors.New("Product not found")
@@ -42,17 +113,103 @@ func (s service) ListProduct(request presenterProduct.ProductRequest) (result []
         httpStatus = http.StatusInternalServerError
         return
     }
-    copier.Copy(&result, &findAllProduct)
+
+    products, err := s.toProductResponses(ctx, []modelProduct.Product{findProduct})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    result = products[0]
+    return
+}
+
+// toProductResponses products with their variants and images
+func (s service) toProductResponses(ctx context.Context, products []modelProduct.Product) (result []presenterProduct.ProductResponse, err error) {
+    copier.Copy(&result, &products)
+
+    productIds := make([]int, len(products))
+    for i, product := range products {
+        productIds[i] = product.ID
+    }
+    findAllVariant, err := s.repo.ListVariant(ctx, productIds)
+    if err != nil {
+        return
+    }
+
+    findAllImage, err := s.repo.ListImage(ctx, productIds)
+    if err != nil {
+        return
+    }
+
+    variants := make(map[int][]modelProduct.Variant)
+    for _, variant := range findAllVariant {
+        variants[variant.ProductID] = append(variants[variant.ProductID], variant)
+    }
+    images := make(map[int][]presenterProduct.ImageResponse)
+    for _, image := range findAllImage {
+        images[image.ProductID] = append(images[image.ProductID], s.toImageResponse(image))
+    }
+    for i, product := range products {
+        result[i].Variants = []presenterProduct.VariantResponse{}
+        for _, variant := range variants[product.ID] {
+            result[i].Variants = append(result[i].Variants, presenterProduct.VariantResponse{
+                ID:     variant.ID,
+                SKU:    variant.SKU,
+                Size:   variant.Size,
+                Colour: variant.Colour,
+                Price:  variant.GetPrice(product),
+                Stock:  variant.Stock,
+            })
+        }
+
+        result[i].Images = images[product.ID]
+        if result[i].Images == nil {
+            result[i].Images = []presenterProduct.ImageResponse{}
+        }
+    }
     return
 }
 
-func (s service) AddToCart(request presenterCart.CartRequest) (httpStatus int, err error) {
+func (s service) AddToCart(ctx context.Context, request presenterCart.CartRequest) (httpStatus int, err error) {
     var (
         cart = modelCart.Cart{}
     )
 
+    variant, httpStatus, err := s.resolveVariant(ctx, request.ProductID, request.VariantID)
+    if err != nil {
+        return
+    }
+
     copier.Copy(&cart, &request)
-    err = s.repo.CreateCart(cart)
+    cart.ProductID = variant.ProductID
+    cart.VariantID = variant.ID
+
+    if s.config.ReservationEnabled {
+        if cart.Quantity <= 0 {
+            httpStatus = http.StatusBadRequest
+            err = errors.New("Quantity must be greater than 0")
+            return
+        }
+
+        _, err = s.repo.CreateCartWithReservation(ctx, cart, modelCart.Reservation{
+            MemberID:  cart.MemberID,
+            ProductID: cart.ProductID,
+            VariantID: cart.VariantID,
+            Quantity:  cart.Quantity,
+            ExpiredAt: time.Now().Add(s.config.ReservationTTL),
+        })
+        if err == modelProduct.ErrInsufficientStock {
+            httpStatus = http.StatusBadRequest
+            return
+        }
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+        return
+    }
+
+    err = s.repo.CreateCart(ctx, cart)
     if err != nil {
         httpStatus = http.StatusInternalServerError
         return
@@ -61,8 +218,8 @@ func (s service) AddToCart(request presenterCart.CartRequest) (httpStatus int, e
     return
 }
 
-func (s service) ViewCart(request presenterCart.CartViewRequest) (result []presenterCart.CartResponse, httpStatus int, err error) {
-    findAllCart, err := s.repo.GetCart(request.MemberID)
+func (s service) ViewCart(ctx context.Context, request presenterCart.CartViewRequest) (result []presenterCart.CartResponse, httpStatus int, err error) {
+    findAllCart, err := s.repo.GetCart(ctx, request.MemberID)
     if err == sql.ErrNoRows {
         httpStatus = http.StatusNotFound
         err = errors.New("Cart not found")
@@ -76,8 +233,8 @@ func (s service) ViewCart(request presenterCart.CartViewRequest) (result []prese
     return
 }
 
-func (s service) DeleteProductInCart(request presenterCart.CartProductDeleteRequest) (httpStatus int, err error) {
-    err = s.repo.DeleteProductInCart(request.MemberID, request.ProductID)
+func (s service) DeleteProductInCart(ctx context.Context, request presenterCart.CartProductDeleteRequest) (httpStatus int, err error) {
+    err = s.repo.DeleteProductInCart(ctx, request.MemberID, request.ProductID, request.VariantID)
     if err == sql.ErrNoRows {
         httpStatus = http.StatusNotFound
         err = errors.New("Cart not found")
@@ -87,15 +244,22 @@ func (s service) DeleteProductInCart(request presenterCart.CartProductDeleteRequ
         httpStatus = http.StatusInternalServerError
         return
     }
+
+    // Hold might exist even when reservation is disabled later
+    err = s.repo.ReleaseReservation(ctx, request.MemberID, request.ProductID, request.VariantID)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
     return
 }
 
-func (s service) CreateTransaction(request presenterTransaction.TransactionRequest) (httpStatus int, err error) {
+func (s service) CreateTransaction(ctx context.Context, request presenterTransaction.TransactionRequest) (httpStatus int, err error) {
     var (
         transaction = modelTransaction.Transactions{}
     )
 
-    getProduct, err := s.repo.GetProduct(request.ProductID)
+    getProduct, err := s.repo.GetProduct(ctx, request.ProductID)
     if err == sql.ErrNoRows {
         httpStatus = http.StatusNotFound
         err = errors.New("Product not found")
@@ -106,38 +270,64 @@ func (s service) CreateTransaction(request presenterTransaction.TransactionReque
         return
     }
 
+    variant, httpStatus, err := s.resolveVariant(ctx, getProduct.ID, request.VariantID)
+    if err != nil {
+        return
+    }
+
     defer func() {
         if err != nil {
-            transaction.Status = "failed"
-            httpStatus = http.StatusInternalServerError
-            err = s.repo.InsertFailedTransaction(transaction)
+            reason := FailedReasonError
+            if errors.Is(err, modelProduct.ErrInsufficientStock) {
+                reason = FailedReasonStock
+            }
+            s.recordFailedTransaction(reason)
+
+            // Failed transaction is kept for audit, the client still get the cause of the failure
+            transaction.Status = modelTransaction.StatusFailed
+            if insertErr := s.repo.InsertFailedTransaction(ctx, transaction); insertErr != nil {
+                middleware.Logger(ctx).Errorln("CreateTransaction: insert failed transaction", insertErr)
+            }
         }
     }()
 
-    totalTransactionAmount := getProduct.Price * float64(request.Quantity)
-    deductedStockProduct := getProduct.Stock - request.Quantity
-    if deductedStockProduct < 0 {
-        httpStatus = http.StatusOK
-        err = errors.New("Quantity not enough")
+    // Stock held by the member is available for the member
+    heldStock, err := s.repo.SumActiveReservation(ctx, request.MemberID, variant.ID)
+    if err != nil {
+        return
+    }
+
+    totalTransactionAmount := variant.GetPrice(getProduct) * float64(request.Quantity)
+    if variant.Stock+heldStock-request.Quantity < 0 {
+        httpStatus = http.StatusConflict
+        err = modelProduct.ErrInsufficientStock
         return
     }
     copier.Copy(&transaction, &request)
+    transaction.VariantID = variant.ID
 
     transaction.Amount = totalTransactionAmount
     transaction.AmountFee = 0
-    transaction.Status = "success"
+    transaction.Status = modelTransaction.StatusPending // Paid or failed by the payment status consumer
 
-    err = s.repo.CreateTransaction(transaction, deductedStockProduct)
+    // Stock is checked again under the row lock, another order might take it in between
+    err = s.repo.CreateTransaction(ctx, transaction)
+    if errors.Is(err, modelProduct.ErrInsufficientStock) {
+        httpStatus = http.StatusConflict
+        return
+    }
     if err != nil {
         httpStatus = http.StatusInternalServerError
         return
     }
 
+    s.recordOrder(getProduct.Category, transaction.Amount)
+    s.checkLowStock(ctx, getProduct.ID)
     return
 }
 
-func (s service) Login(request presenterMember.LoginRequest) (result presenterMember.LoginResponse, httpStatus int, err error) {
-    memberData, err := s.repo.GetMemberByUsername(request.Username)
+func (s service) Login(ctx context.Context, request presenterMember.LoginRequest) (result presenterMember.LoginResponse, httpStatus int, err error) {
+    memberData, err := s.repo.GetMemberByUsername(ctx, request.Username)
     if err == sql.ErrNoRows {
         httpStatus = http.StatusNotFound
         err = errors.New("Member not found")
@@ -155,19 +345,17 @@ func (s service) Login(request presenterMember.LoginRequest) (result presenterMe
         return
     }
 
-    var (
-        crypt, _ = security.New("123")
-        sess     = security.Session{
-            UserId:   cast.ToString(memberData.ID),
-            Username: memberData.Username,
-            Name:     "-",
-            Role:     "-",
-            Iat:      0,
-            Expired:  time.Now().Add(time.Hour).Unix(),
-        }
-    )
-    token, err := sess.Encrypt(crypt)
+    sess := security.Session{
+        UserId:   cast.ToString(memberData.ID),
+        Username: memberData.Username,
+        Name:     "-",
+        Role:     "-",
+        Iat:      time.Now().Unix(),
+        Expired:  time.Now().Add(time.Hour).Unix(),
+    }
+    token, err := security.SignSession(&sess)
     if err != nil {
+        httpStatus = http.StatusInternalServerError
         return
     }
 
@@ -178,6 +366,25 @@ func (s service) Login(request presenterMember.LoginRequest) (result presenterMe
 
 }
 
+// resolveVariant get requested variant, or default variant of the product when variantId is empty
+func (s service) resolveVariant(ctx context.Context, productId, variantId int) (result modelProduct.Variant, httpStatus int, err error) {
+    if variantId == 0 {
+        result, err = s.repo.GetDefaultVariant(ctx, productId)
+    } else {
+        result, err = s.repo.GetVariant(ctx, variantId)
+    }
+    if err == sql.ErrNoRows || (err == nil && productId != 0 && result.ProductID != productId) {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product variant not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    return
+}
+
 func compareBcrypt(hashedString, plainString string) bool {
     err := bcrypt.CompareHashAndPassword([]byte(hashedString), []byte(plainString))
     if err != nil {
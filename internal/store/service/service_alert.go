@@ -0,0 +1,103 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "time"
+
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/event"
+    "store-api/pkg/middleware"
+)
+
+const lowStockPublishTimeout = 5 * time.Second
+
+// SetReorderThreshold of a product, 0 disable the low stock alert
+func (s service) SetReorderThreshold(ctx context.Context, request presenterProduct.ReorderThresholdRequest) (httpStatus int, err error) {
+    if request.ReorderThreshold < 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Reorder threshold must be greater than or equal to 0")
+        return
+    }
+
+    _, err = s.repo.GetProduct(ctx, request.ProductID)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    err = s.repo.UpdateReorderThreshold(ctx, request.ProductID, request.ReorderThreshold)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// checkLowStock alert once when product stock drop below its reorder threshold. The flag is set before publishing
+// so concurrent sales don't alert twice, it is reset when no alert can be sent so the next sale retry.
+func (s service) checkLowStock(ctx context.Context, productId int) {
+    if s.config.Publisher == nil && s.config.Flock == nil {
+        return
+    }
+
+    product, alerted, err := s.repo.MarkLowStockAlert(ctx, productId)
+    if err != nil {
+        middleware.Logger(ctx).Errorln("checkLowStock: mark alert", err)
+        return
+    }
+    if !alerted {
+        return
+    }
+
+    alert := modelProduct.LowStockAlert{
+        ProductID:        product.ID,
+        Name:             product.Name,
+        Category:         product.Category,
+        Stock:            product.Stock,
+        ReorderThreshold: product.ReorderThreshold,
+        AlertedAt:        time.Now(),
+    }
+
+    sent := false
+    if s.config.Publisher != nil {
+        publishCtx, cancel := context.WithTimeout(context.Background(), lowStockPublishTimeout)
+        err = event.Publish(publishCtx, s.config.Publisher, s.config.LowStockTopic,
+            event.New(modelProduct.LowStockEvent, modelProduct.LowStockSchemaVersion, s.config.EventSource, alert),
+            event.JSON, messaging.WithKey(strconv.Itoa(alert.ProductID)))
+        cancel()
+        if err != nil {
+            middleware.Logger(ctx).Errorln("checkLowStock: publish alert", err)
+        } else {
+            sent = true
+        }
+    }
+    if s.config.Flock != nil {
+        code := s.config.Flock.SendMsgFlock(fmt.Sprintf("Low stock :warning: %s (%s) stock %d, reorder threshold %d",
+            alert.Name, alert.Category, alert.Stock, alert.ReorderThreshold))
+        if code >= http.StatusOK && code < http.StatusMultipleChoices {
+            sent = true
+        } else {
+            middleware.Logger(ctx).Errorln("checkLowStock: send flock message, status", code)
+        }
+    }
+
+    if !sent {
+        if err = s.repo.ResetLowStockAlert(ctx, productId); err != nil {
+            middleware.Logger(ctx).Errorln("checkLowStock: reset alert", err)
+        }
+    }
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "testing"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/event"
+    "store-api/pkg/middleware"
+
+    "github.com/sirupsen/logrus"
+    "github.com/sirupsen/logrus/hooks/test"
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) MarkLowStockAlert(ctx context.Context, productId int) (modelProduct.Product, bool, error) {
+    product := r.products[productId]
+    if !product.IsLowStock() || product.LowStockAlerted {
+        return modelProduct.Product{}, false, nil
+    }
+    product.LowStockAlerted = true
+    r.products[productId] = product
+    return product, true, nil
+}
+
+func (r *stubRepository) ResetLowStockAlert(ctx context.Context, productId int) error {
+    product := r.products[productId]
+    product.LowStockAlerted = false
+    r.products[productId] = product
+    return nil
+}
+
+func (r *stubRepository) UpdateReorderThreshold(ctx context.Context, productId, threshold int) error {
+    product := r.products[productId]
+    product.ReorderThreshold = threshold
+    if product.Stock >= threshold {
+        product.LowStockAlerted = false
+    }
+    r.products[productId] = product
+    return nil
+}
+
+func newAlertService(publisher *messaging.MemoryPublisher) (*stubRepository, StoreService) {
+    repo, _ := newStockService()
+    product := repo.products[1]
+    product.Category = "fashion"
+    product.ReorderThreshold = 3
+    repo.products[1] = product
+    return repo, NewService(repo, nil, Config{Publisher: publisher})
+}
+
+func buy(svc StoreService, quantity int) error {
+    _, err := svc.CreateTransaction(context.Background(), presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, Quantity: quantity})
+    return err
+}
+
+func TestService_CreateTransaction_LowStockAlert(t *testing.T) {
+    t.Run("Alert once when crossing threshold", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        repo, svc := newAlertService(publisher)
+
+        assert.Nil(t, buy(svc, 2)) // Stock 3, not below threshold
+        assert.Len(t, publisher.Messages(""), 0)
+
+        assert.Nil(t, buy(svc, 1)) // Stock 2
+        assert.Nil(t, buy(svc, 1)) // Stock 1, already alerted
+        messages := publisher.Messages(modelProduct.LowStockTopic)
+        assert.Len(t, messages, 1)
+
+        registry := event.NewRegistry()
+        modelOutbox.RegisterEvents(registry)
+        env, err := registry.Decode(messages[0].Headers[event.HeaderContentType], messages[0].Data.([]byte))
+        assert.Nil(t, err)
+        assert.Equal(t, modelProduct.LowStockEvent, env.Type)
+        assert.Equal(t, DefaultEventSource, env.Source)
+        assert.Equal(t, "1", messages[0].Key)
+
+        alert := env.Payload.(*modelProduct.LowStockAlert)
+        assert.Equal(t, 1, alert.ProductID)
+        assert.Equal(t, "fashion", alert.Category)
+        assert.Equal(t, 2, alert.Stock)
+        assert.Equal(t, 3, alert.ReorderThreshold)
+        assert.True(t, repo.products[1].LowStockAlerted)
+    })
+
+    t.Run("Alert again after restock", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        _, svc := newAlertService(publisher)
+
+        assert.Nil(t, buy(svc, 3))
+        _, _, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 1, Type: modelProduct.MovementRestock, Quantity: 10})
+        assert.Nil(t, err)
+        assert.Nil(t, buy(svc, 10))
+        assert.Len(t, publisher.Messages(""), 2)
+    })
+
+    t.Run("Failed publish is retried on next sale", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        publisher.SetError(errors.New("broker down"))
+        repo, svc := newAlertService(publisher)
+        hook := test.NewGlobal()
+        defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+
+        ctx := middleware.WithRequestID(context.Background(), "req-1")
+        _, err := svc.CreateTransaction(ctx, presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, Quantity: 3})
+        assert.Nil(t, err, "Transaction must not fail because of the alert")
+        assert.False(t, repo.products[1].LowStockAlerted)
+        assert.Equal(t, "req-1", hook.LastEntry().Data["request_id"], "Log is joined with the request")
+
+        publisher.SetError(nil)
+        assert.Nil(t, buy(svc, 1))
+        assert.Len(t, publisher.Messages(""), 1)
+    })
+
+    t.Run("Threshold 0 disable alert", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        repo, svc := newAlertService(publisher)
+        repo.products[1] = modelProduct.Product{ID: 1, Stock: 5}
+
+        assert.Nil(t, buy(svc, 5))
+        assert.Len(t, publisher.Messages(""), 0)
+    })
+}
+
+func TestService_SetReorderThreshold(t *testing.T) {
+    repo, svc := newAlertService(messaging.NewMemoryPublisher())
+
+    httpStatus, err := svc.SetReorderThreshold(context.Background(), presenterProduct.ReorderThresholdRequest{ProductID: 1, ReorderThreshold: -1})
+    assert.NotNil(t, err)
+    assert.Equal(t, http.StatusBadRequest, httpStatus)
+
+    httpStatus, err = svc.SetReorderThreshold(context.Background(), presenterProduct.ReorderThresholdRequest{ProductID: 9, ReorderThreshold: 1})
+    assert.NotNil(t, err)
+    assert.Equal(t, http.StatusNotFound, httpStatus)
+
+    httpStatus, err = svc.SetReorderThreshold(context.Background(), presenterProduct.ReorderThresholdRequest{ProductID: 1, ReorderThreshold: 10})
+    assert.Nil(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Equal(t, 10, repo.products[1].ReorderThreshold)
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+    "time"
+
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/spreadsheet"
+)
+
+const (
+    ImportBatchSize = 200   // Rows per transaction
+    ImportMaxRow    = 10000 // Excluding header
+
+    columnName         = "name"
+    columnCategory     = "category"
+    columnPrice        = "price"
+    columnSKU          = "sku"
+    columnSize         = "size"
+    columnColour       = "colour"
+    columnVariantPrice = "variant_price"
+    columnStock        = "stock"
+    columnIsActive     = "is_active"
+)
+
+// CatalogueColumns header of import / export file, one line per variant
+var CatalogueColumns = []string{columnName, columnCategory, columnPrice, columnSKU, columnSize, columnColour,
+    columnVariantPrice, columnStock, columnIsActive}
+
+var requiredCatalogueColumns = []string{columnName, columnCategory, columnPrice, columnSKU, columnStock}
+
+// ImportProduct validate every row, then upsert valid rows in batches. Product is matched by name and category,
+// variant by sku. Invalid rows are skipped and reported, they don't stop the import.
+func (s service) ImportProduct(ctx context.Context, request presenterProduct.ImportRequest) (result presenterProduct.ImportResponse, httpStatus int, err error) {
+    result.DryRun = request.DryRun
+    result.Errors = []presenterProduct.ImportRowError{}
+
+    rows, err := spreadsheet.Read(request.File, request.Format)
+    if err != nil {
+        httpStatus = http.StatusBadRequest
+        err = fmt.Errorf("Cannot read file: %v", err)
+        return
+    }
+    if len(rows) == 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("File is empty")
+        return
+    }
+    if len(rows)-1 > ImportMaxRow {
+        httpStatus = http.StatusBadRequest
+        err = fmt.Errorf("File must not have more than %d rows", ImportMaxRow)
+        return
+    }
+
+    header := make(map[string]int)
+    for i, column := range rows[0] {
+        header[strings.ToLower(strings.TrimSpace(column))] = i
+    }
+    for _, column := range requiredCatalogueColumns {
+        if _, ok := header[column]; !ok {
+            httpStatus = http.StatusBadRequest
+            err = errors.New("Missing required column: " + column)
+            return
+        }
+    }
+
+    var (
+        valid    []modelProduct.CatalogueRow
+        lines    []int
+        skuLines = make(map[string]int)
+    )
+    for i, row := range rows[1:] {
+        line := i + 2
+        if isEmptyRow(row) {
+            continue
+        }
+        result.TotalRow++
+
+        model, rowErrors := parseCatalogueRow(header, row, line)
+        if len(rowErrors) == 0 {
+            if firstLine, ok := skuLines[model.Variant.SKU]; ok {
+                rowErrors = append(rowErrors, presenterProduct.ImportRowError{Row: line, Column: columnSKU,
+                    Message: fmt.Sprintf("Duplicate sku, already used in row %d", firstLine)})
+            } else {
+                skuLines[model.Variant.SKU] = line
+            }
+        }
+
+        if len(rowErrors) > 0 {
+            result.Errors = append(result.Errors, rowErrors...)
+            result.Failed++
+            continue
+        }
+        valid = append(valid, model)
+        lines = append(lines, line)
+    }
+
+    // Existing sku must not move to other product
+    skus := make([]string, len(valid))
+    for i, row := range valid {
+        skus[i] = row.Variant.SKU
+    }
+    existing, err := s.repo.ListCatalogueBySKU(ctx, skus)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    existingBySKU := make(map[string]modelProduct.Product, len(existing))
+    for _, row := range existing {
+        existingBySKU[row.Variant.SKU] = row.Product
+    }
+
+    importRows := valid[:0]
+    for i, row := range valid {
+        product, ok := existingBySKU[row.Variant.SKU]
+        if ok && (product.Name != row.Product.Name || product.Category != row.Product.Category) {
+            result.Errors = append(result.Errors, presenterProduct.ImportRowError{Row: lines[i], Column: columnSKU,
+                Message: fmt.Sprintf("Sku already belongs to product %s (%s)", product.Name, product.Category)})
+            result.Failed++
+            continue
+        }
+        importRows = append(importRows, row)
+    }
+
+    if request.DryRun {
+        httpStatus = http.StatusOK
+        return
+    }
+
+    for start := 0; start < len(importRows); start += ImportBatchSize {
+        end := start + ImportBatchSize
+        if end > len(importRows) {
+            end = len(importRows)
+        }
+
+        err = s.repo.UpsertCatalogue(ctx, importRows[start:end])
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            err = fmt.Errorf("Import stopped after %d rows: %v", result.Imported, err)
+            return
+        }
+        result.Imported += end - start
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// ExportProduct every variant of the catalogue, same columns as the import file
+func (s service) ExportProduct(ctx context.Context, request presenterProduct.ExportRequest) (result presenterProduct.ExportResponse, httpStatus int, err error) {
+    format, err := spreadsheet.Format(request.Format)
+    if err != nil {
+        httpStatus = http.StatusBadRequest
+        return
+    }
+
+    catalogue, err := s.repo.ListCatalogue(ctx)
+    if err != nil && err != sql.ErrNoRows {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    writer, err := spreadsheet.NewWriter(&result.Body, format)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    err = writer.Write(CatalogueColumns)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    for _, row := range catalogue {
+        variantPrice := ""
+        if row.Variant.PriceOverride.Valid {
+            variantPrice = formatFloat(row.Variant.PriceOverride.Float64)
+        }
+
+        err = writer.Write([]string{
+            row.Product.Name,
+            row.Product.Category,
+            formatFloat(row.Product.Price),
+            row.Variant.SKU,
+            row.Variant.Size,
+            row.Variant.Colour,
+            variantPrice,
+            strconv.Itoa(row.Variant.Stock),
+            strconv.FormatBool(row.Variant.IsActive),
+        })
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+    }
+
+    err = writer.Flush()
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.FileName = fmt.Sprintf("product_%s.%s", time.Now().Format("20060102150405"), format)
+    httpStatus = http.StatusOK
+    return
+}
+
+func parseCatalogueRow(header map[string]int, row []string, line int) (model modelProduct.CatalogueRow, rowErrors []presenterProduct.ImportRowError) {
+    value := func(column string) string {
+        i, ok := header[column]
+        if !ok || i >= len(row) {
+            return ""
+        }
+        return strings.TrimSpace(row[i])
+    }
+    addError := func(column, message string) {
+        rowErrors = append(rowErrors, presenterProduct.ImportRowError{Row: line, Column: column, Message: message})
+    }
+    required := func(column string, maxLength int) string {
+        text := value(column)
+        if text == "" {
+            addError(column, "Required")
+        } else if len(text) > maxLength {
+            addError(column, fmt.Sprintf("Must not be longer than %d characters", maxLength))
+        }
+        return text
+    }
+    optional := func(column string, maxLength int) string {
+        text := value(column)
+        if len(text) > maxLength {
+            addError(column, fmt.Sprintf("Must not be longer than %d characters", maxLength))
+        }
+        return text
+    }
+    price := func(column string) (float64, bool) {
+        text := value(column)
+        if text == "" {
+            return 0, false
+        }
+        number, err := strconv.ParseFloat(text, 64)
+        if err != nil || number < 0 {
+            addError(column, "Must be a number greater than or equal to 0")
+        }
+        return number, true
+    }
+
+    model.Product.Name = required(columnName, 100)
+    model.Product.Category = required(columnCategory, 100)
+    if value(columnPrice) == "" {
+        addError(columnPrice, "Required")
+    } else {
+        model.Product.Price, _ = price(columnPrice)
+    }
+
+    model.Variant.SKU = required(columnSKU, 100)
+    model.Variant.Size = optional(columnSize, 50)
+    model.Variant.Colour = optional(columnColour, 50)
+    if number, ok := price(columnVariantPrice); ok {
+        model.Variant.PriceOverride = sql.NullFloat64{Float64: number, Valid: true}
+    }
+
+    stock, err := strconv.Atoi(value(columnStock))
+    if err != nil || stock < 0 {
+        addError(columnStock, "Must be an integer greater than or equal to 0")
+    }
+    model.Variant.Stock = stock
+
+    model.Variant.IsActive = true
+    if text := value(columnIsActive); text != "" {
+        isActive, err := strconv.ParseBool(strings.ToLower(text))
+        if err != nil {
+            addError(columnIsActive, "Must be true or false")
+        }
+        model.Variant.IsActive = isActive
+    }
+    return
+}
+
+func isEmptyRow(row []string) bool {
+    for _, value := range row {
+        if strings.TrimSpace(value) != "" {
+            return false
+        }
+    }
+    return true
+}
+
+func formatFloat(number float64) string {
+    return strconv.FormatFloat(number, 'f', -1, 64)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "database/sql"
+    "fmt"
+    "net/http"
+    "strings"
+    "testing"
+
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/spreadsheet"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) ListCatalogue(ctx context.Context) ([]modelProduct.CatalogueRow, error) {
+    return r.catalogue, nil
+}
+
+func (r *stubRepository) ListCatalogueBySKU(ctx context.Context, skus []string) (result []modelProduct.CatalogueRow, err error) {
+    for _, row := range r.catalogue {
+        for _, sku := range skus {
+            if row.Variant.SKU == sku {
+                result = append(result, row)
+            }
+        }
+    }
+    return
+}
+
+func (r *stubRepository) UpsertCatalogue(ctx context.Context, rows []modelProduct.CatalogueRow) error {
+    r.batches = append(r.batches, append([]modelProduct.CatalogueRow{}, rows...))
+    return nil
+}
+
+func newCatalogueService() (*stubRepository, StoreService) {
+    repo := &stubRepository{catalogue: []modelProduct.CatalogueRow{{
+        Product: modelProduct.Product{ID: 1, Name: "Shirt", Category: "fashion", Price: 100},
+        Variant: modelProduct.Variant{ID: 1, ProductID: 1, SKU: "SHIRT-M", Size: "M", Stock: 5, IsActive: true,
+            PriceOverride: sql.NullFloat64{Float64: 120, Valid: true}},
+    }}}
+    return repo, NewService(repo, nil, Config{})
+}
+
+func TestService_ImportProduct(t *testing.T) {
+    t.Run("Import valid rows and report invalid rows", func(t *testing.T) {
+        repo, svc := newCatalogueService()
+
+        file := strings.Join([]string{
+            "Name,Category,Price,SKU,Size,Stock,is_active",
+            "Shirt,fashion,110,SHIRT-S,S,7,",
+            "Shirt,fashion,110,SHIRT-L,L,3,false",
+            ",fashion,abc,SHIRT-XL,XL,-1,",
+            "",
+            "Cap,fashion,25,SHIRT-L,,1,",
+            "Cap,fashion,25,SHIRT-M,,1,",
+        }, "\n")
+
+        result, httpStatus, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: "csv", File: strings.NewReader(file)})
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Equal(t, 5, result.TotalRow)
+        assert.Equal(t, 2, result.Imported)
+        assert.Equal(t, 3, result.Failed)
+
+        assert.Equal(t, []presenterProduct.ImportRowError{
+            {Row: 4, Column: "name", Message: "Required"},
+            {Row: 4, Column: "price", Message: "Must be a number greater than or equal to 0"},
+            {Row: 4, Column: "stock", Message: "Must be an integer greater than or equal to 0"},
+            {Row: 6, Column: "sku", Message: "Duplicate sku, already used in row 3"},
+            {Row: 7, Column: "sku", Message: "Sku already belongs to product Shirt (fashion)"},
+        }, result.Errors)
+
+        assert.Len(t, repo.batches, 1)
+        assert.Equal(t, "SHIRT-S", repo.batches[0][0].Variant.SKU)
+        assert.Equal(t, 7, repo.batches[0][0].Variant.Stock)
+        assert.False(t, repo.batches[0][0].Variant.PriceOverride.Valid)
+        assert.True(t, repo.batches[0][0].Variant.IsActive)
+        assert.False(t, repo.batches[0][1].Variant.IsActive)
+    })
+
+    t.Run("Dry run save nothing", func(t *testing.T) {
+        repo, svc := newCatalogueService()
+
+        file := "name,category,price,sku,stock\nShirt,fashion,110,SHIRT-S,1\n"
+        result, _, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: "csv", DryRun: true, File: strings.NewReader(file)})
+        assert.Nil(t, err)
+        assert.Equal(t, 0, result.Imported)
+        assert.Len(t, repo.batches, 0)
+    })
+
+    t.Run("Upsert in batches", func(t *testing.T) {
+        repo, svc := newCatalogueService()
+
+        var buf bytes.Buffer
+        buf.WriteString("name,category,price,sku,stock\n")
+        for i := 0; i < ImportBatchSize+1; i++ {
+            buf.WriteString(fmt.Sprintf("Sock,fashion,10,SOCK-%d,1\n", i))
+        }
+
+        result, _, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: "csv", File: &buf})
+        assert.Nil(t, err)
+        assert.Equal(t, ImportBatchSize+1, result.Imported)
+        assert.Len(t, repo.batches, 2)
+        assert.Len(t, repo.batches[1], 1)
+    })
+
+    t.Run("Missing required column", func(t *testing.T) {
+        _, svc := newCatalogueService()
+
+        _, httpStatus, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: "csv", File: strings.NewReader("name,price\n")})
+        assert.EqualError(t, err, "Missing required column: category")
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+
+    t.Run("Unsupported format", func(t *testing.T) {
+        _, svc := newCatalogueService()
+
+        _, httpStatus, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: "pdf", File: strings.NewReader("")})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+}
+
+func TestService_ExportProduct(t *testing.T) {
+    _, svc := newCatalogueService()
+
+    for _, format := range []string{spreadsheet.CSV, spreadsheet.XLSX} {
+        t.Run(format, func(t *testing.T) {
+            result, httpStatus, err := svc.ExportProduct(context.Background(), presenterProduct.ExportRequest{Format: format})
+            assert.Nil(t, err)
+            assert.Equal(t, http.StatusOK, httpStatus)
+            assert.True(t, strings.HasSuffix(result.FileName, "."+format))
+
+            rows, err := spreadsheet.Read(&result.Body, format)
+            assert.Nil(t, err)
+            assert.Equal(t, [][]string{
+                CatalogueColumns,
+                {"Shirt", "fashion", "100", "SHIRT-M", "M", "", "120", "5", "true"},
+            }, rows)
+        })
+    }
+
+    t.Run("Exported file can be imported again", func(t *testing.T) {
+        repo, svc := newCatalogueService()
+
+        result, _, err := svc.ExportProduct(context.Background(), presenterProduct.ExportRequest{Format: spreadsheet.XLSX})
+        assert.Nil(t, err)
+
+        imported, _, err := svc.ImportProduct(context.Background(), presenterProduct.ImportRequest{Format: spreadsheet.XLSX, File: &result.Body})
+        assert.Nil(t, err)
+        assert.Equal(t, 1, imported.Imported)
+        assert.Equal(t, repo.catalogue[0].Variant.PriceOverride, repo.batches[0][0].Variant.PriceOverride)
+    })
+
+    t.Run("Unsupported format", func(t *testing.T) {
+        _, httpStatus, err := svc.ExportProduct(context.Background(), presenterProduct.ExportRequest{Format: "pdf"})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/pkg/data/filedata"
+    "store-api/pkg/imageproc"
+    "store-api/pkg/middleware"
+
+    "github.com/sirupsen/logrus"
+)
+
+func (s service) UploadProductImage(ctx context.Context, request presenterProduct.ImageUploadRequest, file *filedata.UploadFile) (result presenterProduct.ImageResponse, httpStatus int, err error) {
+    if s.storage == nil {
+        httpStatus = http.StatusServiceUnavailable
+        err = errors.New("Media storage is not configured")
+        return
+    }
+
+    if file.Size > filedata.MAX_FILE_SIZE {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Image size must be less than " + filedata.GetFileSizeText(filedata.MAX_FILE_SIZE))
+        return
+    }
+
+    _, err = s.repo.GetProduct(ctx, request.ProductID)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    // Original is never stored, only the renditions without metadata
+    _, err = s.imageProcessor.ProcessAndStore(modelProduct.ImageFolder, file)
+    if errors.Is(err, imageproc.ErrUnsupportedImage) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Image must be jpg or png")
+        return
+    }
+    if errors.Is(err, imageproc.ErrImageTooLarge) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Image dimension is too large")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    imageId, err := s.repo.CreateImage(ctx, modelProduct.Image{ProductID: request.ProductID, S3Name: file.GetS3Name()})
+    if err != nil {
+        // Do not leave orphan object in S3
+        if errDelete := s.deleteImageRenditions(file.GetS3Name()); errDelete != nil {
+            middleware.Logger(ctx).Errorln("UploadProductImage: delete orphan image", errDelete)
+        }
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    image, err := s.repo.GetImage(ctx, imageId)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result = s.toImageResponse(image)
+    httpStatus = http.StatusCreated
+    return
+}
+
+func (s service) ReorderProductImage(ctx context.Context, request presenterProduct.ImageReorderRequest) (httpStatus int, err error) {
+    images, err := s.repo.ListImage(ctx, []int{request.ProductID})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    // New order must contain every image of the product exactly once
+    current := make(map[int]bool, len(images))
+    for _, image := range images {
+        current[image.ID] = true
+    }
+    if len(request.ImageIDs) != len(current) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Image ids must contain all image of the product")
+        return
+    }
+    for _, imageId := range request.ImageIDs {
+        if !current[imageId] {
+            httpStatus = http.StatusBadRequest
+            err = errors.New("Image ids must contain all image of the product")
+            return
+        }
+        delete(current, imageId)
+    }
+
+    err = s.repo.UpdateImagePosition(ctx, request.ProductID, request.ImageIDs)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    return
+}
+
+func (s service) DeleteProductImage(ctx context.Context, request presenterProduct.ImageDeleteRequest) (httpStatus int, err error) {
+    image, err := s.repo.GetImage(ctx, request.ImageID)
+    if err == sql.ErrNoRows || (err == nil && image.ProductID != request.ProductID) {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Image not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    if s.storage != nil {
+        err = s.deleteImageRenditions(image.S3Name)
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+    }
+
+    err = s.repo.DeleteImage(ctx, image.ID)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+    return
+}
+
+func (s service) toImageResponse(image modelProduct.Image) presenterProduct.ImageResponse {
+    result := presenterProduct.ImageResponse{ID: image.ID, Position: image.Position}
+    if s.storage == nil {
+        return result
+    }
+
+    urls := make(map[string]string, len(imageproc.DefaultRenditions))
+    for _, rendition := range imageproc.DefaultRenditions {
+        url, err := s.storage.Get(renditionFolder(rendition), image.S3Name)
+        if err != nil {
+            logrus.Errorln("Get product image url", err)
+            continue
+        }
+        urls[rendition.Name] = url
+    }
+    result.URL = urls["large"]
+    result.Thumbnail = urls["thumbnail"]
+    result.Medium = urls["medium"]
+    return result
+}
+
+func (s service) deleteImageRenditions(s3Name string) error {
+    for _, rendition := range imageproc.DefaultRenditions {
+        if err := s.storage.Delete(renditionFolder(rendition), s3Name); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// renditionFolder S3 folder of product image rendition, e.g. product/thumbnail
+func renditionFolder(rendition imageproc.Rendition) string {
+    return modelProduct.ImageFolder + "/" + rendition.Name
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+    "bytes"
+    "context"
+    "database/sql"
+    "image"
+    "image/png"
+    "net/http"
+    "testing"
+
+    "store-api/internal/base/service/notification"
+    modelCart "store-api/internal/store/domain/cart"
+    modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterProduct "store-api/internal/store/presenter/product"
+    "store-api/internal/store/repository"
+    "store-api/pkg/awsutil"
+    "store-api/pkg/data/filedata"
+
+    "github.com/stretchr/testify/assert"
+)
+
+// stubRepository only implement method used by the test, other method panic
+type stubRepository struct {
+    repository.StoreRepository
+
+    products  map[int]modelProduct.Product
+    images    []modelProduct.Image
+    catalogue []modelProduct.CatalogueRow
+    batches   [][]modelProduct.CatalogueRow
+    variants  map[int]modelProduct.Variant
+    movements []modelProduct.StockMovement
+
+    carts        []modelCart.Cart
+    reservations []modelCart.Reservation
+    transactions []modelTransaction.Transactions
+    outbox       []modelOutbox.Event
+
+    members          map[int]modelMember.Member
+    devices          []modelMember.Device
+    optOuts          map[int]bool
+    wishlists        []modelCart.Wishlist
+    notificationLogs []notification.Log
+}
+
+func (r *stubRepository) GetProduct(ctx context.Context, productId int) (modelProduct.Product, error) {
+    product, ok := r.products[productId]
+    if !ok {
+        return product, sql.ErrNoRows
+    }
+    return product, nil
+}
+
+func (r *stubRepository) ListImage(ctx context.Context, productIds []int) (result []modelProduct.Image, err error) {
+    for _, image := range r.images {
+        for _, productId := range productIds {
+            if image.ProductID == productId {
+                result = append(result, image)
+            }
+        }
+    }
+    return
+}
+
+func (r *stubRepository) GetImage(ctx context.Context, imageId int) (modelProduct.Image, error) {
+    for _, image := range r.images {
+        if image.ID == imageId {
+            return image, nil
+        }
+    }
+    return modelProduct.Image{}, sql.ErrNoRows
+}
+
+func (r *stubRepository) CreateImage(ctx context.Context, model modelProduct.Image) (int, error) {
+    model.ID = len(r.images) + 1
+    model.Position = len(r.images)
+    r.images = append(r.images, model)
+    return model.ID, nil
+}
+
+func (r *stubRepository) UpdateImagePosition(ctx context.Context, productId int, imageIds []int) error {
+    for position, imageId := range imageIds {
+        for i := range r.images {
+            if r.images[i].ID == imageId {
+                r.images[i].Position = position
+            }
+        }
+    }
+    return nil
+}
+
+func (r *stubRepository) DeleteImage(ctx context.Context, imageId int) error {
+    for i, image := range r.images {
+        if image.ID == imageId {
+            r.images = append(r.images[:i], r.images[i+1:]...)
+            break
+        }
+    }
+    return nil
+}
+
+type memoryFile struct {
+    *bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+func newUploadFile(name, ext, fileType string, content []byte) *filedata.UploadFile {
+    return &filedata.UploadFile{
+        Name:   name,
+        S3Name: "20220101000000_" + name,
+        Size:   int64(len(content)),
+        Ext:    ext,
+        Type:   fileType,
+        File:   memoryFile{bytes.NewReader(content)},
+    }
+}
+
+func newImageService(t *testing.T) (*stubRepository, StoreService) {
+    storage, err := awsutil.NewAwsMockService()
+    assert.Nil(t, err)
+
+    repo := &stubRepository{products: map[int]modelProduct.Product{1: {ID: 1, Name: "Shirt"}}}
+    return repo, NewService(repo, storage, Config{})
+}
+
+func newPNG(t *testing.T, width, height int) []byte {
+    var buf bytes.Buffer
+    err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+    assert.Nil(t, err)
+    return buf.Bytes()
+}
+
+func TestService_UploadProductImage(t *testing.T) {
+    content := newPNG(t, 40, 20)
+
+    t.Run("Upload image success", func(t *testing.T) {
+        repo, svc := newImageService(t)
+
+        file := newUploadFile("front.png", "png", "image/png", content)
+        result, httpStatus, err := svc.UploadProductImage(context.Background(), presenterProduct.ImageUploadRequest{ProductID: 1}, file)
+
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusCreated, httpStatus)
+        assert.Equal(t, 1, result.ID)
+        assert.Contains(t, result.URL, "product/large/"+file.GetS3Name())
+        assert.Contains(t, result.Thumbnail, "product/thumbnail/"+file.GetS3Name())
+        assert.Contains(t, result.Medium, "product/medium/"+file.GetS3Name())
+        assert.Len(t, repo.images, 1)
+    })
+
+    t.Run("Reject fake image by magic bytes", func(t *testing.T) {
+        repo, svc := newImageService(t)
+
+        file := newUploadFile("front.png", "png", "image/png", []byte("not really a png"))
+        _, httpStatus, err := svc.UploadProductImage(context.Background(), presenterProduct.ImageUploadRequest{ProductID: 1}, file)
+
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+        assert.Len(t, repo.images, 0)
+    })
+
+    t.Run("Reject non image file", func(t *testing.T) {
+        repo, svc := newImageService(t)
+
+        file := newUploadFile("price.csv", "csv", "text/plain; charset=utf-8", []byte("a,b"))
+        _, httpStatus, err := svc.UploadProductImage(context.Background(), presenterProduct.ImageUploadRequest{ProductID: 1}, file)
+
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+        assert.Len(t, repo.images, 0)
+    })
+
+    t.Run("Product not found", func(t *testing.T) {
+        _, svc := newImageService(t)
+
+        file := newUploadFile("front.png", "png", "image/png", content)
+        _, httpStatus, err := svc.UploadProductImage(context.Background(), presenterProduct.ImageUploadRequest{ProductID: 2}, file)
+
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+    })
+}
+
+func TestService_ReorderProductImage(t *testing.T) {
+    repo, svc := newImageService(t)
+    repo.images = []modelProduct.Image{
+        {ID: 1, ProductID: 1, Position: 0},
+        {ID: 2, ProductID: 1, Position: 1},
+    }
+
+    t.Run("Reject incomplete image ids", func(t *testing.T) {
+        httpStatus, err := svc.ReorderProductImage(context.Background(), presenterProduct.ImageReorderRequest{ProductID: 1, ImageIDs: []int{2}})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+
+    t.Run("Reject duplicated image ids", func(t *testing.T) {
+        httpStatus, err := svc.ReorderProductImage(context.Background(), presenterProduct.ImageReorderRequest{ProductID: 1, ImageIDs: []int{2, 2}})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+
+    t.Run("Reorder success", func(t *testing.T) {
+        _, err := svc.ReorderProductImage(context.Background(), presenterProduct.ImageReorderRequest{ProductID: 1, ImageIDs: []int{2, 1}})
+        assert.Nil(t, err)
+        assert.Equal(t, 1, repo.images[0].Position)
+        assert.Equal(t, 0, repo.images[1].Position)
+    })
+}
+
+func TestService_DeleteProductImage(t *testing.T) {
+    repo, svc := newImageService(t)
+    repo.images = []modelProduct.Image{{ID: 1, ProductID: 1, S3Name: "front.png"}}
+
+    t.Run("Image of other product", func(t *testing.T) {
+        httpStatus, err := svc.DeleteProductImage(context.Background(), presenterProduct.ImageDeleteRequest{ProductID: 2, ImageID: 1})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+    })
+
+    t.Run("Delete success", func(t *testing.T) {
+        _, err := svc.DeleteProductImage(context.Background(), presenterProduct.ImageDeleteRequest{ProductID: 1, ImageID: 1})
+        assert.Nil(t, err)
+        assert.Len(t, repo.images, 0)
+    })
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "net/http"
+    "path/filepath"
+    "strings"
+    "time"
+
+    presenterMedia "store-api/internal/store/presenter/media"
+    "store-api/pkg/awsutil"
+    "store-api/pkg/data/filedata"
+    "store-api/pkg/errs"
+)
+
+// CreateUploadURL presigned url to upload file directly to the member folder
+func (s service) CreateUploadURL(request presenterMedia.UploadURLRequest) (result presenterMedia.UploadURLResponse, httpStatus int, err error) {
+    if s.storage == nil {
+        httpStatus = http.StatusServiceUnavailable
+        err = errors.New("Media storage is not configured")
+        return
+    }
+
+    ext := strings.ToLower(strings.ReplaceAll(filepath.Ext(request.FileName), ".", ""))
+    contentType, ok := filedata.ContentTypeByExt[ext]
+    if !ok {
+        httpStatus = http.StatusBadRequest
+        err = errs.ErrFileNotSupported
+        return
+    }
+    if request.Size <= 0 || request.Size > filedata.MAX_FILE_SIZE {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("File size must be less than " + filedata.GetFileSizeText(filedata.MAX_FILE_SIZE))
+        return
+    }
+
+    s3Name, err := filedata.NewS3Name(request.FileName)
+    if err != nil {
+        httpStatus = http.StatusBadRequest
+        return
+    }
+
+    folder := memberFolder(request.MemberID)
+    url, headers, err := s.storage.PresignPut(folder, s3Name,
+        awsutil.PresignOptions{ContentType: contentType, ContentLength: request.Size})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.Key = folder + "/" + s3Name
+    result.URL = url
+    result.Method = http.MethodPut
+    result.Headers = make(map[string]string)
+    for key := range headers {
+        result.Headers[key] = headers.Get(key)
+    }
+    result.ExpiredAt = time.Now().Add(s.storage.PresignExpiry())
+    httpStatus = http.StatusOK
+    return
+}
+
+// CreateDownloadURL presigned url to download private file, only for file in the member folder
+func (s service) CreateDownloadURL(request presenterMedia.DownloadURLRequest) (result presenterMedia.DownloadURLResponse, httpStatus int, err error) {
+    if s.storage == nil {
+        httpStatus = http.StatusServiceUnavailable
+        err = errors.New("Media storage is not configured")
+        return
+    }
+
+    folder := memberFolder(request.MemberID)
+    filename := strings.TrimPrefix(request.Key, folder+"/")
+    if filename == request.Key || filename == "" || strings.Contains(filename, "/") || strings.Contains(filename, "..") {
+        httpStatus = http.StatusForbidden
+        err = errors.New(errs.ERROR_403)
+        return
+    }
+
+    url, err := s.storage.PresignGet(folder, filename, awsutil.PresignOptions{})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.URL = url
+    result.ExpiredAt = time.Now().Add(s.storage.PresignExpiry())
+    httpStatus = http.StatusOK
+    return
+}
+
+func memberFolder(memberId int) string {
+    return fmt.Sprintf("member/%d", memberId)
+}
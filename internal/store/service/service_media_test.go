@@ -0,0 +1,59 @@
+package service
+
+import (
+    "net/http"
+    "strings"
+    "testing"
+
+    presenterMedia "store-api/internal/store/presenter/media"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestService_CreateUploadURL(t *testing.T) {
+    _, svc := newImageService(t)
+
+    t.Run("Upload url in member folder", func(t *testing.T) {
+        result, httpStatus, err := svc.CreateUploadURL(presenterMedia.UploadURLRequest{MemberID: 7, FileName: "KTP.jpg", Size: 2048})
+
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.True(t, strings.HasPrefix(result.Key, "member/7/"))
+        assert.True(t, strings.HasSuffix(result.Key, "_ktp.jpg"))
+        assert.Equal(t, http.MethodPut, result.Method)
+        assert.Equal(t, "image/jpeg", result.Headers["Content-Type"])
+    })
+
+    t.Run("Reject not allowed file", func(t *testing.T) {
+        _, httpStatus, err := svc.CreateUploadURL(presenterMedia.UploadURLRequest{MemberID: 7, FileName: "run.sh", Size: 10})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+
+    t.Run("Reject empty or too large file", func(t *testing.T) {
+        _, httpStatus, _ := svc.CreateUploadURL(presenterMedia.UploadURLRequest{MemberID: 7, FileName: "a.pdf", Size: 0})
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+
+        _, httpStatus, _ = svc.CreateUploadURL(presenterMedia.UploadURLRequest{MemberID: 7, FileName: "a.pdf", Size: 11 << 20})
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+    })
+}
+
+func TestService_CreateDownloadURL(t *testing.T) {
+    _, svc := newImageService(t)
+
+    t.Run("Download own file", func(t *testing.T) {
+        result, httpStatus, err := svc.CreateDownloadURL(presenterMedia.DownloadURLRequest{MemberID: 7, Key: "member/7/a.pdf"})
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Contains(t, result.URL, "/member/7/a.pdf")
+    })
+
+    t.Run("Forbid file of other member", func(t *testing.T) {
+        for _, key := range []string{"member/8/a.pdf", "member/7/../8/a.pdf", "member/7/", "product/a.png", "member/70/a.pdf"} {
+            _, httpStatus, err := svc.CreateDownloadURL(presenterMedia.DownloadURLRequest{MemberID: 7, Key: key})
+            assert.NotNil(t, err, key)
+            assert.Equal(t, http.StatusForbidden, httpStatus, key)
+        }
+    })
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+    "math"
+
+    "github.com/sirupsen/logrus"
+)
+
+// Business metric name, backend prefix it by the namespace
+const (
+    MetricOrderCreated      = "order.created"
+    MetricOrderRevenue      = "order.revenue"
+    MetricOrderAmount       = "order.amount"
+    MetricTransactionFailed = "transaction.failed"
+)
+
+// Reason tag of MetricTransactionFailed
+const (
+    FailedReasonStock   = "insufficient_stock"
+    FailedReasonError   = "error"
+    FailedReasonPayment = "payment"
+)
+
+// recordOrder count the order and its revenue, amount is rounded for the counter
+func (s service) recordOrder(category string, amount float64) {
+    if s.config.Metric == nil {
+        return
+    }
+
+    tags := []string{"category:" + category}
+    if err := s.config.Metric.Increment(MetricOrderCreated, tags, 1); err != nil {
+        logrus.Debugln("recordOrder: increment", err)
+    }
+    if err := s.config.Metric.Count(MetricOrderRevenue, int64(math.Round(amount)), tags, 1); err != nil {
+        logrus.Debugln("recordOrder: revenue", err)
+    }
+    if err := s.config.Metric.Histogram(MetricOrderAmount, amount, tags, 1); err != nil {
+        logrus.Debugln("recordOrder: amount", err)
+    }
+}
+
+func (s service) recordFailedTransaction(reason string) {
+    if s.config.Metric == nil {
+        return
+    }
+    if err := s.config.Metric.Increment(MetricTransactionFailed, []string{"reason:" + reason}, 1); err != nil {
+        logrus.Debugln("recordFailedTransaction", err)
+    }
+}
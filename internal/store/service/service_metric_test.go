@@ -0,0 +1,52 @@
+package service
+
+import (
+    "context"
+    "testing"
+
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+    "store-api/pkg/metric"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) InsertFailedTransaction(ctx context.Context, model modelTransaction.Transactions) error {
+    r.transactions = append(r.transactions, model)
+    return nil
+}
+
+func TestService_Metric(t *testing.T) {
+    monitoring := metric.NewMemoryMonitoring()
+    repo, _ := newStockService()
+    repo.products[1] = modelProduct.Product{ID: 1, Name: "Shirt", Category: "fashion", Price: 1000, Stock: 5}
+    repo.transactions = []modelTransaction.Transactions{{ID: 1, TrxCode: "TRX-1", Status: modelTransaction.StatusPending}}
+    svc := NewService(repo, nil, Config{Metric: monitoring})
+
+    assert.Nil(t, buy(svc, 2))
+    _ = buy(svc, 10) // Not enough quantity, saved as failed transaction
+    _, err := svc.UpdateTransactionStatus(context.Background(), presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: modelTransaction.StatusFailed})
+    assert.Nil(t, err)
+
+    assert.Equal(t, 1.0, monitoring.Sum(MetricOrderCreated))
+    assert.Equal(t, 2000.0, monitoring.Sum(MetricOrderRevenue))
+    assert.Equal(t, []string{"category:fashion"}, monitoring.Metrics(MetricOrderAmount)[0].Tags)
+
+    failed := monitoring.Metrics(MetricTransactionFailed)
+    assert.Len(t, failed, 2)
+    assert.Equal(t, []string{"reason:" + FailedReasonStock}, failed[0].Tags)
+    assert.Equal(t, []string{"reason:" + FailedReasonPayment}, failed[1].Tags)
+}
+
+func TestService_Metric_OversellUnderLock(t *testing.T) {
+    monitoring := metric.NewMemoryMonitoring()
+    repo, _ := newStockService()
+    svc := NewService(oversellRepository{repo}, nil, Config{Metric: monitoring})
+
+    assert.NotNil(t, buy(svc, 2))
+
+    failed := monitoring.Metrics(MetricTransactionFailed)
+    assert.Len(t, failed, 1)
+    assert.Equal(t, []string{"reason:" + FailedReasonStock}, failed[0].Tags, "Stock taken by the other order under the lock")
+}
@@ -0,0 +1,227 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "store-api/internal/base/service/firebase"
+    "store-api/internal/base/service/notification"
+    modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    presenterMember "store-api/internal/store/presenter/member"
+
+    "github.com/sirupsen/logrus"
+)
+
+// MemberTemplates event sent to the member per channel, other event is not sent
+var MemberTemplates = NotificationTemplates()
+
+// NotificationTemplates of the event sent to the member, executed with the event payload
+func NotificationTemplates() *notification.Templates {
+    templates := notification.NewTemplates()
+    for _, channel := range []string{notification.ChannelPush, notification.ChannelInApp, notification.ChannelEmail} {
+        templates.MustRegister(modelOutbox.EventOrderCreated, channel, "Order received",
+            `Order {{.TrxCode}} is received, total {{printf "%.0f" .Amount}}.`)
+        templates.MustRegister(modelOutbox.EventOrderPaid, channel, "Payment success",
+            "Payment of order {{.TrxCode}} is received, we are preparing your order.")
+        templates.MustRegister(modelOutbox.EventBackInStock, channel, "Back in stock",
+            "{{.Name}} from your wishlist is back in stock, get it before it runs out.")
+    }
+    return templates
+}
+
+func (s service) RegisterDevice(ctx context.Context, request presenterMember.DeviceRequest) (httpStatus int, err error) {
+    request.Token = strings.TrimSpace(request.Token)
+    if request.Token == "" || len(request.Token) > 255 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Token is required and must not be longer than 255 characters")
+        return
+    }
+    if !modelMember.IsValidPlatform(request.Platform) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Platform must be android, ios or web")
+        return
+    }
+
+    err = s.repo.UpsertDevice(ctx, modelMember.Device{MemberID: request.MemberID, Token: request.Token, Platform: request.Platform})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+func (s service) UnregisterDevice(ctx context.Context, request presenterMember.DeviceRequest) (httpStatus int, err error) {
+    if strings.TrimSpace(request.Token) == "" {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Token is required")
+        return
+    }
+
+    err = s.repo.DeleteDevice(ctx, request.MemberID, strings.TrimSpace(request.Token))
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+func (s service) SetNotificationOptOut(ctx context.Context, request presenterMember.NotificationOptOutRequest) (httpStatus int, err error) {
+    if request.Channel == "" {
+        request.Channel = modelMember.ChannelPush
+    }
+    if !modelMember.IsValidChannel(request.Channel) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Unknown notification channel")
+        return
+    }
+    if !modelMember.IsValidCategory(request.Category) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Unknown notification category")
+        return
+    }
+
+    err = s.repo.SetNotificationOptOut(ctx, modelMember.NotificationOptOut{MemberID: request.MemberID, Channel: request.Channel,
+        Category: request.Category}, request.OptOut)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// SendOrderNotification push order event to every device of the member and queue the in-app notification, run by
+// the order consumer. Token rejected by FCM is deleted. Error is returned only when no push is sent, so retry doesn't
+// push twice, in-app and email notification is queued once the push is done.
+func (s service) SendOrderNotification(ctx context.Context, eventType string, order modelOutbox.OrderPayload) (sent int, httpStatus int, err error) {
+    if s.config.Push == nil && s.config.Notifier == nil {
+        httpStatus = http.StatusInternalServerError
+        err = errors.New("Notification is not configured")
+        return
+    }
+
+    httpStatus = http.StatusOK
+    if s.config.Push != nil {
+        sent, err = s.sendPush(ctx, order.MemberID, modelMember.CategoryOrder, eventType, order,
+            map[string]string{"trx_code": order.TrxCode})
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+    }
+
+    if s.config.Notifier != nil {
+        s.notifyMember(ctx, order.MemberID, modelMember.CategoryOrder, eventType, order)
+    }
+    return
+}
+
+// sendPush render the template of the event and push it to every device of the member, nothing is sent when the
+// member opted out of the category. Error is returned only when no push is sent.
+func (s service) sendPush(ctx context.Context, memberId int, category, eventType string, payload interface{}, data map[string]string) (sent int, err error) {
+    title, body, err := MemberTemplates.Render(eventType, notification.ChannelPush, payload)
+    if errors.Is(err, notification.ErrNoTemplate) {
+        err = nil
+        return
+    }
+    if err != nil {
+        return
+    }
+
+    optOut, err := s.repo.IsNotificationOptOut(ctx, memberId, modelMember.ChannelPush, category)
+    if err != nil || optOut {
+        return
+    }
+
+    devices, err := s.repo.ListDevice(ctx, memberId)
+    if err != nil {
+        return
+    }
+
+    pushData := map[string]string{"event_type": eventType}
+    for k, v := range data {
+        pushData[k] = v
+    }
+
+    var (
+        invalidTokens []string
+        sendErr       error
+    )
+    for _, device := range devices {
+        // Sent through the push channel of the dispatcher, the send is retried and written to notification_log
+        errSend := s.config.Push.Send(ctx, notification.Message{
+            Channel:   notification.ChannelPush,
+            EventType: eventType,
+            Recipient: device.Token,
+            Subject:   title,
+            Body:      body,
+            Data:      pushData,
+        })
+
+        switch {
+        case errSend == nil:
+            sent++
+        case errors.Is(errSend, firebase.ErrInvalidToken):
+            invalidTokens = append(invalidTokens, device.Token)
+        default:
+            sendErr = errSend
+            logrus.Errorln("sendPush:", eventType, memberId, errSend)
+        }
+    }
+
+    if err = s.repo.DeleteDeviceToken(ctx, invalidTokens); err != nil {
+        logrus.Errorln("sendPush: delete invalid token", err)
+        err = nil
+    }
+    if sent == 0 && sendErr != nil {
+        err = fmt.Errorf("Cannot send push notification: %v", sendErr)
+    }
+    return
+}
+
+// notifyMember queue the in-app and email notification, error is only logged, notification is not worth retrying the
+// whole event
+func (s service) notifyMember(ctx context.Context, memberId int, category, eventType string, payload interface{}) {
+    s.notify(ctx, memberId, modelMember.ChannelInApp, category, eventType, payload)
+    if s.config.Email {
+        s.notify(ctx, memberId, modelMember.ChannelEmail, category, eventType, payload)
+    }
+}
+
+func (s service) notify(ctx context.Context, memberId int, channel, category, eventType string, payload interface{}) {
+    optOut, err := s.repo.IsNotificationOptOut(ctx, memberId, channel, category)
+    if err != nil {
+        logrus.Errorln("notify: check opt out", channel, eventType, memberId, err)
+        return
+    }
+    if optOut {
+        return
+    }
+
+    recipient := strconv.Itoa(memberId)
+    if channel == modelMember.ChannelEmail {
+        member, errMember := s.repo.GetMember(ctx, memberId)
+        if errMember != nil {
+            logrus.Errorln("notify: get member", eventType, memberId, errMember)
+            return
+        }
+        if recipient = member.EmailAddress(); recipient == "" {
+            return
+        }
+    }
+
+    err = s.config.Notifier.Notify(channel, eventType, recipient, payload)
+    if err != nil && !errors.Is(err, notification.ErrNoTemplate) {
+        logrus.Errorln("notify: queue notification", channel, eventType, memberId, err)
+    }
+}
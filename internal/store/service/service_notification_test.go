@@ -0,0 +1,330 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "fmt"
+    "net/http"
+    "testing"
+    "time"
+
+    "store-api/internal/base/service/firebase"
+    "store-api/internal/base/service/notification"
+    modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    presenterMember "store-api/internal/store/presenter/member"
+
+    "firebase.google.com/go/messaging"
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) UpsertDevice(ctx context.Context, model modelMember.Device) error {
+    for i, device := range r.devices {
+        if device.Token == model.Token {
+            r.devices[i] = model
+            return nil
+        }
+    }
+    r.devices = append(r.devices, model)
+    return nil
+}
+
+func (r *stubRepository) DeleteDevice(ctx context.Context, memberId int, token string) error {
+    for i, device := range r.devices {
+        if device.MemberID == memberId && device.Token == token {
+            r.devices = append(r.devices[:i], r.devices[i+1:]...)
+            return nil
+        }
+    }
+    return nil
+}
+
+func (r *stubRepository) DeleteDeviceToken(ctx context.Context, tokens []string) error {
+    for _, token := range tokens {
+        for i, device := range r.devices {
+            if device.Token == token {
+                r.devices = append(r.devices[:i], r.devices[i+1:]...)
+                break
+            }
+        }
+    }
+    return nil
+}
+
+func (r *stubRepository) ListDevice(ctx context.Context, memberId int) (result []modelMember.Device, err error) {
+    for _, device := range r.devices {
+        if device.MemberID == memberId {
+            result = append(result, device)
+        }
+    }
+    return
+}
+
+func (r *stubRepository) SetNotificationOptOut(ctx context.Context, model modelMember.NotificationOptOut, optOut bool) error {
+    if r.optOuts == nil {
+        r.optOuts = make(map[int]bool)
+    }
+    r.optOuts[model.MemberID] = optOut
+    return nil
+}
+
+func (r *stubRepository) IsNotificationOptOut(ctx context.Context, memberId int, channel, category string) (bool, error) {
+    return r.optOuts[memberId], nil
+}
+
+func (r *stubRepository) GetMember(ctx context.Context, memberId int) (modelMember.Member, error) {
+    member, ok := r.members[memberId]
+    if !ok {
+        return member, sql.ErrNoRows
+    }
+    return member, nil
+}
+
+func (r *stubRepository) CreateNotificationLog(ctx context.Context, model notification.Log) error {
+    r.notificationLogs = append(r.notificationLogs, model)
+    return nil
+}
+
+// fakeFirebase record sent message, token in errors fail with the error
+type fakeFirebase struct {
+    sent   []*messaging.Message
+    errors map[string]error
+}
+
+func (f *fakeFirebase) Send(ctx context.Context, data *messaging.Message) (string, error) {
+    if err := f.errors[data.Token]; err != nil {
+        return "", err
+    }
+    f.sent = append(f.sent, data)
+    return fmt.Sprintf("projects/store/messages/%d", len(f.sent)), nil
+}
+
+// fakeNotifier record the notification rendered with the order templates
+type fakeNotifier struct {
+    messages []notification.Message
+}
+
+func (f *fakeNotifier) Notify(channel, eventType, recipient string, data interface{}) error {
+    subject, body, err := MemberTemplates.Render(eventType, channel, data)
+    if err != nil {
+        return err
+    }
+    f.messages = append(f.messages, notification.Message{Channel: channel, EventType: eventType, Recipient: recipient,
+        Subject: subject, Body: body})
+    return nil
+}
+
+// newPushSender dispatcher with only the push channel, recorder can be nil
+func newPushSender(push *fakeFirebase, recorder notification.Recorder) *notification.Dispatcher {
+    return notification.NewDispatcher(notification.Config{Backoff: time.Millisecond}, MemberTemplates, recorder,
+        notification.PushChannel{Client: push})
+}
+
+func newNotificationService(push *fakeFirebase) (*stubRepository, StoreService) {
+    repo := &stubRepository{devices: []modelMember.Device{
+        {MemberID: 7, Token: "token-a", Platform: modelMember.PlatformAndroid},
+        {MemberID: 7, Token: "token-b", Platform: modelMember.PlatformIOS},
+        {MemberID: 8, Token: "token-c", Platform: modelMember.PlatformWeb},
+    }}
+    return repo, NewService(repo, nil, Config{Push: newPushSender(push, repo)})
+}
+
+func TestService_RegisterDevice(t *testing.T) {
+    repo, svc := newNotificationService(&fakeFirebase{})
+
+    tests := []struct {
+        name       string
+        request    presenterMember.DeviceRequest
+        httpStatus int
+    }{
+        {"New device", presenterMember.DeviceRequest{MemberID: 7, Token: "token-d", Platform: "android"}, http.StatusOK},
+        {"Token moved to other member", presenterMember.DeviceRequest{MemberID: 9, Token: "token-c", Platform: "web"}, http.StatusOK},
+        {"Empty token", presenterMember.DeviceRequest{MemberID: 7, Token: " ", Platform: "android"}, http.StatusBadRequest},
+        {"Unknown platform", presenterMember.DeviceRequest{MemberID: 7, Token: "token-e", Platform: "tv"}, http.StatusBadRequest},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            httpStatus, err := svc.RegisterDevice(context.Background(), tt.request)
+            assert.Equal(t, tt.httpStatus, httpStatus)
+            assert.Equal(t, tt.httpStatus != http.StatusOK, err != nil)
+        })
+    }
+
+    devices, _ := repo.ListDevice(context.Background(), 9)
+    assert.Len(t, devices, 1)
+    assert.Len(t, repo.devices, 4)
+}
+
+func TestService_SendOrderNotification(t *testing.T) {
+    order := modelOutbox.OrderPayload{TransactionID: 1, TrxCode: "TRX-1", MemberID: 7, Amount: 150000}
+
+    t.Run("Send to every device", func(t *testing.T) {
+        push := &fakeFirebase{}
+        repo, svc := newNotificationService(push)
+
+        sent, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderCreated, order)
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Equal(t, 2, sent)
+        assert.Equal(t, "Order received", push.sent[0].Notification.Title)
+        assert.Equal(t, "Order TRX-1 is received, total 150000.", push.sent[0].Notification.Body)
+        assert.Equal(t, "TRX-1", push.sent[1].Data["trx_code"])
+        assert.Equal(t, []notification.Log{
+            {Channel: notification.ChannelPush, EventType: modelOutbox.EventOrderCreated, Recipient: "token-a",
+                Subject: "Order received", Status: notification.StatusSent, Attempts: 1},
+            {Channel: notification.ChannelPush, EventType: modelOutbox.EventOrderCreated, Recipient: "token-b",
+                Subject: "Order received", Status: notification.StatusSent, Attempts: 1},
+        }, repo.notificationLogs)
+    })
+
+    t.Run("Event without template is skipped", func(t *testing.T) {
+        push := &fakeFirebase{}
+        _, svc := newNotificationService(push)
+
+        sent, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventStockChanged, order)
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Zero(t, sent)
+        assert.Empty(t, push.sent)
+    })
+
+    t.Run("Opted out member is skipped", func(t *testing.T) {
+        push := &fakeFirebase{}
+        _, svc := newNotificationService(push)
+        _, err := svc.SetNotificationOptOut(context.Background(), presenterMember.NotificationOptOutRequest{MemberID: 7,
+            Category: modelMember.CategoryOrder, OptOut: true})
+        assert.NoError(t, err)
+
+        sent, _, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.NoError(t, err)
+        assert.Zero(t, sent)
+
+        _, err = svc.SetNotificationOptOut(context.Background(), presenterMember.NotificationOptOutRequest{MemberID: 7,
+            Category: modelMember.CategoryOrder, OptOut: false})
+        assert.NoError(t, err)
+        sent, _, _ = svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.Equal(t, 2, sent)
+    })
+
+    t.Run("Invalid token is pruned", func(t *testing.T) {
+        push := &fakeFirebase{errors: map[string]error{"token-a": fmt.Errorf("%w: not registered", firebase.ErrInvalidToken)}}
+        repo, svc := newNotificationService(push)
+
+        sent, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Equal(t, 1, sent)
+
+        devices, _ := repo.ListDevice(context.Background(), 7)
+        assert.Len(t, devices, 1)
+        assert.Equal(t, "token-b", devices[0].Token)
+        assert.Equal(t, notification.StatusFailed, repo.notificationLogs[0].Status)
+        assert.Equal(t, 1, repo.notificationLogs[0].Attempts, "Invalid token is not retried")
+    })
+
+    t.Run("Retry when nothing is sent", func(t *testing.T) {
+        unavailable := errors.New("fcm unavailable")
+        push := &fakeFirebase{errors: map[string]error{"token-a": unavailable, "token-b": unavailable}}
+        repo, svc := newNotificationService(push)
+
+        sent, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.Error(t, err)
+        assert.Equal(t, http.StatusInternalServerError, httpStatus)
+        assert.Zero(t, sent)
+        assert.Len(t, repo.devices, 3)
+    })
+}
+
+func TestService_SendOrderNotification_InApp(t *testing.T) {
+    order := modelOutbox.OrderPayload{TransactionID: 1, TrxCode: "TRX-1", MemberID: 7, Amount: 150000}
+
+    t.Run("Queued without push", func(t *testing.T) {
+        notifier := &fakeNotifier{}
+        repo := &stubRepository{}
+        svc := NewService(repo, nil, Config{Notifier: notifier})
+
+        sent, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Zero(t, sent)
+        assert.Equal(t, []notification.Message{{Channel: notification.ChannelInApp, EventType: modelOutbox.EventOrderPaid,
+            Recipient: "7", Subject: "Payment success",
+            Body: "Payment of order TRX-1 is received, we are preparing your order."}}, notifier.messages)
+
+        _, _, err = svc.SendOrderNotification(context.Background(), modelOutbox.EventStockChanged, order)
+        assert.NoError(t, err)
+        assert.Len(t, notifier.messages, 1)
+    })
+
+    t.Run("Email to member signed up with email", func(t *testing.T) {
+        notifier := &fakeNotifier{}
+        repo := &stubRepository{members: map[int]modelMember.Member{
+            7: {ID: 7, Username: "budi@example.com"},
+            8: {ID: 8, Username: "budi"},
+        }}
+        svc := NewService(repo, nil, Config{Notifier: notifier, Email: true})
+
+        _, _, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, order)
+        assert.NoError(t, err)
+        assert.Len(t, notifier.messages, 2)
+        assert.Equal(t, notification.Message{Channel: notification.ChannelEmail, EventType: modelOutbox.EventOrderPaid,
+            Recipient: "budi@example.com", Subject: "Payment success",
+            Body: "Payment of order TRX-1 is received, we are preparing your order."}, notifier.messages[1])
+
+        // Username is not an email, only the in-app notification is queued
+        other := order
+        other.MemberID = 8
+        _, _, err = svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderPaid, other)
+        assert.NoError(t, err)
+        assert.Len(t, notifier.messages, 3)
+        assert.Equal(t, notification.ChannelInApp, notifier.messages[2].Channel)
+    })
+
+    t.Run("Opt out per channel", func(t *testing.T) {
+        notifier := &fakeNotifier{}
+        push := &fakeFirebase{}
+        repo, _ := newNotificationService(push)
+        repo.optOuts = map[int]bool{7: true}
+        svc := NewService(repo, nil, Config{Push: newPushSender(push, repo), Notifier: notifier, Email: true})
+
+        sent, _, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderCreated, order)
+        assert.NoError(t, err)
+        assert.Zero(t, sent)
+        assert.Empty(t, notifier.messages)
+    })
+
+    t.Run("Not queued when push is retried", func(t *testing.T) {
+        notifier := &fakeNotifier{}
+        unavailable := errors.New("fcm unavailable")
+        push := &fakeFirebase{errors: map[string]error{"token-a": unavailable, "token-b": unavailable}}
+        repo, _ := newNotificationService(push)
+        svc := NewService(repo, nil, Config{Push: newPushSender(push, repo), Notifier: notifier})
+
+        _, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderCreated, order)
+        assert.Error(t, err)
+        assert.Equal(t, http.StatusInternalServerError, httpStatus)
+        assert.Empty(t, notifier.messages)
+    })
+
+    t.Run("Not configured", func(t *testing.T) {
+        svc := NewService(&stubRepository{}, nil, Config{})
+        _, httpStatus, err := svc.SendOrderNotification(context.Background(), modelOutbox.EventOrderCreated, order)
+        assert.Error(t, err)
+        assert.Equal(t, http.StatusInternalServerError, httpStatus)
+    })
+}
+
+func TestService_SetNotificationOptOut_Invalid(t *testing.T) {
+    _, svc := newNotificationService(&fakeFirebase{})
+
+    httpStatus, err := svc.SetNotificationOptOut(context.Background(), presenterMember.NotificationOptOutRequest{MemberID: 7, Channel: "sms",
+        Category: modelMember.CategoryOrder, OptOut: true})
+    assert.Error(t, err)
+    assert.Equal(t, http.StatusBadRequest, httpStatus)
+
+    httpStatus, err = svc.SetNotificationOptOut(context.Background(), presenterMember.NotificationOptOutRequest{MemberID: 7, Category: "promo",
+        OptOut: true})
+    assert.Error(t, err)
+    assert.Equal(t, http.StatusBadRequest, httpStatus)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "time"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/event"
+
+    "github.com/sirupsen/logrus"
+)
+
+const (
+    OutboxBatchSize   = 100
+    OutboxMaxAttempts = 10 // Event is marked failed after, needs manual action
+
+    outboxLease          = time.Minute // Longer than a batch publish
+    outboxPublishTimeout = 10 * time.Second
+    outboxMaxRetryDelay  = 10 * time.Minute
+)
+
+// RelayOutbox publish every pending outbox event, run by the outbox relay. Failed event is retried with backoff
+// by a later run. Event is published at least once, consumer must dedupe by id.
+func (s service) RelayOutbox(ctx context.Context) (sent int, httpStatus int, err error) {
+    if s.config.Publisher == nil {
+        httpStatus = http.StatusInternalServerError
+        err = errors.New("Kafka publisher is not configured")
+        return
+    }
+
+    for {
+        var events []modelOutbox.Event
+        events, err = s.repo.ClaimOutbox(ctx, time.Now(), outboxLease, OutboxBatchSize)
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+
+        for _, outbox := range events {
+            if s.publishOutbox(ctx, outbox) {
+                sent++
+            }
+        }
+        if len(events) < OutboxBatchSize {
+            break
+        }
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+func (s service) publishOutbox(ctx context.Context, outbox modelOutbox.Event) bool {
+    publishCtx, cancel := context.WithTimeout(ctx, outboxPublishTimeout)
+    // Keyed by aggregate so events of an order or a variant stay in order
+    err := event.Publish(publishCtx, s.config.Publisher, outbox.Topic, outbox.Envelope(s.config.EventSource), event.JSON,
+        messaging.WithKey(outbox.AggregateID))
+    cancel()
+
+    if err == nil {
+        if err = s.repo.MarkOutboxSent(ctx, outbox.ID, time.Now()); err != nil {
+            // Published again after lease, consumer dedupe it
+            logrus.Errorln("RelayOutbox: mark sent", outbox.ID, err)
+        }
+        return true
+    }
+
+    outbox.Attempts++
+    outbox.LastError = err.Error()
+    if len(outbox.LastError) > 255 {
+        outbox.LastError = outbox.LastError[:255]
+    }
+    outbox.AvailableAt = time.Now().Add(outboxRetryDelay(outbox.Attempts))
+    if outbox.Attempts >= OutboxMaxAttempts {
+        outbox.Status = modelOutbox.StatusFailed
+    }
+    logrus.Errorln("RelayOutbox: publish", outbox.ID, outbox.EventType, "attempt", outbox.Attempts, err)
+
+    if err = s.repo.MarkOutboxFailed(ctx, outbox); err != nil {
+        logrus.Errorln("RelayOutbox: mark failed", outbox.ID, err)
+    }
+    return false
+}
+
+// outboxRetryDelay exponential backoff from 2 seconds
+func outboxRetryDelay(attempts int) time.Duration {
+    if attempts > 10 {
+        return outboxMaxRetryDelay
+    }
+    delay := time.Second << uint(attempts)
+    if delay > outboxMaxRetryDelay {
+        delay = outboxMaxRetryDelay
+    }
+    return delay
+}
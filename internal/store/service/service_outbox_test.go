@@ -0,0 +1,152 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "net/http"
+    "testing"
+    "time"
+
+    modelOutbox "store-api/internal/store/domain/outbox"
+    "store-api/pkg/messaging"
+    "store-api/pkg/messaging/event"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) ClaimOutbox(ctx context.Context, now time.Time, lease time.Duration, limit int) (result []modelOutbox.Event, err error) {
+    for i, event := range r.outbox {
+        if len(result) == limit {
+            break
+        }
+        if event.Status != modelOutbox.StatusPending || event.AvailableAt.After(now) {
+            continue
+        }
+        r.outbox[i].AvailableAt = now.Add(lease)
+        result = append(result, event)
+    }
+    return
+}
+
+func (r *stubRepository) MarkOutboxSent(ctx context.Context, id int64, sentAt time.Time) error {
+    for i := range r.outbox {
+        if r.outbox[i].ID == id {
+            r.outbox[i].Status = modelOutbox.StatusSent
+            r.outbox[i].Attempts++
+            r.outbox[i].SentAt.Time, r.outbox[i].SentAt.Valid = sentAt, true
+        }
+    }
+    return nil
+}
+
+func (r *stubRepository) MarkOutboxFailed(ctx context.Context, model modelOutbox.Event) error {
+    for i := range r.outbox {
+        if r.outbox[i].ID == model.ID {
+            r.outbox[i] = model
+        }
+    }
+    return nil
+}
+
+func newOutboxService(publisher *messaging.MemoryPublisher, count int) (*stubRepository, StoreService) {
+    repo := &stubRepository{}
+    for i := 1; i <= count; i++ {
+        event, _ := modelOutbox.NewEvent(modelOutbox.TopicOrders, modelOutbox.EventOrderCreated, "1",
+            modelOutbox.OrderPayload{TransactionID: i, TrxCode: "TRX-1"})
+        event.ID = int64(i)
+        event.AvailableAt = time.Now().Add(-time.Second)
+        repo.outbox = append(repo.outbox, event)
+    }
+
+    config := Config{}
+    if publisher != nil {
+        config.Publisher = publisher
+    }
+    return repo, NewService(repo, nil, config)
+}
+
+func TestService_RelayOutbox(t *testing.T) {
+    t.Run("Publish every pending event", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        repo, svc := newOutboxService(publisher, OutboxBatchSize+5)
+
+        sent, httpStatus, err := svc.RelayOutbox(context.Background())
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Equal(t, OutboxBatchSize+5, sent)
+        for _, event := range repo.outbox {
+            assert.Equal(t, modelOutbox.StatusSent, event.Status)
+        }
+
+        messages := publisher.Messages(modelOutbox.TopicOrders)
+        assert.Len(t, messages, OutboxBatchSize+5)
+        assert.Equal(t, "1", messages[0].Key)
+        assert.Equal(t, map[string]string{messaging.HeaderEventType: modelOutbox.EventOrderCreated,
+            messaging.HeaderSchemaVersion: "1", event.HeaderContentType: event.JSON.ContentType()}, messages[0].Headers)
+
+        registry := event.NewRegistry()
+        modelOutbox.RegisterEvents(registry)
+        env, err := registry.Decode(event.JSON.ContentType(), messages[0].Data.([]byte))
+        assert.Nil(t, err)
+        assert.Equal(t, "1", env.ID, "Outbox id is the event id")
+        assert.Equal(t, modelOutbox.EventOrderCreated, env.Type)
+        assert.Equal(t, &modelOutbox.OrderPayload{TransactionID: 1, TrxCode: "TRX-1"}, env.Payload)
+
+        // Nothing left to publish
+        sent, _, err = svc.RelayOutbox(context.Background())
+        assert.Nil(t, err)
+        assert.Equal(t, 0, sent)
+    })
+
+    t.Run("Retry failed publish with backoff", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        publisher.SetError(errors.New("broker down"))
+        repo, svc := newOutboxService(publisher, 1)
+
+        sent, _, err := svc.RelayOutbox(context.Background())
+        assert.Nil(t, err, "Publish error is saved on the event")
+        assert.Equal(t, 0, sent)
+
+        event := repo.outbox[0]
+        assert.Equal(t, modelOutbox.StatusPending, event.Status)
+        assert.Equal(t, 1, event.Attempts)
+        assert.Equal(t, "broker down", event.LastError)
+        assert.WithinDuration(t, time.Now().Add(2*time.Second), event.AvailableAt, time.Second)
+
+        // Not available yet
+        publisher.SetError(nil)
+        sent, _, _ = svc.RelayOutbox(context.Background())
+        assert.Equal(t, 0, sent)
+
+        repo.outbox[0].AvailableAt = time.Now().Add(-time.Second)
+        sent, _, _ = svc.RelayOutbox(context.Background())
+        assert.Equal(t, 1, sent)
+        assert.Equal(t, modelOutbox.StatusSent, repo.outbox[0].Status)
+    })
+
+    t.Run("Stop retry after max attempts", func(t *testing.T) {
+        publisher := messaging.NewMemoryPublisher()
+        publisher.SetError(errors.New("broker down"))
+        repo, svc := newOutboxService(publisher, 1)
+        repo.outbox[0].Attempts = OutboxMaxAttempts - 1
+
+        _, _, err := svc.RelayOutbox(context.Background())
+        assert.Nil(t, err)
+        assert.Equal(t, modelOutbox.StatusFailed, repo.outbox[0].Status)
+    })
+
+    t.Run("Publisher not configured", func(t *testing.T) {
+        _, svc := newOutboxService(nil, 1)
+
+        _, httpStatus, err := svc.RelayOutbox(context.Background())
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusInternalServerError, httpStatus)
+    })
+}
+
+func TestOutboxRetryDelay(t *testing.T) {
+    assert.Equal(t, 2*time.Second, outboxRetryDelay(1))
+    assert.Equal(t, 64*time.Second, outboxRetryDelay(6))
+    assert.Equal(t, outboxMaxRetryDelay, outboxRetryDelay(10))
+    assert.Equal(t, outboxMaxRetryDelay, outboxRetryDelay(100))
+}
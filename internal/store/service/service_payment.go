@@ -0,0 +1,46 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+)
+
+// UpdateTransactionStatus apply payment result, run by the payment consumer
+func (s service) UpdateTransactionStatus(ctx context.Context, request presenterTransaction.PaymentStatusRequest) (httpStatus int, err error) {
+    if request.TrxCode == "" {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Trx code is required")
+        return
+    }
+    if request.Status != modelTransaction.StatusSuccess && request.Status != modelTransaction.StatusFailed {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Status must be success or failed")
+        return
+    }
+
+    _, err = s.repo.UpdateTransactionStatus(ctx, request.TrxCode, request.Status, request.ChannelRefNo)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Transaction not found")
+        return
+    }
+    if err == modelTransaction.ErrInvalidStatus {
+        httpStatus = http.StatusConflict
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    if request.Status == modelTransaction.StatusFailed {
+        s.recordFailedTransaction(FailedReasonPayment)
+    }
+    httpStatus = http.StatusOK
+    return
+}
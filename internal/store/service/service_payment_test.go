@@ -0,0 +1,105 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+    "testing"
+
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) UpdateTransactionStatus(ctx context.Context, trxCode, status, channelRefNo string) (modelTransaction.Transactions, error) {
+    var rows []modelTransaction.Transactions
+    for i := len(r.transactions) - 1; i >= 0; i-- {
+        if r.transactions[i].TrxCode == trxCode {
+            rows = append(rows, r.transactions[i])
+        }
+    }
+    transaction, ok := modelTransaction.CurrentOrder(rows)
+    if !ok {
+        return modelTransaction.Transactions{}, sql.ErrNoRows
+    }
+
+    for i := range r.transactions {
+        if r.transactions[i] != transaction {
+            continue
+        }
+        if !modelTransaction.CanChangeStatus(transaction.Status, status) {
+            return transaction, modelTransaction.ErrInvalidStatus
+        }
+        if transaction.Status != status && status == modelTransaction.StatusFailed && transaction.VariantID != 0 {
+            if _, err := r.CreateStockMovement(ctx, modelProduct.StockMovement{VariantID: transaction.VariantID,
+                Type: modelProduct.MovementRefund, Quantity: transaction.Quantity, Reference: trxCode}); err != nil {
+                return transaction, err
+            }
+        }
+        r.transactions[i].Status = status
+        r.transactions[i].ChannelRefNo = channelRefNo
+        return r.transactions[i], nil
+    }
+    return modelTransaction.Transactions{}, sql.ErrNoRows
+}
+
+func TestService_UpdateTransactionStatus(t *testing.T) {
+    repo := &stubRepository{transactions: []modelTransaction.Transactions{
+        {ID: 1, TrxCode: "TRX-1", Status: modelTransaction.StatusPending},
+        {ID: 2, TrxCode: "TRX-2", Status: modelTransaction.StatusFailed},
+        {ID: 3, TrxCode: "TRX-3", Status: modelTransaction.StatusPending},
+        {ID: 4, TrxCode: "TRX-3", Status: modelTransaction.StatusFailed}, // Retry of the trx code failed the stock check
+    }}
+    svc := NewService(repo, nil, Config{})
+
+    tests := []struct {
+        name       string
+        request    presenterTransaction.PaymentStatusRequest
+        httpStatus int
+    }{
+        {"Paid", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: "success", ChannelRefNo: "REF-1"}, http.StatusOK},
+        {"Redelivered", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: "success", ChannelRefNo: "REF-1"}, http.StatusOK},
+        {"Paid next to failed retry", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-3", Status: "success"}, http.StatusOK},
+        {"Failed cannot be paid", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-2", Status: "success"}, http.StatusConflict},
+        {"Not found", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-9", Status: "success"}, http.StatusNotFound},
+        {"Unknown status", presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: "pending"}, http.StatusBadRequest},
+        {"Empty trx code", presenterTransaction.PaymentStatusRequest{Status: "success"}, http.StatusBadRequest},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            httpStatus, err := svc.UpdateTransactionStatus(context.Background(), tt.request)
+            assert.Equal(t, tt.httpStatus, httpStatus)
+            assert.Equal(t, tt.httpStatus != http.StatusOK, err != nil)
+        })
+    }
+
+    assert.Equal(t, modelTransaction.StatusSuccess, repo.transactions[0].Status)
+    assert.Equal(t, "REF-1", repo.transactions[0].ChannelRefNo)
+    assert.Equal(t, modelTransaction.StatusSuccess, repo.transactions[2].Status)
+    assert.Equal(t, modelTransaction.StatusFailed, repo.transactions[3].Status)
+}
+
+func TestService_CreateTransaction_PaymentFailed(t *testing.T) {
+    repo, svc := newStockService()
+
+    _, err := svc.CreateTransaction(context.Background(), presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, TrxCode: "TRX-1", Quantity: 2})
+    assert.Nil(t, err)
+    assert.Equal(t, modelTransaction.StatusPending, repo.transactions[0].Status)
+    assert.Equal(t, 3, repo.variants[1].Stock)
+
+    httpStatus, err := svc.UpdateTransactionStatus(context.Background(), presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: "failed"})
+    assert.Nil(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Equal(t, 5, repo.variants[1].Stock, "Stock is given back")
+
+    // Redelivered failed status doesn't give the stock back twice
+    _, err = svc.UpdateTransactionStatus(context.Background(), presenterTransaction.PaymentStatusRequest{TrxCode: "TRX-1", Status: "failed"})
+    assert.Nil(t, err)
+    assert.Equal(t, 5, repo.variants[1].Stock)
+    assert.Equal(t, modelProduct.MovementRefund, repo.movements[len(repo.movements)-1].Type)
+
+    ledger, _ := repo.GetLedgerStock(context.Background(), 1)
+    assert.Equal(t, repo.products[1].Stock, ledger)
+}
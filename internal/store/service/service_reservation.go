@@ -0,0 +1,29 @@
+package service
+
+import (
+    "context"
+    "net/http"
+    "time"
+)
+
+const ReservationReleaseBatchSize = 100
+
+// ReleaseExpiredReservation give back stock of every expired hold, run by the reservation worker
+func (s service) ReleaseExpiredReservation(ctx context.Context) (released int, httpStatus int, err error) {
+    now := time.Now()
+    for {
+        var count int
+        count, err = s.repo.ReleaseExpiredReservation(ctx, now, ReservationReleaseBatchSize)
+        released += count
+        if err != nil {
+            httpStatus = http.StatusInternalServerError
+            return
+        }
+        if count < ReservationReleaseBatchSize {
+            break
+        }
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
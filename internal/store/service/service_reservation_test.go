@@ -0,0 +1,211 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+    "testing"
+    "time"
+
+    modelCart "store-api/internal/store/domain/cart"
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterCart "store-api/internal/store/presenter/cart"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) GetVariant(ctx context.Context, variantId int) (modelProduct.Variant, error) {
+    variant, ok := r.variants[variantId]
+    if !ok {
+        return variant, sql.ErrNoRows
+    }
+    return variant, nil
+}
+
+func (r *stubRepository) GetDefaultVariant(ctx context.Context, productId int) (modelProduct.Variant, error) {
+    for id := 1; id <= len(r.variants); id++ {
+        if r.variants[id].ProductID == productId {
+            return r.variants[id], nil
+        }
+    }
+    return modelProduct.Variant{}, sql.ErrNoRows
+}
+
+func (r *stubRepository) CreateCart(ctx context.Context, model modelCart.Cart) error {
+    r.carts = append(r.carts, model)
+    return nil
+}
+
+func (r *stubRepository) DeleteProductInCart(ctx context.Context, memberId, productId, variantId int) error {
+    return nil
+}
+
+func (r *stubRepository) CreateCartWithReservation(ctx context.Context, cart modelCart.Cart, reservation modelCart.Reservation) (modelCart.Reservation, error) {
+    reservation.ID = len(r.reservations) + 1
+    reservation.Status = modelCart.ReservationActive
+    _, err := r.CreateStockMovement(ctx, modelProduct.StockMovement{VariantID: reservation.VariantID,
+        Type: modelProduct.MovementReservation, Quantity: -reservation.Quantity})
+    if err != nil {
+        return reservation, err
+    }
+
+    r.carts = append(r.carts, cart)
+    r.reservations = append(r.reservations, reservation)
+    return reservation, nil
+}
+
+func (r *stubRepository) releaseWhere(ctx context.Context, status string, match func(modelCart.Reservation) bool) (released int) {
+    for i, reservation := range r.reservations {
+        if reservation.Status != modelCart.ReservationActive || !match(reservation) {
+            continue
+        }
+        r.reservations[i].Status = status
+        r.CreateStockMovement(ctx, modelProduct.StockMovement{VariantID: reservation.VariantID,
+            Type: modelProduct.MovementReservation, Quantity: reservation.Quantity})
+        released++
+    }
+    return
+}
+
+func (r *stubRepository) ReleaseReservation(ctx context.Context, memberId, productId, variantId int) error {
+    r.releaseWhere(ctx, modelCart.ReservationReleased, func(reservation modelCart.Reservation) bool {
+        return reservation.MemberID == memberId && reservation.ProductID == productId &&
+            (variantId == 0 || reservation.VariantID == variantId)
+    })
+    return nil
+}
+
+func (r *stubRepository) ReleaseExpiredReservation(ctx context.Context, now time.Time, limit int) (int, error) {
+    return r.releaseWhere(ctx, modelCart.ReservationExpired, func(reservation modelCart.Reservation) bool {
+        if limit == 0 || reservation.ExpiredAt.After(now) {
+            return false
+        }
+        limit--
+        return true
+    }), nil
+}
+
+func (r *stubRepository) SumActiveReservation(ctx context.Context, memberId, variantId int) (quantity int, err error) {
+    for _, reservation := range r.reservations {
+        if reservation.Status == modelCart.ReservationActive && reservation.MemberID == memberId && reservation.VariantID == variantId {
+            quantity += reservation.Quantity
+        }
+    }
+    return
+}
+
+func (r *stubRepository) CreateTransaction(ctx context.Context, model modelTransaction.Transactions) error {
+    r.releaseWhere(ctx, modelCart.ReservationConverted, func(reservation modelCart.Reservation) bool {
+        return reservation.MemberID == model.MemberID && reservation.VariantID == model.VariantID
+    })
+    _, err := r.CreateStockMovement(ctx, modelProduct.StockMovement{VariantID: model.VariantID,
+        Type: modelProduct.MovementSale, Quantity: -model.Quantity})
+    if err != nil {
+        return err
+    }
+
+    r.transactions = append(r.transactions, model)
+    return nil
+}
+
+func newReservationService(config Config) (*stubRepository, StoreService) {
+    repo, _ := newStockService()
+    return repo, NewService(repo, nil, config)
+}
+
+func TestService_AddToCart_Reservation(t *testing.T) {
+    t.Run("Hold stock", func(t *testing.T) {
+        repo, svc := newReservationService(Config{ReservationEnabled: true, ReservationTTL: time.Minute})
+
+        _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+        assert.Nil(t, err)
+        assert.Len(t, repo.carts, 1)
+        assert.Len(t, repo.reservations, 1)
+        assert.WithinDuration(t, time.Now().Add(time.Minute), repo.reservations[0].ExpiredAt, time.Second)
+
+        // Available stock of product list subtract the hold
+        assert.Equal(t, 3, repo.variants[1].Stock)
+        assert.Equal(t, 3, repo.products[1].Stock)
+    })
+
+    t.Run("Not enough stock", func(t *testing.T) {
+        repo, svc := newReservationService(Config{ReservationEnabled: true})
+
+        httpStatus, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 6})
+        assert.Equal(t, modelProduct.ErrInsufficientStock, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+        assert.Len(t, repo.carts, 0)
+    })
+
+    t.Run("Default TTL", func(t *testing.T) {
+        repo, svc := newReservationService(Config{ReservationEnabled: true})
+
+        _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 1})
+        assert.Nil(t, err)
+        assert.WithinDuration(t, time.Now().Add(DefaultReservationTTL), repo.reservations[0].ExpiredAt, time.Second)
+    })
+
+    t.Run("Disabled", func(t *testing.T) {
+        repo, svc := newReservationService(Config{})
+
+        _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 6})
+        assert.Nil(t, err)
+        assert.Len(t, repo.carts, 1)
+        assert.Len(t, repo.reservations, 0)
+        assert.Equal(t, 5, repo.variants[1].Stock)
+    })
+}
+
+func TestService_DeleteProductInCart_Reservation(t *testing.T) {
+    repo, svc := newReservationService(Config{ReservationEnabled: true})
+
+    _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+    assert.Nil(t, err)
+
+    _, err = svc.DeleteProductInCart(context.Background(), presenterCart.CartProductDeleteRequest{MemberID: 7, ProductID: 1})
+    assert.Nil(t, err)
+    assert.Equal(t, modelCart.ReservationReleased, repo.reservations[0].Status)
+    assert.Equal(t, 5, repo.variants[1].Stock)
+}
+
+func TestService_CreateTransaction_Reservation(t *testing.T) {
+    repo, svc := newReservationService(Config{ReservationEnabled: true})
+
+    // Other member hold 3, only 2 left for member 7 on top of its own hold
+    _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 8, ProductID: 1, Quantity: 3})
+    assert.Nil(t, err)
+    _, err = svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+    assert.Nil(t, err)
+    assert.Equal(t, 0, repo.variants[1].Stock)
+
+    _, err = svc.CreateTransaction(context.Background(), presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+    assert.Nil(t, err)
+    assert.Len(t, repo.transactions, 1)
+    assert.Equal(t, modelCart.ReservationConverted, repo.reservations[1].Status)
+    assert.Equal(t, modelCart.ReservationActive, repo.reservations[0].Status)
+    assert.Equal(t, 0, repo.variants[1].Stock)
+
+    ledger, _ := repo.GetLedgerStock(context.Background(), 1)
+    assert.Equal(t, repo.products[1].Stock, ledger)
+}
+
+func TestService_ReleaseExpiredReservation(t *testing.T) {
+    repo, svc := newReservationService(Config{ReservationEnabled: true})
+    repo.variants[1] = modelProduct.Variant{ID: 1, ProductID: 1, Stock: 500}
+
+    expired := time.Now().Add(-time.Minute)
+    for i := 0; i < ReservationReleaseBatchSize+5; i++ {
+        repo.reservations = append(repo.reservations, modelCart.Reservation{ID: i + 1, VariantID: 1, Quantity: 1,
+            Status: modelCart.ReservationActive, ExpiredAt: expired})
+    }
+    repo.reservations = append(repo.reservations, modelCart.Reservation{VariantID: 1, Quantity: 1,
+        Status: modelCart.ReservationActive, ExpiredAt: time.Now().Add(time.Minute)})
+
+    released, _, err := svc.ReleaseExpiredReservation(context.Background())
+    assert.Nil(t, err)
+    assert.Equal(t, ReservationReleaseBatchSize+5, released)
+    assert.Equal(t, modelCart.ReservationActive, repo.reservations[len(repo.reservations)-1].Status)
+    assert.Equal(t, 500+ReservationReleaseBatchSize+5, repo.variants[1].Stock)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+
+    modelProduct "store-api/internal/store/domain/product"
+    presenterProduct "store-api/internal/store/presenter/product"
+)
+
+const (
+    DefaultStockHistoryPerPage = 50
+    MaxStockHistoryPerPage     = 200
+)
+
+// StockHistory movements of a product, newest first
+func (s service) StockHistory(ctx context.Context, request presenterProduct.StockHistoryRequest) (result presenterProduct.StockHistoryResponse, httpStatus int, err error) {
+    product, err := s.repo.GetProduct(ctx, request.ProductID)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.Page = request.Page
+    if result.Page < 1 {
+        result.Page = 1
+    }
+    result.PerPage = request.PerPage
+    if result.PerPage < 1 {
+        result.PerPage = DefaultStockHistoryPerPage
+    }
+    if result.PerPage > MaxStockHistoryPerPage {
+        result.PerPage = MaxStockHistoryPerPage
+    }
+
+    movements, err := s.repo.ListStockMovement(ctx, product.ID, request.VariantID, result.PerPage, (result.Page-1)*result.PerPage)
+    if err != nil && err != sql.ErrNoRows {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.Total, err = s.repo.CountStockMovement(ctx, product.ID, request.VariantID)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.LedgerStock, err = s.repo.GetLedgerStock(ctx, product.ID)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result.ProductID = product.ID
+    result.Stock = product.Stock
+    result.Movements = make([]presenterProduct.StockMovementResponse, len(movements))
+    for i, movement := range movements {
+        result.Movements[i] = presenterProduct.StockMovementResponse{
+            ID:          movement.ID,
+            VariantID:   movement.VariantID,
+            Type:        movement.Type,
+            Quantity:    movement.Quantity,
+            StockAfter:  movement.StockAfter,
+            Reference:   movement.Reference,
+            Note:        movement.Note,
+            CreatedDate: movement.CreatedDate,
+        }
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// AdjustStock manual movement by admin: restock, adjustment or refund
+func (s service) AdjustStock(ctx context.Context, request presenterProduct.StockAdjustRequest) (result presenterProduct.StockMovementResponse, httpStatus int, err error) {
+    if !modelProduct.IsManualMovement(request.Type) {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Type must be restock, adjustment or refund")
+        return
+    }
+    if request.Quantity == 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Quantity must not be 0")
+        return
+    }
+    if request.Type != modelProduct.MovementAdjustment && request.Quantity < 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Quantity of " + request.Type + " must be positive")
+        return
+    }
+
+    movement, err := s.repo.CreateStockMovement(ctx, modelProduct.StockMovement{
+        VariantID: request.VariantID,
+        Type:      request.Type,
+        Quantity:  request.Quantity,
+        Reference: request.Reference,
+        Note:      request.Note,
+    })
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product variant not found")
+        return
+    }
+    if err == modelProduct.ErrInsufficientStock {
+        httpStatus = http.StatusBadRequest
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    result = presenterProduct.StockMovementResponse{
+        ID:          movement.ID,
+        VariantID:   movement.VariantID,
+        Type:        movement.Type,
+        Quantity:    movement.Quantity,
+        StockAfter:  movement.StockAfter,
+        Reference:   movement.Reference,
+        Note:        movement.Note,
+        CreatedDate: movement.CreatedDate,
+    }
+    httpStatus = http.StatusCreated
+    return
+}
@@ -0,0 +1,203 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+    "testing"
+
+    modelProduct "store-api/internal/store/domain/product"
+    modelTransaction "store-api/internal/store/domain/transaction"
+    presenterProduct "store-api/internal/store/presenter/product"
+    presenterTransaction "store-api/internal/store/presenter/transaction"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) ListStockMovement(ctx context.Context, productId, variantId, limit, offset int) (result []modelProduct.StockMovement, err error) {
+    for i := len(r.movements) - 1; i >= 0; i-- {
+        movement := r.movements[i]
+        if movement.ProductID == productId && (variantId == 0 || movement.VariantID == variantId) {
+            result = append(result, movement)
+        }
+    }
+    if offset >= len(result) {
+        return nil, nil
+    }
+    result = result[offset:]
+    if len(result) > limit {
+        result = result[:limit]
+    }
+    return
+}
+
+func (r *stubRepository) CountStockMovement(ctx context.Context, productId, variantId int) (int, error) {
+    result, _ := r.ListStockMovement(ctx, productId, variantId, len(r.movements), 0)
+    return len(result), nil
+}
+
+func (r *stubRepository) GetLedgerStock(ctx context.Context, productId int) (stock int, err error) {
+    for _, movement := range r.movements {
+        if movement.ProductID == productId {
+            stock += movement.Quantity
+        }
+    }
+    return
+}
+
+func (r *stubRepository) CreateStockMovement(ctx context.Context, movement modelProduct.StockMovement) (modelProduct.StockMovement, error) {
+    variant, ok := r.variants[movement.VariantID]
+    if !ok {
+        return movement, sql.ErrNoRows
+    }
+    if variant.Stock+movement.Quantity < 0 {
+        return movement, modelProduct.ErrInsufficientStock
+    }
+
+    variant.Stock += movement.Quantity
+    r.variants[variant.ID] = variant
+
+    product := r.products[variant.ProductID]
+    product.Stock += movement.Quantity
+    if product.Stock >= product.ReorderThreshold {
+        product.LowStockAlerted = false
+    }
+    r.products[product.ID] = product
+
+    movement.ID = len(r.movements) + 1
+    movement.ProductID = variant.ProductID
+    movement.StockAfter = variant.Stock
+    r.movements = append(r.movements, movement)
+    return movement, nil
+}
+
+func newStockService() (*stubRepository, StoreService) {
+    repo := &stubRepository{
+        products: map[int]modelProduct.Product{1: {ID: 1, Name: "Shirt", Stock: 5}},
+        variants: map[int]modelProduct.Variant{1: {ID: 1, ProductID: 1, SKU: "SHIRT-M", Stock: 5}},
+        movements: []modelProduct.StockMovement{
+            {ID: 1, ProductID: 1, VariantID: 1, Type: modelProduct.MovementAdjustment, Quantity: 5, StockAfter: 5},
+        },
+    }
+    return repo, NewService(repo, nil, Config{})
+}
+
+func TestService_AdjustStock(t *testing.T) {
+    t.Run("Restock", func(t *testing.T) {
+        repo, svc := newStockService()
+
+        result, httpStatus, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 1, Type: "restock", Quantity: 10, Reference: "PO-1"})
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusCreated, httpStatus)
+        assert.Equal(t, 15, result.StockAfter)
+        assert.Equal(t, "PO-1", result.Reference)
+        assert.Equal(t, 15, repo.products[1].Stock)
+    })
+
+    t.Run("Negative adjustment", func(t *testing.T) {
+        _, svc := newStockService()
+
+        result, _, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 1, Type: "adjustment", Quantity: -2, Note: "Broken"})
+        assert.Nil(t, err)
+        assert.Equal(t, 3, result.StockAfter)
+    })
+
+    t.Run("Stock must not be negative", func(t *testing.T) {
+        repo, svc := newStockService()
+
+        _, httpStatus, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 1, Type: "adjustment", Quantity: -6})
+        assert.Equal(t, modelProduct.ErrInsufficientStock, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+        assert.Len(t, repo.movements, 1)
+    })
+
+    t.Run("Reject invalid request", func(t *testing.T) {
+        _, svc := newStockService()
+
+        for _, request := range []presenterProduct.StockAdjustRequest{
+            {VariantID: 1, Type: "sale", Quantity: -1},
+            {VariantID: 1, Type: "reservation", Quantity: -1},
+            {VariantID: 1, Type: "restock", Quantity: -1},
+            {VariantID: 1, Type: "adjustment", Quantity: 0},
+        } {
+            _, httpStatus, err := svc.AdjustStock(context.Background(), request)
+            assert.NotNil(t, err, request.Type)
+            assert.Equal(t, http.StatusBadRequest, httpStatus, request.Type)
+        }
+    })
+
+    t.Run("Variant not found", func(t *testing.T) {
+        _, svc := newStockService()
+
+        _, httpStatus, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 2, Type: "restock", Quantity: 1})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+    })
+}
+
+func TestService_StockHistory(t *testing.T) {
+    _, svc := newStockService()
+    for i := 0; i < 3; i++ {
+        _, _, err := svc.AdjustStock(context.Background(), presenterProduct.StockAdjustRequest{VariantID: 1, Type: "restock", Quantity: 1})
+        assert.Nil(t, err)
+    }
+
+    t.Run("Newest first with pagination", func(t *testing.T) {
+        result, httpStatus, err := svc.StockHistory(context.Background(), presenterProduct.StockHistoryRequest{ProductID: 1, Page: 2, PerPage: 3})
+        assert.Nil(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Equal(t, 4, result.Total)
+        assert.Len(t, result.Movements, 1)
+        assert.Equal(t, 1, result.Movements[0].ID)
+    })
+
+    t.Run("Stock match the ledger", func(t *testing.T) {
+        result, _, err := svc.StockHistory(context.Background(), presenterProduct.StockHistoryRequest{ProductID: 1})
+        assert.Nil(t, err)
+        assert.Equal(t, 8, result.Stock)
+        assert.Equal(t, result.Stock, result.LedgerStock)
+        assert.Equal(t, DefaultStockHistoryPerPage, result.PerPage)
+    })
+
+    t.Run("Product not found", func(t *testing.T) {
+        _, httpStatus, err := svc.StockHistory(context.Background(), presenterProduct.StockHistoryRequest{ProductID: 2})
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+    })
+}
+
+// oversellRepository another order take the stock between the check and the row lock
+type oversellRepository struct {
+    *stubRepository
+}
+
+func (r oversellRepository) CreateTransaction(ctx context.Context, model modelTransaction.Transactions) error {
+    if _, err := r.CreateStockMovement(ctx, modelProduct.StockMovement{VariantID: model.VariantID,
+        Type: modelProduct.MovementSale, Quantity: -4}); err != nil {
+        return err
+    }
+    return r.stubRepository.CreateTransaction(ctx, model)
+}
+
+func TestService_CreateTransaction_InsufficientStock(t *testing.T) {
+    t.Run("Before the lock", func(t *testing.T) {
+        repo, svc := newStockService()
+
+        httpStatus, err := svc.CreateTransaction(context.Background(), presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, Quantity: 6})
+        assert.Equal(t, http.StatusConflict, httpStatus)
+        assert.Equal(t, modelProduct.ErrInsufficientStock, err)
+        assert.Len(t, repo.transactions, 1)
+        assert.Equal(t, modelTransaction.StatusFailed, repo.transactions[0].Status)
+    })
+
+    t.Run("Under the lock", func(t *testing.T) {
+        repo, _ := newStockService()
+        svc := NewService(oversellRepository{repo}, nil, Config{})
+
+        httpStatus, err := svc.CreateTransaction(context.Background(), presenterTransaction.TransactionRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+        assert.Equal(t, http.StatusConflict, httpStatus)
+        assert.Equal(t, "Quantity not enough", err.Error())
+        assert.Equal(t, 1, repo.variants[1].Stock, "Only the other order is deducted")
+        assert.Equal(t, modelTransaction.StatusFailed, repo.transactions[len(repo.transactions)-1].Status)
+    })
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+    "context"
+    "net/http"
+    "testing"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func TestService_GetProduct(t *testing.T) {
+    _, svc := newWishlistService(Config{})
+
+    t.Run("Product with variants", func(t *testing.T) {
+        result, _, err := svc.GetProduct(context.Background(), 1)
+        assert.Nil(t, err)
+        assert.Equal(t, "Shirt", result.Name)
+        assert.Len(t, result.Variants, 2)
+        assert.Equal(t, "SHIRT-L", result.Variants[1].SKU)
+        assert.NotNil(t, result.Images)
+    })
+
+    t.Run("Product without variant", func(t *testing.T) {
+        result, _, err := svc.GetProduct(context.Background(), 2)
+        assert.Nil(t, err)
+        assert.Equal(t, "Cap", result.Name)
+        assert.Empty(t, result.Variants)
+    })
+
+    t.Run("Product not found", func(t *testing.T) {
+        _, httpStatus, err := svc.GetProduct(context.Background(), 99)
+        assert.NotNil(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+    })
+}
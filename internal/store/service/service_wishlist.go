@@ -0,0 +1,238 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+
+    modelCart "store-api/internal/store/domain/cart"
+    modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    presenterCart "store-api/internal/store/presenter/cart"
+
+    "github.com/sirupsen/logrus"
+)
+
+// BackInStockNotification data of the back in stock template
+type BackInStockNotification struct {
+    ProductID int
+    VariantID int
+    Name      string
+    Stock     int
+}
+
+func (s service) AddWishlist(ctx context.Context, request presenterCart.WishlistRequest) (httpStatus int, err error) {
+    variant, httpStatus, err := s.resolveVariant(ctx, request.ProductID, request.VariantID)
+    if err != nil {
+        return
+    }
+
+    err = s.repo.AddWishlist(ctx, modelCart.Wishlist{MemberID: request.MemberID, ProductID: variant.ProductID, VariantID: variant.ID})
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+func (s service) DeleteWishlist(ctx context.Context, request presenterCart.WishlistRequest) (httpStatus int, err error) {
+    if request.ProductID == 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Product is required")
+        return
+    }
+
+    err = s.repo.DeleteWishlist(ctx, request.MemberID, request.ProductID, request.VariantID)
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+func (s service) ListWishlist(ctx context.Context, request presenterCart.WishlistViewRequest) (result []presenterCart.WishlistResponse, httpStatus int, err error) {
+    result = []presenterCart.WishlistResponse{}
+    httpStatus = http.StatusInternalServerError
+
+    wishlists, err := s.repo.ListWishlist(ctx, request.MemberID)
+    if err != nil {
+        return
+    }
+
+    var productIds []int
+    seen := map[int]bool{}
+    for _, wishlist := range wishlists {
+        if !seen[wishlist.ProductID] {
+            seen[wishlist.ProductID] = true
+            productIds = append(productIds, wishlist.ProductID)
+        }
+    }
+
+    products := map[int]modelProduct.Product{}
+    variants := map[int]modelProduct.Variant{}
+    if len(productIds) > 0 {
+        listProduct, errProduct := s.repo.ListProductByID(ctx, productIds)
+        if errProduct != nil {
+            err = errProduct
+            return
+        }
+        for _, product := range listProduct {
+            products[product.ID] = product
+        }
+
+        listVariant, errVariant := s.repo.ListVariant(ctx, productIds)
+        if errVariant != nil {
+            err = errVariant
+            return
+        }
+        for _, variant := range listVariant {
+            variants[variant.ID] = variant
+        }
+    }
+
+    for _, wishlist := range wishlists {
+        variant := variants[wishlist.VariantID]
+        result = append(result, presenterCart.WishlistResponse{
+            ID:          wishlist.ID,
+            ProductID:   wishlist.ProductID,
+            VariantID:   wishlist.VariantID,
+            Name:        products[wishlist.ProductID].Name,
+            Stock:       variant.Stock,
+            InStock:     variant.IsActive && variant.Stock > 0,
+            CreatedDate: wishlist.CreatedDate,
+        })
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// MoveWishlistToCart add the wishlist variant to the cart and remove it from the wishlist at once
+func (s service) MoveWishlistToCart(ctx context.Context, request presenterCart.WishlistMoveRequest) (httpStatus int, err error) {
+    if request.Quantity < 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Quantity must be greater than 0")
+        return
+    }
+    if request.Quantity == 0 {
+        request.Quantity = 1
+    }
+
+    variant, httpStatus, err := s.resolveVariant(ctx, request.ProductID, request.VariantID)
+    if err != nil {
+        return
+    }
+
+    cart := modelCart.Cart{MemberID: request.MemberID, ProductID: variant.ProductID, VariantID: variant.ID,
+        Quantity: request.Quantity}
+    var reservation *modelCart.Reservation
+    if s.config.ReservationEnabled {
+        reservation = &modelCart.Reservation{
+            MemberID:  cart.MemberID,
+            ProductID: cart.ProductID,
+            VariantID: cart.VariantID,
+            Quantity:  cart.Quantity,
+            ExpiredAt: time.Now().Add(s.config.ReservationTTL),
+        }
+    }
+
+    err = s.repo.MoveWishlistToCart(ctx, cart, reservation)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found in wishlist")
+        return
+    }
+    if err == modelProduct.ErrInsufficientStock {
+        httpStatus = http.StatusBadRequest
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// MoveCartToWishlist save the cart item for later, its hold is released
+func (s service) MoveCartToWishlist(ctx context.Context, request presenterCart.WishlistMoveRequest) (httpStatus int, err error) {
+    if request.ProductID == 0 {
+        httpStatus = http.StatusBadRequest
+        err = errors.New("Product is required")
+        return
+    }
+
+    err = s.repo.MoveCartToWishlist(ctx, request.MemberID, request.ProductID, request.VariantID)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Cart not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    httpStatus = http.StatusOK
+    return
+}
+
+// NotifyBackInStock notify member who has the variant in the wishlist, run by the stock consumer.
+// Send is best effort, only error reading the wishlist is returned so the event is retried.
+func (s service) NotifyBackInStock(ctx context.Context, stock modelOutbox.StockPayload) (notified int, httpStatus int, err error) {
+    if s.config.Push == nil && s.config.Notifier == nil {
+        httpStatus = http.StatusInternalServerError
+        err = errors.New("Notification is not configured")
+        return
+    }
+
+    httpStatus = http.StatusInternalServerError
+    members, err := s.repo.ListWishlistMember(ctx, stock.VariantID)
+    if err != nil {
+        return
+    }
+    httpStatus = http.StatusOK
+    if len(members) == 0 {
+        return
+    }
+
+    product, err := s.repo.GetProduct(ctx, stock.ProductID)
+    if err == sql.ErrNoRows {
+        httpStatus = http.StatusNotFound
+        err = errors.New("Product not found")
+        return
+    }
+    if err != nil {
+        httpStatus = http.StatusInternalServerError
+        return
+    }
+
+    payload := BackInStockNotification{ProductID: stock.ProductID, VariantID: stock.VariantID, Name: product.Name,
+        Stock: stock.StockAfter}
+    data := map[string]string{
+        "product_id": strconv.Itoa(stock.ProductID),
+        "variant_id": strconv.Itoa(stock.VariantID),
+    }
+    for _, memberId := range members {
+        if s.config.Push != nil {
+            if _, errPush := s.sendPush(ctx, memberId, modelMember.CategoryWishlist, modelOutbox.EventBackInStock, payload,
+                data); errPush != nil {
+                logrus.Errorln("NotifyBackInStock: push", memberId, errPush)
+            }
+        }
+        if s.config.Notifier != nil {
+            s.notifyMember(ctx, memberId, modelMember.CategoryWishlist, modelOutbox.EventBackInStock, payload)
+        }
+        notified++
+    }
+    return
+}
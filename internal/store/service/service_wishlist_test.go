@@ -0,0 +1,253 @@
+package service
+
+import (
+    "context"
+    "database/sql"
+    "net/http"
+    "testing"
+    "time"
+
+    modelCart "store-api/internal/store/domain/cart"
+    modelMember "store-api/internal/store/domain/member"
+    modelOutbox "store-api/internal/store/domain/outbox"
+    modelProduct "store-api/internal/store/domain/product"
+    presenterCart "store-api/internal/store/presenter/cart"
+
+    "github.com/stretchr/testify/assert"
+)
+
+func (r *stubRepository) AddWishlist(ctx context.Context, model modelCart.Wishlist) error {
+    for _, wishlist := range r.wishlists {
+        if wishlist.MemberID == model.MemberID && wishlist.VariantID == model.VariantID {
+            return nil
+        }
+    }
+    model.ID = len(r.wishlists) + 1
+    r.wishlists = append(r.wishlists, model)
+    return nil
+}
+
+func (r *stubRepository) DeleteWishlist(ctx context.Context, memberId, productId, variantId int) error {
+    r.removeWishlist(memberId, productId, variantId)
+    return nil
+}
+
+func (r *stubRepository) removeWishlist(memberId, productId, variantId int) (removed int) {
+    var kept []modelCart.Wishlist
+    for _, wishlist := range r.wishlists {
+        if wishlist.MemberID == memberId && (productId == 0 || wishlist.ProductID == productId) &&
+            (variantId == 0 || wishlist.VariantID == variantId) {
+            removed++
+            continue
+        }
+        kept = append(kept, wishlist)
+    }
+    r.wishlists = kept
+    return
+}
+
+func (r *stubRepository) ListWishlist(ctx context.Context, memberId int) (result []modelCart.Wishlist, err error) {
+    for _, wishlist := range r.wishlists {
+        if wishlist.MemberID == memberId {
+            result = append(result, wishlist)
+        }
+    }
+    return
+}
+
+func (r *stubRepository) ListWishlistMember(ctx context.Context, variantId int) (result []int, err error) {
+    for _, wishlist := range r.wishlists {
+        if wishlist.VariantID == variantId {
+            result = append(result, wishlist.MemberID)
+        }
+    }
+    return
+}
+
+func (r *stubRepository) ListProductByID(ctx context.Context, productIds []int) (result []modelProduct.Product, err error) {
+    for _, productId := range productIds {
+        if product, ok := r.products[productId]; ok {
+            result = append(result, product)
+        }
+    }
+    return
+}
+
+func (r *stubRepository) ListVariant(ctx context.Context, productIds []int) (result []modelProduct.Variant, err error) {
+    for id := 1; id <= len(r.variants); id++ {
+        for _, productId := range productIds {
+            if r.variants[id].ProductID == productId {
+                result = append(result, r.variants[id])
+            }
+        }
+    }
+    return
+}
+
+func (r *stubRepository) MoveWishlistToCart(ctx context.Context, cart modelCart.Cart, reservation *modelCart.Reservation) error {
+    wishlists := r.wishlists
+    if r.removeWishlist(cart.MemberID, 0, cart.VariantID) == 0 {
+        return sql.ErrNoRows
+    }
+    if reservation == nil {
+        return r.CreateCart(ctx, cart)
+    }
+    if _, err := r.CreateCartWithReservation(ctx, cart, *reservation); err != nil {
+        r.wishlists = wishlists // Rollback
+        return err
+    }
+    return nil
+}
+
+func (r *stubRepository) MoveCartToWishlist(ctx context.Context, memberId, productId, variantId int) error {
+    var kept []modelCart.Cart
+    for _, cart := range r.carts {
+        if cart.MemberID == memberId && cart.ProductID == productId && (variantId == 0 || cart.VariantID == variantId) {
+            r.AddWishlist(ctx, modelCart.Wishlist{MemberID: memberId, ProductID: productId, VariantID: cart.VariantID})
+            continue
+        }
+        kept = append(kept, cart)
+    }
+    if len(kept) == len(r.carts) {
+        return sql.ErrNoRows
+    }
+    r.carts = kept
+    return r.ReleaseReservation(ctx, memberId, productId, variantId)
+}
+
+func newWishlistService(config Config) (*stubRepository, StoreService) {
+    repo, _ := newStockService()
+    repo.variants[2] = modelProduct.Variant{ID: 2, ProductID: 1, SKU: "SHIRT-L", Stock: 0, IsActive: true}
+    repo.products[2] = modelProduct.Product{ID: 2, Name: "Cap"}
+    return repo, NewService(repo, nil, config)
+}
+
+func TestService_Wishlist(t *testing.T) {
+    repo, svc := newWishlistService(Config{})
+
+    tests := []struct {
+        name       string
+        request    presenterCart.WishlistRequest
+        httpStatus int
+    }{
+        {"Default variant", presenterCart.WishlistRequest{MemberID: 7, ProductID: 1}, http.StatusOK},
+        {"Variant", presenterCart.WishlistRequest{MemberID: 7, ProductID: 1, VariantID: 2}, http.StatusOK},
+        {"Added twice", presenterCart.WishlistRequest{MemberID: 7, ProductID: 1, VariantID: 2}, http.StatusOK},
+        {"Variant of other product", presenterCart.WishlistRequest{MemberID: 7, ProductID: 2, VariantID: 1}, http.StatusNotFound},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            httpStatus, err := svc.AddWishlist(context.Background(), tt.request)
+            assert.Equal(t, tt.httpStatus, httpStatus)
+            assert.Equal(t, tt.httpStatus != http.StatusOK, err != nil)
+        })
+    }
+
+    result, httpStatus, err := svc.ListWishlist(context.Background(), presenterCart.WishlistViewRequest{MemberID: 7})
+    assert.NoError(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Len(t, result, 2)
+    assert.Equal(t, "Shirt", result[1].Name)
+    assert.Equal(t, 2, result[1].VariantID)
+    assert.False(t, result[1].InStock)
+
+    httpStatus, err = svc.DeleteWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 7, ProductID: 1, VariantID: 2})
+    assert.NoError(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Len(t, repo.wishlists, 1)
+
+    result, _, _ = svc.ListWishlist(context.Background(), presenterCart.WishlistViewRequest{MemberID: 8})
+    assert.NotNil(t, result)
+    assert.Empty(t, result)
+}
+
+func TestService_MoveWishlistToCart(t *testing.T) {
+    t.Run("Moved", func(t *testing.T) {
+        repo, svc := newWishlistService(Config{})
+        svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 7, ProductID: 1})
+
+        httpStatus, err := svc.MoveWishlistToCart(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1})
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Empty(t, repo.wishlists)
+        assert.Equal(t, []modelCart.Cart{{MemberID: 7, ProductID: 1, VariantID: 1, Quantity: 1}}, repo.carts)
+    })
+
+    t.Run("Not in wishlist", func(t *testing.T) {
+        repo, svc := newWishlistService(Config{})
+
+        httpStatus, err := svc.MoveWishlistToCart(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+        assert.Error(t, err)
+        assert.Equal(t, http.StatusNotFound, httpStatus)
+        assert.Empty(t, repo.carts)
+    })
+
+    t.Run("Stock is held", func(t *testing.T) {
+        repo, svc := newWishlistService(Config{ReservationEnabled: true, ReservationTTL: time.Minute})
+        svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 7, ProductID: 1})
+
+        httpStatus, err := svc.MoveWishlistToCart(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+        assert.NoError(t, err)
+        assert.Equal(t, http.StatusOK, httpStatus)
+        assert.Len(t, repo.reservations, 1)
+        assert.Equal(t, 3, repo.variants[1].Stock)
+    })
+
+    t.Run("Insufficient stock keep the wishlist", func(t *testing.T) {
+        repo, svc := newWishlistService(Config{ReservationEnabled: true, ReservationTTL: time.Minute})
+        svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 7, ProductID: 1, VariantID: 2})
+
+        httpStatus, err := svc.MoveWishlistToCart(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1, VariantID: 2})
+        assert.Equal(t, modelProduct.ErrInsufficientStock, err)
+        assert.Equal(t, http.StatusBadRequest, httpStatus)
+        assert.Len(t, repo.wishlists, 1)
+        assert.Empty(t, repo.carts)
+    })
+}
+
+func TestService_MoveCartToWishlist(t *testing.T) {
+    repo, svc := newWishlistService(Config{ReservationEnabled: true, ReservationTTL: time.Minute})
+    _, err := svc.AddToCart(context.Background(), presenterCart.CartRequest{MemberID: 7, ProductID: 1, Quantity: 2})
+    assert.NoError(t, err)
+    assert.Equal(t, 3, repo.variants[1].Stock)
+
+    httpStatus, err := svc.MoveCartToWishlist(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1})
+    assert.NoError(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Empty(t, repo.carts)
+    assert.Equal(t, modelCart.ReservationReleased, repo.reservations[0].Status)
+    assert.Equal(t, 5, repo.variants[1].Stock)
+    assert.Equal(t, []modelCart.Wishlist{{ID: 1, MemberID: 7, ProductID: 1, VariantID: 1}}, repo.wishlists)
+
+    httpStatus, err = svc.MoveCartToWishlist(context.Background(), presenterCart.WishlistMoveRequest{MemberID: 7, ProductID: 1})
+    assert.Error(t, err)
+    assert.Equal(t, http.StatusNotFound, httpStatus)
+}
+
+func TestService_NotifyBackInStock(t *testing.T) {
+    push := &fakeFirebase{}
+    notifier := &fakeNotifier{}
+    repo, svc := newWishlistService(Config{Push: newPushSender(push, nil), Notifier: notifier})
+    repo.devices = []modelMember.Device{{MemberID: 7, Token: "token-a"}, {MemberID: 8, Token: "token-b"}}
+    repo.optOuts = map[int]bool{8: true}
+    svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 7, ProductID: 1, VariantID: 2})
+    svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 8, ProductID: 1, VariantID: 2})
+    svc.AddWishlist(context.Background(), presenterCart.WishlistRequest{MemberID: 9, ProductID: 1})
+
+    notified, httpStatus, err := svc.NotifyBackInStock(context.Background(), modelOutbox.StockPayload{ProductID: 1, VariantID: 2, StockAfter: 4})
+    assert.NoError(t, err)
+    assert.Equal(t, http.StatusOK, httpStatus)
+    assert.Equal(t, 2, notified)
+
+    // Member 8 opted out of the wishlist category on every channel
+    assert.Len(t, push.sent, 1)
+    assert.Equal(t, "token-a", push.sent[0].Token)
+    assert.Equal(t, "Shirt from your wishlist is back in stock, get it before it runs out.", push.sent[0].Notification.Body)
+    assert.Equal(t, "2", push.sent[0].Data["variant_id"])
+    assert.Len(t, notifier.messages, 1)
+    assert.Equal(t, "7", notifier.messages[0].Recipient)
+
+    notified, _, err = svc.NotifyBackInStock(context.Background(), modelOutbox.StockPayload{ProductID: 2, VariantID: 3, StockAfter: 1})
+    assert.NoError(t, err)
+    assert.Zero(t, notified)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE `transaction` DROP COLUMN `variant_id`;
+ALTER TABLE `cart` DROP COLUMN `variant_id`;
+DROP TABLE product_variant;
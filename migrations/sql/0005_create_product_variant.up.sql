@@ -0,0 +1,25 @@
+-- store.product_variant definition
+
+CREATE TABLE IF NOT EXISTS `product_variant` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `product_id` int(11) NOT NULL,
+                           `sku` varchar(100) NOT NULL,
+                           `size` varchar(50) NOT NULL DEFAULT '',
+                           `colour` varchar(50) NOT NULL DEFAULT '',
+                           `price_override` float DEFAULT NULL,
+                           `stock` int(11) NOT NULL,
+                           `is_active` tinyint(1) NOT NULL DEFAULT 1,
+                           PRIMARY KEY (`id`),
+                           UNIQUE KEY `product_variant_sku_unique` (`sku`),
+                           KEY `product_variant_product_id_index` (`product_id`)
+);
+
+-- Existing products become single-variant products
+INSERT INTO `product_variant` (`product_id`, `sku`, `stock`, `is_active`)
+SELECT `id`, CONCAT('SKU-', `id`), `stock`, 1 FROM `product`;
+
+ALTER TABLE `cart` ADD COLUMN `variant_id` int(11) NOT NULL DEFAULT 0 AFTER `product_id`;
+UPDATE `cart` c JOIN `product_variant` v ON v.`product_id` = c.`product_id` SET c.`variant_id` = v.`id`;
+
+ALTER TABLE `transaction` ADD COLUMN `variant_id` int(11) NOT NULL DEFAULT 0 AFTER `product_id`;
+UPDATE `transaction` t JOIN `product_variant` v ON v.`product_id` = t.`product_id` SET t.`variant_id` = v.`id`;
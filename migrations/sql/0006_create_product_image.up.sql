@@ -0,0 +1,11 @@
+-- store.product_image definition
+
+CREATE TABLE IF NOT EXISTS `product_image` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `product_id` int(11) NOT NULL,
+                           `s3_name` varchar(255) NOT NULL,
+                           `position` int(11) NOT NULL DEFAULT 0,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           KEY `product_image_product_id_index` (`product_id`, `position`)
+);
@@ -0,0 +1 @@
+DROP TABLE stock_movement;
\ No newline at end of file
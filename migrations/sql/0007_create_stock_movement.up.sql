@@ -0,0 +1,20 @@
+-- store.stock_movement definition, append only
+
+CREATE TABLE IF NOT EXISTS `stock_movement` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `product_id` int(11) NOT NULL,
+                           `variant_id` int(11) NOT NULL,
+                           `type` varchar(20) NOT NULL,
+                           `quantity` int(11) NOT NULL,
+                           `stock_after` int(11) NOT NULL,
+                           `reference` varchar(100) NOT NULL DEFAULT '',
+                           `note` varchar(255) NOT NULL DEFAULT '',
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           KEY `stock_movement_product_id_index` (`product_id`, `id`),
+                           KEY `stock_movement_variant_id_index` (`variant_id`, `id`)
+);
+
+-- Opening balance, so the sum of movements of a variant is its stock
+INSERT INTO `stock_movement` (`product_id`, `variant_id`, `type`, `quantity`, `stock_after`, `note`)
+SELECT `product_id`, `id`, 'adjustment', `stock`, `stock`, 'Opening balance' FROM `product_variant`;
@@ -0,0 +1 @@
+DROP TABLE cart_reservation;
\ No newline at end of file
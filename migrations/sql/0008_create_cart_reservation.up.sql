@@ -0,0 +1,17 @@
+-- store.cart_reservation definition, stock hold of cart item. Held stock is taken out of variant stock
+-- through the stock_movement ledger (type reservation) and given back on release, expiry or checkout.
+
+CREATE TABLE IF NOT EXISTS `cart_reservation` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `member_id` int(11) NOT NULL,
+                           `product_id` int(11) NOT NULL,
+                           `variant_id` int(11) NOT NULL,
+                           `quantity` int(11) NOT NULL,
+                           `status` varchar(20) NOT NULL,
+                           `expired_at` datetime NOT NULL,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           `updated_date` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           KEY `cart_reservation_status_index` (`status`, `expired_at`),
+                           KEY `cart_reservation_member_id_index` (`member_id`, `variant_id`, `status`)
+);
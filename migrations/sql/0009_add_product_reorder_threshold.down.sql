@@ -0,0 +1,2 @@
+ALTER TABLE `product` DROP COLUMN `low_stock_alerted`;
+ALTER TABLE `product` DROP COLUMN `reorder_threshold`;
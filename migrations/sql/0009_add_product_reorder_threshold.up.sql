@@ -0,0 +1,5 @@
+-- Low stock alert: reorder_threshold 0 disable the alert, low_stock_alerted avoid alert on every sale
+-- while the product is low. It is reset once stock is back to the threshold.
+
+ALTER TABLE `product` ADD COLUMN `reorder_threshold` int(11) NOT NULL DEFAULT 0 AFTER `stock`;
+ALTER TABLE `product` ADD COLUMN `low_stock_alerted` tinyint(1) NOT NULL DEFAULT 0 AFTER `reorder_threshold`;
@@ -0,0 +1,18 @@
+-- store.outbox_event definition, domain event written in the same transaction as the change.
+-- outbox-relay publish pending event to kafka, failed event is retried at available_at.
+
+CREATE TABLE IF NOT EXISTS `outbox_event` (
+                           `id` bigint(20) NOT NULL AUTO_INCREMENT,
+                           `topic` varchar(100) NOT NULL,
+                           `event_type` varchar(50) NOT NULL,
+                           `aggregate_id` varchar(100) NOT NULL,
+                           `payload` json NOT NULL,
+                           `status` varchar(20) NOT NULL,
+                           `attempts` int(11) NOT NULL DEFAULT 0,
+                           `last_error` varchar(255) NOT NULL DEFAULT '',
+                           `available_at` datetime NOT NULL,
+                           `sent_at` datetime DEFAULT NULL,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           KEY `outbox_event_status_index` (`status`, `available_at`)
+);
@@ -0,0 +1,14 @@
+-- store.member_device definition, FCM registration token of member device. Token is unique, it moves to the
+-- member who logged in last on the device. Token rejected by FCM is deleted.
+
+CREATE TABLE IF NOT EXISTS `member_device` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `member_id` int(11) NOT NULL,
+                           `token` varchar(255) NOT NULL,
+                           `platform` varchar(20) NOT NULL,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           `updated_date` timestamp NOT NULL DEFAULT current_timestamp() ON UPDATE current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           UNIQUE KEY `member_device_token_unique` (`token`),
+                           KEY `member_device_member_id_index` (`member_id`)
+);
@@ -0,0 +1 @@
+DROP TABLE member_notification_opt_out;
@@ -0,0 +1,9 @@
+-- store.member_notification_opt_out definition, notification category the member doesn't want on the channel
+
+CREATE TABLE IF NOT EXISTS `member_notification_opt_out` (
+                           `member_id` int(11) NOT NULL,
+                           `channel` varchar(20) NOT NULL,
+                           `category` varchar(50) NOT NULL,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`member_id`, `channel`, `category`)
+);
@@ -0,0 +1,15 @@
+-- store.notification_log definition, result of every email, in-app and push notification send.
+
+CREATE TABLE IF NOT EXISTS `notification_log` (
+                           `id` bigint(20) NOT NULL AUTO_INCREMENT,
+                           `channel` varchar(20) NOT NULL,
+                           `event_type` varchar(50) NOT NULL,
+                           `recipient` varchar(255) NOT NULL,
+                           `subject` varchar(255) NOT NULL DEFAULT '',
+                           `status` varchar(20) NOT NULL,
+                           `attempts` int(11) NOT NULL DEFAULT 0,
+                           `last_error` varchar(255) NOT NULL DEFAULT '',
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           KEY `notification_log_recipient_index` (`recipient`, `created_date`)
+);
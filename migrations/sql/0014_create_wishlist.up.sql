@@ -0,0 +1,12 @@
+-- store.wishlist definition, product saved for later by member. Member is notified when the variant is back in stock.
+
+CREATE TABLE IF NOT EXISTS `wishlist` (
+                           `id` int(11) NOT NULL AUTO_INCREMENT,
+                           `member_id` int(11) NOT NULL,
+                           `product_id` int(11) NOT NULL,
+                           `variant_id` int(11) NOT NULL,
+                           `created_date` timestamp NOT NULL DEFAULT current_timestamp(),
+                           PRIMARY KEY (`id`),
+                           UNIQUE KEY `wishlist_member_variant_unique` (`member_id`, `variant_id`),
+                           KEY `wishlist_variant_id_index` (`variant_id`)
+);
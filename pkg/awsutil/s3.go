@@ -30,9 +30,22 @@ import (
     awstrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/aws/aws-sdk-go/aws"
 )
 
+const (
+    DefaultPresignExpiry = 15 * time.Minute
+    MaxPresignExpiry     = 7 * 24 * time.Hour // Limit of S3 signature v4
+)
+
 type AWSService struct {
-    client *s3.S3
-    bucket string
+    client        *s3.S3
+    bucket        string
+    presignExpiry time.Duration
+}
+
+// PresignOptions constraint of presigned url. Zero value use default expiry without constraint
+type PresignOptions struct {
+    Expiry        time.Duration // Default presign expiry of the service when empty
+    ContentType   string        // PUT only. Client must upload with the same Content-Type header
+    ContentLength int64         // PUT only. Client must upload with the same Content-Length header
 }
 
 func NewAWSService(awsAccessKey, awsSecretKey, awsRegion, bucket string) (*AWSService, error) {
@@ -63,18 +76,91 @@ func (s AWSService) Head(folder string, filename string) (*s3.HeadObjectOutput,
 }
 
 func (s AWSService) Get(folder string, filename string) (string, error) {
+    url, err := s.PresignGet(folder, filename, PresignOptions{})
+    if err != nil {
+        return err.Error(), err
+    }
+
+    return url, nil
+}
+
+// SetPresignExpiry change default expiry of presigned url, ex: from AWS_PRESIGN_EXPIRY
+func (s *AWSService) SetPresignExpiry(expiry time.Duration) error {
+    if expiry <= 0 || expiry > MaxPresignExpiry {
+        return fmt.Errorf("presign expiry must be between 1s and %s", MaxPresignExpiry)
+    }
+    s.presignExpiry = expiry
+    return nil
+}
+
+// PresignGet time-limited url to download private object
+func (s AWSService) PresignGet(folder string, filename string, opts PresignOptions) (string, error) {
+    expiry, err := s.getPresignExpiry(opts)
+    if err != nil {
+        return "", err
+    }
+
     req, _ := s.client.GetObjectRequest(&s3.GetObjectInput{
         Bucket: aws.String(s.bucket),
         Key:    aws.String(folder + "/" + filename),
     })
 
-    // Create the pre-signed url with an expiry
-    url, err := req.Presign(15 * time.Minute)
+    return req.Presign(expiry)
+}
+
+// PresignPut time-limited url to upload object directly to S3.
+// Client must send the returned headers, they are part of the signature.
+func (s AWSService) PresignPut(folder string, filename string, opts PresignOptions) (string, http.Header, error) {
+    expiry, err := s.getPresignExpiry(opts)
     if err != nil {
-        return err.Error(), err
+        return "", nil, err
     }
 
-    return url, nil
+    input := &s3.PutObjectInput{
+        Bucket: aws.String(s.bucket),
+        Key:    aws.String(folder + "/" + filename),
+    }
+    if opts.ContentType != "" {
+        input.ContentType = aws.String(opts.ContentType)
+    }
+    if opts.ContentLength > 0 {
+        input.ContentLength = aws.Int64(opts.ContentLength)
+    }
+
+    req, _ := s.client.PutObjectRequest(input)
+    url, signedHeaders, err := req.PresignRequest(expiry)
+    if err != nil {
+        return "", nil, err
+    }
+
+    // Signed header keys are lower case, canonicalize for http.Header.Get
+    headers := http.Header{}
+    for key, values := range signedHeaders {
+        for _, value := range values {
+            headers.Add(key, value)
+        }
+    }
+
+    return url, headers, nil
+}
+
+// PresignExpiry default expiry of presigned url
+func (s AWSService) PresignExpiry() time.Duration {
+    if s.presignExpiry == 0 {
+        return DefaultPresignExpiry
+    }
+    return s.presignExpiry
+}
+
+func (s AWSService) getPresignExpiry(opts PresignOptions) (time.Duration, error) {
+    expiry := opts.Expiry
+    if expiry == 0 {
+        expiry = s.PresignExpiry()
+    }
+    if expiry < 0 || expiry > MaxPresignExpiry {
+        return 0, fmt.Errorf("presign expiry must be between 1s and %s", MaxPresignExpiry)
+    }
+    return expiry, nil
 }
 
 // PutRawFile   ContentType: "binary/octet-stream",
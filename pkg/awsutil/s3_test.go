@@ -0,0 +1,56 @@
+package awsutil
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSService_PresignGet(t *testing.T) {
+	svc, _ := NewAwsMockService()
+
+	t.Run("Use default expiry", func(t *testing.T) {
+		presigned, err := svc.PresignGet("member/1", "avatar.png", PresignOptions{})
+		assert.Nil(t, err)
+
+		u, _ := url.Parse(presigned)
+		assert.Equal(t, "/mock/member/1/avatar.png", u.Path)
+		assert.Equal(t, "900", u.Query().Get("X-Amz-Expires"))
+	})
+
+	t.Run("Use custom expiry", func(t *testing.T) {
+		presigned, err := svc.PresignGet("member/1", "avatar.png", PresignOptions{Expiry: time.Minute})
+		assert.Nil(t, err)
+
+		u, _ := url.Parse(presigned)
+		assert.Equal(t, "60", u.Query().Get("X-Amz-Expires"))
+	})
+
+	t.Run("Reject expiry more than 7 days", func(t *testing.T) {
+		_, err := svc.PresignGet("member/1", "avatar.png", PresignOptions{Expiry: 8 * 24 * time.Hour})
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAWSService_PresignPut(t *testing.T) {
+	svc, _ := NewAwsMockService()
+	assert.Nil(t, svc.SetPresignExpiry(5*time.Minute))
+
+	t.Run("Sign content type and length", func(t *testing.T) {
+		presigned, headers, err := svc.PresignPut("member/1", "avatar.png",
+			PresignOptions{ContentType: "image/png", ContentLength: 1024})
+		assert.Nil(t, err)
+
+		u, _ := url.Parse(presigned)
+		assert.Equal(t, "300", u.Query().Get("X-Amz-Expires"))
+		assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), "content-type")
+		assert.Contains(t, u.Query().Get("X-Amz-SignedHeaders"), "content-length")
+		assert.Equal(t, "image/png", headers.Get("Content-Type"))
+	})
+
+	t.Run("Reject invalid default expiry", func(t *testing.T) {
+		assert.NotNil(t, svc.SetPresignExpiry(0))
+	})
+}
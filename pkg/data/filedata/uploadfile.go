@@ -30,6 +30,22 @@ var units = []string{"B", "KB", "MB", "GB", "TB"}
 var AllowedFile = []string{"pdf", "jpg", "png", "xlsx", "xls", "jpeg", "docx", "doc", "csv", "txt", "ppt", "pptx"}
 var ImageExt = []string{"jpg", "png", "jpeg"}
 var ImageType = []string{"image/png", "image/jpeg"}
+
+// ContentTypeByExt content type of AllowedFile, ex: to sign direct upload to S3
+var ContentTypeByExt = map[string]string{
+    "pdf":  "application/pdf",
+    "jpg":  "image/jpeg",
+    "jpeg": "image/jpeg",
+    "png":  "image/png",
+    "xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+    "xls":  "application/vnd.ms-excel",
+    "docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+    "doc":  "application/msword",
+    "csv":  "text/csv",
+    "txt":  "text/plain",
+    "ppt":  "application/vnd.ms-powerpoint",
+    "pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
 var documentPath = map[int]string{
     OtherDocument: "./assets/upload/image/",
 }
@@ -193,13 +209,23 @@ func (u UploadFile) GetFile() multipart.File {
     return u.File
 }
 
-// GetFileBody get buffer for S3
+// GetFileBody get buffer for S3. Always read from the beginning, file might be read before (ex: copy to Path)
 func (u UploadFile) GetFileBody() *bytes.Reader {
     buffer := make([]byte, u.Size)
-    u.File.Read(buffer)
+    if _, err := u.File.Seek(0, io.SeekStart); err == nil {
+        io.ReadFull(u.File, buffer)
+    }
     return bytes.NewReader(buffer)
 }
 
+// Clean remove local copy of the upload file
+func (u UploadFile) Clean() error {
+    if u.Path == "" {
+        return nil
+    }
+    return os.Remove(u.Path)
+}
+
 func (u UploadFile) GetName() string {
     return u.Name
 }
@@ -235,6 +261,18 @@ func (u UploadFile) Println() {
 }
 
 // Static public functions
+
+// NewS3Name unique S3 name of the file, reject path traversal file name
+func NewS3Name(filename string) (string, error) {
+    fileRe := regexp.MustCompile(`^\.*/|/|\.\..*$`)
+    if fileRe.MatchString(filename) {
+        return "", fmt.Errorf("File name is invalid. Please use valid character: %s", filename)
+    }
+
+    filename = strings.ToLower(filename)
+    return fmt.Sprintf("%s_%s_%s",
+        time.Now().Format("20060102150405"), strings.ReplaceAll(uuid.New().String(), "-", ""), filename), nil
+}
 func GetFileSizeText(size int64) string {
     var result float64
     var text string
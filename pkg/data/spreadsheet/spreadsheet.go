@@ -0,0 +1,161 @@
+// Package spreadsheet read and write rows of csv or xlsx file.
+//
+// Usage:
+//
+//	rows, err := spreadsheet.Read(file.GetFileBody(), file.GetExtension())
+//
+//	w, err := spreadsheet.NewWriter(&buf, spreadsheet.XLSX)
+//	err = w.Write([]string{"name", "price"})
+//	err = w.Flush()
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	CSV  = "csv"
+	XLSX = "xlsx"
+
+	SheetName = "Sheet1"
+)
+
+var ErrUnsupportedFormat = errors.New("format must be csv or xlsx")
+
+// Format normalize file extension / format, return ErrUnsupportedFormat when it is not csv or xlsx
+func Format(ext string) (string, error) {
+	format := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(ext), "."))
+	if format != CSV && format != XLSX {
+		return "", ErrUnsupportedFormat
+	}
+	return format, nil
+}
+
+// ContentType of the format for download response
+func ContentType(format string) string {
+	if format == XLSX {
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	return "text/csv"
+}
+
+// Read all rows of csv or the first sheet of xlsx. Empty rows are kept so row number match the file.
+func Read(r io.Reader, ext string) ([][]string, error) {
+	format, err := Format(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == CSV {
+		return readCSV(r)
+	}
+
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sheet := f.GetSheetName(0)
+	if sheet == "" {
+		return nil, errors.New("xlsx has no sheet")
+	}
+	return f.GetRows(sheet)
+}
+
+// readCSV csv.Reader skip blank lines, put them back as empty row
+func readCSV(r io.Reader) ([][]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rows [][]string
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		for len(rows) < line-1 {
+			rows = append(rows, []string{})
+		}
+		rows = append(rows, row)
+	}
+}
+
+// Writer write rows one by one, Flush must be called once all rows are written
+type Writer interface {
+	Write(row []string) error
+	Flush() error
+}
+
+// NewWriter create csv or xlsx writer
+func NewWriter(w io.Writer, ext string) (Writer, error) {
+	format, err := Format(ext)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == CSV {
+		return &csvWriter{writer: csv.NewWriter(w)}, nil
+	}
+
+	f := excelize.NewFile()
+	stream, err := f.NewStreamWriter(SheetName)
+	if err != nil {
+		return nil, err
+	}
+	return &xlsxWriter{out: w, file: f, stream: stream}, nil
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWriter) Write(row []string) error {
+	return w.writer.Write(row)
+}
+
+func (w *csvWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func (w *xlsxWriter) Write(row []string) error {
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(row))
+	for i, value := range row {
+		values[i] = value
+	}
+	return w.stream.SetRow(cell, values)
+}
+
+func (w *xlsxWriter) Flush() error {
+	defer w.file.Close()
+
+	if err := w.stream.Flush(); err != nil {
+		return err
+	}
+	return w.file.Write(w.out)
+}
@@ -0,0 +1,66 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	format, err := Format(".XLSX")
+	assert.Nil(t, err)
+	assert.Equal(t, XLSX, format)
+
+	_, err = Format("pdf")
+	assert.ErrorIs(t, err, ErrUnsupportedFormat)
+}
+
+func TestReadWrite(t *testing.T) {
+	rows := [][]string{
+		{"name", "category", "price"},
+		{"Shirt, long sleeve", "fashion", "100"},
+		{"Cap", "fashion", "25.5"},
+	}
+
+	for _, format := range []string{CSV, XLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, format)
+			assert.Nil(t, err)
+			for _, row := range rows {
+				assert.Nil(t, w.Write(row))
+			}
+			assert.Nil(t, w.Flush())
+
+			result, err := Read(&buf, format)
+			assert.Nil(t, err)
+			assert.Equal(t, rows, result)
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	t.Run("Csv with uneven column", func(t *testing.T) {
+		result, err := Read(strings.NewReader("name,price\nShirt\n"), CSV)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"name", "price"}, {"Shirt"}}, result)
+	})
+
+	t.Run("Csv keep blank line", func(t *testing.T) {
+		result, err := Read(strings.NewReader("name\n\nShirt\n"), CSV)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]string{{"name"}, {}, {"Shirt"}}, result)
+	})
+
+	t.Run("Invalid xlsx", func(t *testing.T) {
+		_, err := Read(strings.NewReader("name,price"), XLSX)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		_, err := Read(strings.NewReader("name,price"), "txt")
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+	})
+}
@@ -2,12 +2,12 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/sirupsen/logrus"
 	"os"
 
-	sqltrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/database/sql"
-	sqlxtrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/jmoiron/sqlx"
+	"store-api/pkg/tracing"
 
 	// mysql driver
 	"github.com/go-sql-driver/mysql"
@@ -28,11 +28,20 @@ func NewMySQLRepository(host, uname, pass, dbname string, port int) (*MySQLClien
 		tz = "" // Second phase, split db, set UTC for MariaDB
 	}
 
-	sqltrace.Register("mysql", &mysql.MySQLDriver{})
-
 	dsnFormat := fmt.Sprintf("%s:%s@(%s:%d)/%s?parseTime=true%s", uname, pass, host, port, dbname, tz)
-	db, err := sqlxtrace.Connect("mysql", dsnFormat)
+	config, err := mysql.ParseDSN(dsnFormat)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid MySQL DSN")
+	}
+	connector, err := mysql.NewConnector(config)
 	if err != nil {
+		return nil, errors.Wrap(err, "Invalid MySQL DSN")
+	}
+
+	// Query is traced by the tracer set with tracing.SetTracer
+	db := sqlx.NewDb(sql.OpenDB(tracing.WrapConnector(connector, "mysql")), "mysql")
+	if err = db.Ping(); err != nil {
+		_ = db.Close()
 		logrus.Error(fmt.Sprintf("Cannot connect to MySQL. %v", err))
 		return nil, errors.Wrap(err, "Cannot connect to MySQL")
 	}
@@ -45,7 +54,7 @@ func NewMySQLRepository(host, uname, pass, dbname string, port int) (*MySQLClien
 
 //Ref https://github.com/jmoiron/sqlx
 func (r MySQLClientRepository) GetOne(ctx context.Context, query string, target interface{}, args ...interface{}) error {
-	err := r.DB.Get(target, query, args...)
+	err := r.DB.GetContext(ctx, target, query, args...)
 	if err != nil {
 		return err
 	}
@@ -53,7 +62,7 @@ func (r MySQLClientRepository) GetOne(ctx context.Context, query string, target
 }
 
 func (r MySQLClientRepository) GetAll(ctx context.Context, query string, target interface{}, args ...interface{}) error {
-	err := r.DB.Select(target, query, args...)
+	err := r.DB.SelectContext(ctx, target, query, args...)
 	if err != nil {
 		return err
 	}
@@ -37,15 +37,57 @@ type Error struct {
 	Message string
 	Err     error
 	Type    ErrorType
+	Field   string      // Request field, for validation errors
+	Value   interface{} // Rejected value of the field
 	File    string
 	Stack   string
 }
 
+// ErrorResponse is the error body of web and mobile response, see Error.ToResponse()
+type ErrorResponse struct {
+	Code    string      `json:"code"` // Machine readable, see ErrorType.Code()
+	Message string      `json:"message"`
+	Field   string      `json:"field,omitempty"`
+	Value   interface{} `json:"value,omitempty"`
+}
+
+var errorCodes = map[ErrorType]string{
+	ErrorTypeDefault:              "error",
+	ErrorTypeMissing:              "missing",
+	ErrorTypeParse:                "parse",
+	ErrorTypeNotFound:             "not_found",
+	ErrorTypeSql:                  "sql",
+	ErrorTypeInvalid:              "invalid",
+	ErrorTypeBadRequest:           "bad_request",
+	ErrorTypeFile:                 "file",
+	ErrorTypePanic:                "panic",
+	ErrorTypeUnauthorized:         "unauthorized",
+	ErrorTypeUnprocesseableEntity: "unprocessable_entity",
+}
+
+// Code machine readable code of the error type
+func (t ErrorType) Code() string {
+	if code, ok := errorCodes[t]; ok {
+		return code
+	}
+	return errorCodes[ErrorTypeDefault]
+}
+
 // Error implements the error interface.
 func (msg Error) Error() string {
 	return msg.Err.Error()
 }
 
+// ToResponse error body for the client, no File and Stack
+func (msg *Error) ToResponse() ErrorResponse {
+	return ErrorResponse{
+		Code:    msg.Type.Code(),
+		Message: msg.Err.Error(),
+		Field:   msg.Field,
+		Value:   msg.Value,
+	}
+}
+
 func (msg *Error) IsType(flags ErrorType) bool {
 	return msg.Type == flags
 }
@@ -178,19 +220,27 @@ func NewBadRequestError(name string, err error) *Error {
 }
 
 func NewParseError(name string) *Error {
-	return generateError(name, fmt.Errorf("Parse :%s fail", name), ErrorTypeParse)
+	e := generateError(name, fmt.Errorf("Parse :%s fail", name), ErrorTypeParse)
+	e.Field = name
+	return e
 }
 
 func NewMissingQueryError(name string) *Error {
-	return generateError(name, fmt.Errorf("Missing query: %s in URI", name), ErrorTypeMissing)
+	e := generateError(name, fmt.Errorf("Missing query: %s in URI", name), ErrorTypeMissing)
+	e.Field = name
+	return e
 }
 
 func NewMissingKeyError(name string) *Error {
-	return generateError(name, fmt.Errorf("Missing key: %s in JSON body", name), ErrorTypeMissing)
+	e := generateError(name, fmt.Errorf("Missing key: %s in JSON body", name), ErrorTypeMissing)
+	e.Field = name
+	return e
 }
 
 func NewMissingError(name string) *Error {
-	return generateError(name, fmt.Errorf("Missing required parameter: %s", name), ErrorTypeMissing)
+	e := generateError(name, fmt.Errorf("Missing required parameter: %s", name), ErrorTypeMissing)
+	e.Field = name
+	return e
 }
 
 func NewMalformedJSONError(err error) *Error {
@@ -209,6 +259,15 @@ func NewUnprocessableEntityError(err error) *Error {
 	return generateError("Unprocessable Entity", err, ErrorTypeUnprocesseableEntity)
 }
 
+// NewFieldError validation error of the request field, field is the json name eg. items[0].quantity
+// errType: ErrorTypeMissing for required field, ErrorTypeInvalid for the others
+func NewFieldError(field string, value interface{}, errType ErrorType, err error) *Error {
+	e := generateError(field, err, errType)
+	e.Field = field
+	e.Value = value
+	return e
+}
+
 func NewBadRequest(err error) *Error {
 	return &Error{
 		Name: "error",
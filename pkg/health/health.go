@@ -0,0 +1,191 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded" // Non critical dependency is down, the service still serve request
+	StatusDown     = "down"
+
+	DefaultTimeout = time.Second
+)
+
+// CheckFunc return error when the dependency is not usable, ctx has the timeout of the check
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	critical bool
+	timeout  time.Duration
+	fn       CheckFunc
+}
+
+// CheckResult of one dependency
+type CheckResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Latency  string `json:"latency"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Result of readiness, status is down when a critical dependency is down
+type Result struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Health run the registered dependency checks for /readyz
+type Health struct {
+	checks []check
+}
+
+func New() *Health {
+	return &Health{}
+}
+
+// Register dependency check, timeout <= 0 use DefaultTimeout. Failing non critical check mark the service degraded
+func (h *Health) Register(name string, critical bool, timeout time.Duration, fn CheckFunc) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	h.checks = append(h.checks, check{name: name, critical: critical, timeout: timeout, fn: fn})
+}
+
+// Check run every check concurrently, each with its own timeout
+func (h *Health) Check(ctx context.Context) Result {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		result = Result{Status: StatusUp, Checks: make(map[string]CheckResult, len(h.checks))}
+	)
+	for _, c := range h.checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+			checkResult := c.run(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			result.Checks[c.name] = checkResult
+			if checkResult.Status == StatusDown {
+				if c.critical {
+					result.Status = StatusDown
+				} else if result.Status == StatusUp {
+					result.Status = StatusDegraded
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	return result
+}
+
+func (c check) run(ctx context.Context) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				errc <- fmt.Errorf("panic: %v", r)
+			}
+		}()
+		errc <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done(): // Check which ignore ctx
+		err = ctx.Err()
+	}
+
+	result := CheckResult{Status: StatusUp, Critical: c.critical, Latency: time.Since(start).Round(time.Millisecond).String()}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler /healthz, the process is alive. No dependency is checked
+func (h *Health) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		writeJSON(rw, http.StatusOK, Result{Status: StatusUp, Checks: map[string]CheckResult{}})
+	})
+}
+
+// ReadinessHandler /readyz, 200 when up or degraded, 503 when down
+func (h *Health) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		result := h.Check(r.Context())
+
+		httpStatus := http.StatusOK
+		if result.Status == StatusDown {
+			httpStatus = http.StatusServiceUnavailable
+		}
+		writeJSON(rw, httpStatus, result)
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, httpStatus int, result Result) {
+	rw.Header().Set("Content-Type", "application/json")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(httpStatus)
+	_ = json.NewEncoder(rw).Encode(result)
+}
+
+// Pinger *sql.DB, *sqlx.DB
+type Pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// SQLCheck ping the database
+func SQLCheck(db Pinger) CheckFunc {
+	return db.PingContext
+}
+
+// RedisPinger redisser.RedisClient
+type RedisPinger interface {
+	Ping(ctx context.Context) (string, error)
+}
+
+// RedisCheck ping the redis server
+func RedisCheck(client RedisPinger) CheckFunc {
+	return func(ctx context.Context) error {
+		_, err := client.Ping(ctx)
+		return err
+	}
+}
+
+// TCPCheck at least one address is reachable, for kafka brokers
+func TCPCheck(addresses []string) CheckFunc {
+	return func(ctx context.Context) error {
+		if len(addresses) == 0 {
+			return fmt.Errorf("no address")
+		}
+
+		var (
+			dialer  net.Dialer
+			lastErr error
+		)
+		for _, address := range addresses {
+			conn, err := dialer.DialContext(ctx, "tcp", address)
+			if err == nil {
+				return conn.Close()
+			}
+			lastErr = err
+		}
+		return lastErr
+	}
+}
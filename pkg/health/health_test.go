@@ -0,0 +1,137 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func up(ctx context.Context) error {
+	return nil
+}
+
+func down(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func hang(ctx context.Context) error {
+	time.Sleep(time.Second) // Ignore ctx
+	return nil
+}
+
+func serveReadiness(h *Health) (*httptest.ResponseRecorder, Result) {
+	rw := httptest.NewRecorder()
+	h.ReadinessHandler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	result := Result{}
+	_ = json.Unmarshal(rw.Body.Bytes(), &result)
+	return rw, result
+}
+
+func TestHealth_Readiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   func(h *Health)
+		status     string
+		httpStatus int
+	}{
+		{
+			name: "All up",
+			register: func(h *Health) {
+				h.Register("mysql", true, 0, up)
+				h.Register("redis", false, 0, up)
+			},
+			status:     StatusUp,
+			httpStatus: http.StatusOK,
+		},
+		{
+			name: "Non critical down",
+			register: func(h *Health) {
+				h.Register("mysql", true, 0, up)
+				h.Register("redis", false, 0, down)
+			},
+			status:     StatusDegraded,
+			httpStatus: http.StatusOK,
+		},
+		{
+			name: "Critical down",
+			register: func(h *Health) {
+				h.Register("mysql", true, 0, down)
+				h.Register("redis", false, 0, down)
+			},
+			status:     StatusDown,
+			httpStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:       "No check",
+			register:   func(h *Health) {},
+			status:     StatusUp,
+			httpStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := New()
+			tt.register(h)
+
+			rw, result := serveReadiness(h)
+			assert.Equal(t, tt.httpStatus, rw.Code)
+			assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+			assert.Equal(t, tt.status, result.Status)
+			assert.Len(t, result.Checks, len(h.checks))
+		})
+	}
+}
+
+func TestHealth_CheckResult(t *testing.T) {
+	h := New()
+	h.Register("mysql", true, 0, up)
+	h.Register("kafka", false, 20*time.Millisecond, hang)
+	h.Register("redis", false, 0, func(ctx context.Context) error { panic("nil client") })
+
+	start := time.Now()
+	result := h.Check(context.Background())
+	assert.Less(t, int64(time.Since(start)), int64(500*time.Millisecond), "Check must not wait the hanging check")
+
+	assert.Equal(t, StatusDegraded, result.Status)
+	assert.Equal(t, CheckResult{Status: StatusUp, Critical: true, Latency: result.Checks["mysql"].Latency}, result.Checks["mysql"])
+	assert.Equal(t, StatusDown, result.Checks["kafka"].Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), result.Checks["kafka"].Error)
+	assert.Equal(t, "panic: nil client", result.Checks["redis"].Error)
+}
+
+func TestHealth_Liveness(t *testing.T) {
+	h := New()
+	h.Register("mysql", true, 0, down)
+
+	rw := httptest.NewRecorder()
+	h.LivenessHandler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.JSONEq(t, `{"status":"up","checks":{}}`, rw.Body.String())
+}
+
+func TestTCPCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	closedAddr := closed.Addr().String()
+	_ = closed.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	assert.Nil(t, TCPCheck([]string{closedAddr, listener.Addr().String()})(ctx))
+	_ = listener.Close()
+	assert.NotNil(t, TCPCheck([]string{closedAddr})(ctx))
+	assert.NotNil(t, TCPCheck(nil)(ctx))
+}
@@ -1,15 +1,19 @@
 package httpclient
 
 import (
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"path/filepath"
 	"reflect"
 	"time"
 
 	"github.com/parnurzeal/gorequest"
 	"github.com/pkg/errors"
+
+	"store-api/pkg/tracing"
 )
 
 //New creates client Factory
@@ -39,19 +43,65 @@ type Client interface {
 	Put(string, interface{}, map[string]string, interface{}) (int, error)
 	PostMultipart(string, interface{}, map[string]string, interface{}, interface{}) (int, error)
 	PostMultiparts(string, interface{}, map[string]string, interface{}, []string) (int, error)
+	// WithContext request span is child of the span in ctx, trace context is sent in the request header
+	WithContext(ctx context.Context) Client
 }
 
 type client struct {
+	ctx context.Context
+}
+
+func (g client) WithContext(ctx context.Context) Client {
+	return client{ctx: ctx}
+}
+
+// startSpan client span of the request, headers is copied with the trace context
+func (g client) startSpan(method, rawURL string, headers map[string]string) (tracing.Span, map[string]string) {
+	ctx := g.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	resource := method
+	opts := []tracing.SpanOption{tracing.WithKind(tracing.KindClient), tracing.WithTag(tracing.TagHTTPMethod, method)}
+	if u, err := url.Parse(rawURL); err == nil {
+		// Query is not tagged, it may have a token
+		resource = method + " " + u.Host
+		opts = append(opts, tracing.WithTag(tracing.TagHTTPURL, u.Scheme+"://"+u.Host+u.Path))
+	}
+	span, ctx := tracing.StartSpan(ctx, "http.request", append(opts, tracing.WithResource(resource))...)
+
+	carrier := make(tracing.MapCarrier, len(headers)+2)
+	for k, v := range headers {
+		carrier[k] = v
+	}
+	tracing.Inject(ctx, carrier)
+	return span, carrier
+}
+
+// finishSpan 5xx response is an error of the span
+func finishSpan(span tracing.Span, resp gorequest.Response, errs []error) {
+	if len(errs) > 0 {
+		span.SetError(errs[0])
+	} else if resp != nil {
+		span.SetTag(tracing.TagHTTPStatusCode, resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError {
+			span.SetError(errors.New(resp.Status))
+		}
+	}
+	span.Finish()
 }
 
 func (g client) GetForUsersFilteredByBranchAndOrganizationAndName(url string, headers map[string]string, dest interface{}) (int, error) {
 	getRequest := gorequest.New()
 	getRequest.Timeout(1 * time.Minute)
+	span, headers := g.startSpan(http.MethodGet, url, headers)
 	agent := getRequest.Get(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 	resp, bytes, errs := agent.EndBytes()
+	finishSpan(span, resp, errs)
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
 	}
@@ -64,11 +114,13 @@ func (g client) GetForUsersFilteredByBranchAndOrganizationAndName(url string, he
 
 func (g client) Get(url string, headers map[string]string, dest interface{}) (int, error) {
 	getRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodGet, url, headers)
 	agent := getRequest.Get(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 	resp, bytes, errs := agent.EndBytes()
+	finishSpan(span, resp, errs)
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
 	}
@@ -86,11 +138,13 @@ func (g client) PostJSON(string, string, map[string]string, interface{}) (int, e
 // url encoded sample https://golang.cafe/blog/how-to-make-http-url-form-encoded-request-golang.html
 func (g client) PostEncodedForm(url string, data interface{}, headers map[string]string, dest interface{}) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPut, url, headers)
 	agent := postRequest.Put(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 	resp, bytes, errs := agent.Type("urlencoded").Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
 	}
@@ -103,11 +157,13 @@ func (g client) PostEncodedForm(url string, data interface{}, headers map[string
 // Post JSON
 func (g client) Post(url string, data interface{}, headers map[string]string, dest interface{}) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPost, url, headers)
 	agent := postRequest.Post(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 	resp, bytes, errs := agent.Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
 	}
@@ -120,12 +176,14 @@ func (g client) Post(url string, data interface{}, headers map[string]string, de
 // Put JSON
 func (g client) Put(url string, data interface{}, headers map[string]string, dest interface{}) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPut, url, headers)
 	agent := postRequest.Put(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 
 	resp, bytes, errs := agent.Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
 	}
@@ -137,11 +195,13 @@ func (g client) Put(url string, data interface{}, headers map[string]string, des
 
 func (g client) PostForm(url string, data interface{}, headers map[string]string, dest interface{}) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPost, url, headers)
 	agent := postRequest.Post(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
 	}
 	resp, bytes, errs := agent.Type("form-data").Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
@@ -156,6 +216,7 @@ func (g client) PostForm(url string, data interface{}, headers map[string]string
 
 func (g client) PostMultipart(url string, data interface{}, headers map[string]string, dest interface{}, file interface{}) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPost, url, headers)
 	agent := postRequest.Post(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
@@ -165,6 +226,7 @@ func (g client) PostMultipart(url string, data interface{}, headers map[string]s
 	}
 
 	resp, bytes, errs := agent.Type("multipart").Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
@@ -179,6 +241,7 @@ func (g client) PostMultipart(url string, data interface{}, headers map[string]s
 
 func (g client) PostMultiparts(url string, data interface{}, headers map[string]string, dest interface{}, file []string) (int, error) {
 	postRequest := gorequest.New()
+	span, headers := g.startSpan(http.MethodPost, url, headers)
 	agent := postRequest.Post(url)
 	for k, v := range headers {
 		agent = agent.Set(k, v)
@@ -191,6 +254,7 @@ func (g client) PostMultiparts(url string, data interface{}, headers map[string]
 	}
 
 	resp, bytes, errs := agent.Type("multipart").Send(data).EndBytes()
+	finishSpan(span, resp, errs)
 
 	if len(errs) > 0 {
 		return http.StatusInternalServerError, errs[0]
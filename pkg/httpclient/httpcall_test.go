@@ -1,6 +1,7 @@
 package httpclient
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"store-api/pkg/tracing"
 )
 
 type Pokemon struct {
@@ -336,3 +339,37 @@ func TestHTTPCall(t *testing.T) {
 
 	})
 }
+
+func TestClient_WithContext(t *testing.T) {
+	tracer := tracing.NewMemoryTracer()
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"message":"maintenance"}`))
+	}))
+	defer server.Close()
+
+	span, ctx := tracing.StartSpan(context.Background(), "notification.send")
+	headers := map[string]string{"Content-Type": "application/json"}
+
+	var resp map[string]interface{}
+	code, err := New().CreateClient().WithContext(ctx).Post(server.URL+"/v1/messages?token=secret", nil, headers, &resp)
+	span.Finish()
+
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Len(t, headers, 1, "Caller headers are not modified")
+	assert.Contains(t, traceparent, span.TraceID())
+
+	spans := tracer.Spans("http.request")
+	assert.Len(t, spans, 1)
+	assert.Equal(t, tracing.KindClient, spans[0].Kind)
+	assert.Equal(t, server.URL+"/v1/messages", spans[0].Tags[tracing.TagHTTPURL])
+	assert.Equal(t, int64(http.StatusServiceUnavailable), spans[0].Tags[tracing.TagHTTPStatusCode])
+	assert.NotEmpty(t, spans[0].Error)
+	assert.Equal(t, tracer.Spans("notification.send")[0].SpanID, spans[0].ParentID)
+}
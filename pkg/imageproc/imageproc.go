@@ -0,0 +1,198 @@
+// Package imageproc decode uploaded image, strip metadata and store resized renditions.
+//
+// Usage:
+//
+//	file, err := ctx.GetUploadFile("image")
+//	result, err := imageproc.New(awsService).ProcessAndStore("product", file)
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"store-api/pkg/data/filedata"
+
+	"github.com/h2non/filetype"
+	"golang.org/x/image/draw"
+)
+
+const (
+	DefaultJPEGQuality = 85
+	DefaultMaxPixels   = 50_000_000 // Avoid decompression bomb, ~ 7000 x 7000
+)
+
+var (
+	ErrUnsupportedImage = errors.New("image must be jpg or png")
+	ErrImageTooLarge    = errors.New("image dimension is too large")
+)
+
+// Rendition resized version of the image, MaxSize is the longest side in pixel
+type Rendition struct {
+	Name    string
+	MaxSize int
+}
+
+var DefaultRenditions = []Rendition{
+	{Name: "thumbnail", MaxSize: 150},
+	{Name: "medium", MaxSize: 600},
+	{Name: "large", MaxSize: 1200},
+}
+
+// Storage of the renditions, implemented by awsutil.AWSService
+type Storage interface {
+	Put(folder string, filename string, body *bytes.Reader, filetype string) error
+}
+
+// Output encoded rendition
+type Output struct {
+	Name        string
+	Folder      string
+	FileName    string
+	ContentType string
+	Width       int
+	Height      int
+	Body        []byte
+}
+
+// Key S3 key of stored rendition
+func (o Output) Key() string {
+	return o.Folder + "/" + o.FileName
+}
+
+type Processor struct {
+	storage     Storage
+	renditions  []Rendition
+	jpegQuality int
+	maxPixels   int
+}
+
+// New creates image processor, use DefaultRenditions when renditions is empty
+func New(storage Storage, renditions ...Rendition) *Processor {
+	if len(renditions) == 0 {
+		renditions = DefaultRenditions
+	}
+	return &Processor{
+		storage:     storage,
+		renditions:  renditions,
+		jpegQuality: DefaultJPEGQuality,
+		maxPixels:   DefaultMaxPixels,
+	}
+}
+
+// Process decode image by magic bytes and encode every rendition. Encoded rendition has no EXIF or other metadata,
+// JPEG orientation is applied to the pixels before it is dropped.
+func (p *Processor) Process(r io.Reader) ([]Output, error) {
+	body, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	kind, err := filetype.Match(body)
+	if err != nil {
+		return nil, err
+	}
+	if kind.MIME.Value != "image/jpeg" && kind.MIME.Value != "image/png" {
+		return nil, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+	if config.Width*config.Height > p.maxPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	var img image.Image
+	if kind.MIME.Value == "image/jpeg" {
+		img, err = jpeg.Decode(bytes.NewReader(body))
+		if err == nil {
+			img = applyOrientation(img, jpegOrientation(body))
+		}
+	} else {
+		img, err = png.Decode(bytes.NewReader(body))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedImage, err)
+	}
+
+	outputs := make([]Output, 0, len(p.renditions))
+	for _, rendition := range p.renditions {
+		resized := resize(img, rendition.MaxSize)
+
+		var buf bytes.Buffer
+		if kind.MIME.Value == "image/jpeg" {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: p.jpegQuality})
+		} else {
+			err = png.Encode(&buf, resized)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, Output{
+			Name:        rendition.Name,
+			ContentType: kind.MIME.Value,
+			Width:       resized.Bounds().Dx(),
+			Height:      resized.Bounds().Dy(),
+			Body:        buf.Bytes(),
+		})
+	}
+
+	return outputs, nil
+}
+
+// ProcessAndStore process upload file and put each rendition to {folder}/{rendition}/{S3Name}
+func (p *Processor) ProcessAndStore(folder string, file *filedata.UploadFile) ([]Output, error) {
+	outputs, err := p.Process(file.GetFileBody())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range outputs {
+		outputs[i].Folder = folder + "/" + outputs[i].Name
+		outputs[i].FileName = file.GetS3Name()
+
+		err = p.storage.Put(outputs[i].Folder, outputs[i].FileName, bytes.NewReader(outputs[i].Body), outputs[i].ContentType)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return outputs, nil
+}
+
+// resize keep aspect ratio, never upscale
+func resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if maxSize <= 0 || (width <= maxSize && height <= maxSize) {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Copy(dst, image.Point{}, img, bounds, draw.Src, nil)
+		return dst
+	}
+
+	if width >= height {
+		height = max(1, height*maxSize/width)
+		width = maxSize
+	} else {
+		width = max(1, width*maxSize/height)
+		height = maxSize
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,172 @@
+package imageproc
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"store-api/pkg/data/filedata"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type putCall struct {
+	folder   string
+	filename string
+	filetype string
+}
+
+type fakeStorage struct {
+	calls []putCall
+}
+
+func (s *fakeStorage) Put(folder string, filename string, body *bytes.Reader, filetype string) error {
+	s.calls = append(s.calls, putCall{folder: folder, filename: filename, filetype: filetype})
+	return nil
+}
+
+type memoryFile struct {
+	*bytes.Reader
+}
+
+func (memoryFile) Close() error { return nil }
+
+func newPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	assert.Nil(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+// newJPEGWithExif encode jpeg and insert APP1 segment with orientation tag and a fake GPS marker
+func newJPEGWithExif(t *testing.T, width, height int, orientation uint16) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	// Mark top left corner so rotation can be verified
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.Nil(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 100}))
+	body := buf.Bytes()
+
+	tiff := []byte{
+		'M', 'M', 0x00, 0x2A, 0x00, 0x00, 0x00, 0x08, // Header, IFD at offset 8
+		0x00, 0x01, // One entry
+		0x01, 0x12, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, byte(orientation >> 8), byte(orientation), 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, // No next IFD
+	}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	segment = append(segment, []byte("GPS-SECRET")...)
+	length := len(segment) + 2
+	app1 := append([]byte{0xFF, 0xE1, byte(length >> 8), byte(length)}, segment...)
+
+	result := append([]byte{}, body[:2]...)
+	result = append(result, app1...)
+	return append(result, body[2:]...)
+}
+
+func TestProcessor_Process(t *testing.T) {
+	processor := New(&fakeStorage{})
+
+	t.Run("Resize keep aspect ratio", func(t *testing.T) {
+		outputs, err := processor.Process(bytes.NewReader(newPNG(t, 2400, 1200)))
+		assert.Nil(t, err)
+		assert.Len(t, outputs, 3)
+
+		expected := map[string][2]int{"thumbnail": {150, 75}, "medium": {600, 300}, "large": {1200, 600}}
+		for _, output := range outputs {
+			assert.Equal(t, "image/png", output.ContentType)
+			assert.Equal(t, expected[output.Name], [2]int{output.Width, output.Height}, output.Name)
+
+			config, err := png.DecodeConfig(bytes.NewReader(output.Body))
+			assert.Nil(t, err)
+			assert.Equal(t, output.Width, config.Width)
+		}
+	})
+
+	t.Run("Never upscale", func(t *testing.T) {
+		outputs, err := processor.Process(bytes.NewReader(newPNG(t, 100, 300)))
+		assert.Nil(t, err)
+
+		for _, output := range outputs {
+			if output.Name == "thumbnail" {
+				assert.Equal(t, [2]int{50, 150}, [2]int{output.Width, output.Height})
+			} else {
+				assert.Equal(t, [2]int{100, 300}, [2]int{output.Width, output.Height})
+			}
+		}
+	})
+
+	t.Run("Strip exif and apply orientation", func(t *testing.T) {
+		body := newJPEGWithExif(t, 40, 20, 6)
+		assert.Equal(t, 6, jpegOrientation(body))
+
+		outputs, err := processor.Process(bytes.NewReader(body))
+		assert.Nil(t, err)
+
+		for _, output := range outputs {
+			assert.Equal(t, "image/jpeg", output.ContentType)
+			assert.False(t, bytes.Contains(output.Body, []byte("Exif")))
+			assert.False(t, bytes.Contains(output.Body, []byte("GPS-SECRET")))
+			// Rotated 90 degree clockwise
+			assert.Equal(t, [2]int{20, 40}, [2]int{output.Width, output.Height})
+		}
+
+		// Red corner moved from top left to top right
+		img, err := jpeg.Decode(bytes.NewReader(outputs[2].Body))
+		assert.Nil(t, err)
+		r, _, _, _ := img.At(17, 2).RGBA()
+		assert.Greater(t, r, uint32(0x8000))
+	})
+
+	t.Run("Reject by magic bytes", func(t *testing.T) {
+		_, err := processor.Process(bytes.NewReader([]byte("name,price\nshirt,100")))
+		assert.ErrorIs(t, err, ErrUnsupportedImage)
+
+		// Valid gif is still not allowed
+		_, err = processor.Process(bytes.NewReader([]byte("GIF89a\x01\x00\x01\x00")))
+		assert.ErrorIs(t, err, ErrUnsupportedImage)
+	})
+
+	t.Run("Reject truncated image", func(t *testing.T) {
+		body := newPNG(t, 10, 10)
+		_, err := processor.Process(bytes.NewReader(body[:20]))
+		assert.ErrorIs(t, err, ErrUnsupportedImage)
+	})
+
+	t.Run("Reject too large dimension", func(t *testing.T) {
+		small := New(&fakeStorage{})
+		small.maxPixels = 50
+		_, err := small.Process(bytes.NewReader(newPNG(t, 10, 10)))
+		assert.ErrorIs(t, err, ErrImageTooLarge)
+	})
+}
+
+func TestProcessor_ProcessAndStore(t *testing.T) {
+	storage := &fakeStorage{}
+	processor := New(storage, Rendition{Name: "small", MaxSize: 10}, Rendition{Name: "big", MaxSize: 100})
+
+	body := newPNG(t, 50, 50)
+	file := &filedata.UploadFile{
+		Name:   "front.png",
+		S3Name: "20220101000000_front.png",
+		Size:   int64(len(body)),
+		Ext:    "png",
+		File:   memoryFile{bytes.NewReader(body)},
+	}
+
+	outputs, err := processor.ProcessAndStore("product", file)
+	assert.Nil(t, err)
+	assert.Len(t, outputs, 2)
+	assert.Equal(t, "product/small/20220101000000_front.png", outputs[0].Key())
+
+	assert.Equal(t, []putCall{
+		{folder: "product/small", filename: "20220101000000_front.png", filetype: "image/png"},
+		{folder: "product/big", filename: "20220101000000_front.png", filetype: "image/png"},
+	}, storage.calls)
+}
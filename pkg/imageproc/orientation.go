@@ -0,0 +1,113 @@
+package imageproc
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// jpegOrientation read EXIF orientation tag (0x0112) of JPEG, return 1 (normal) when it is missing or malformed
+func jpegOrientation(body []byte) int {
+	if len(body) < 4 || body[0] != 0xFF || body[1] != 0xD8 {
+		return 1
+	}
+
+	for i := 2; i+4 <= len(body); {
+		if body[i] != 0xFF {
+			return 1
+		}
+		marker := body[i+1]
+		// Start of scan, no more metadata segment after this
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		length := int(binary.BigEndian.Uint16(body[i+2 : i+4]))
+		if length < 2 || i+2+length > len(body) {
+			return 1
+		}
+		segment := body[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:8]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset : offset+2]))
+	for n := 0; n < count; n++ {
+		entry := offset + 2 + n*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:entry+2]) == 0x0112 {
+			orientation := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if orientation < 1 || orientation > 8 {
+				return 1
+			}
+			return orientation
+		}
+	}
+
+	return 1
+}
+
+// applyOrientation rotate / flip the pixels so the image look the same once EXIF is dropped
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	dstWidth, dstHeight := width, height
+	if orientation >= 5 {
+		dstWidth, dstHeight = height, width
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // Mirror horizontal
+				dx, dy = width-1-x, y
+			case 3: // Rotate 180
+				dx, dy = width-1-x, height-1-y
+			case 4: // Mirror vertical
+				dx, dy = x, height-1-y
+			case 5: // Transpose
+				dx, dy = y, x
+			case 6: // Rotate 90 clockwise
+				dx, dy = height-1-y, x
+			case 7: // Transverse
+				dx, dy = height-1-y, width-1-x
+			case 8: // Rotate 270 clockwise
+				dx, dy = y, width-1-x
+			}
+			dst.Set(dx, dy, img.At(bounds.Min.X+x, bounds.Min.Y+y))
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,106 @@
+// Package event is the envelope of the messages published by the store.
+//
+// Every message has the same envelope (id, type, version, occurred_at, source) whatever the serializer is,
+// so consumer can find the payload schema by type and version before decoding it:
+//
+//	env := event.New("order.created", 1, "store-api", payload)
+//	err := event.Publish(ctx, publisher, "store.orders", env, event.JSON, messaging.WithKey(orderId))
+//
+//	registry := event.NewRegistry()
+//	registry.Register("order.created", 1, func() interface{} { return &OrderCreated{} })
+//	env, err := registry.Decode(event.ContentType(msg.Headers), msg.Value)
+//
+// Payload schema change which is not backward compatible must be published with a new version, consumer keep
+// decoding the old version until every producer is migrated.
+package event
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"store-api/pkg/messaging"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+)
+
+const HeaderContentType = "content_type"
+
+var (
+	ErrUnknownEvent       = errors.New("event type or version is not registered")
+	ErrUnsupportedPayload = errors.New("payload is not supported by the serializer")
+	ErrUnknownContentType = errors.New("unknown event content type")
+)
+
+// Envelope of every event
+type Envelope struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`    // ex: order.created
+	Version    int         `json:"version"` // Schema version of the payload
+	OccurredAt time.Time   `json:"occurred_at"`
+	Source     string      `json:"source"` // Producer service
+	Payload    interface{} `json:"payload"`
+}
+
+// New envelope with random id, occurred now
+func New(eventType string, version int, source string, payload interface{}) Envelope {
+	return Envelope{
+		ID:         uuid.NewString(),
+		Type:       eventType,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+		Source:     source,
+		Payload:    payload,
+	}
+}
+
+// Serializer encode the envelope and its payload
+type Serializer interface {
+	ContentType() string
+	Marshal(env Envelope) ([]byte, error)
+	// Unmarshal decode the envelope, payload is returned encoded so it can be decoded once its type is known
+	Unmarshal(data []byte) (env Envelope, payload []byte, err error)
+	UnmarshalPayload(payload []byte, dest interface{}) error
+}
+
+// Serializers by content type
+var Serializers = map[string]Serializer{
+	JSON.ContentType():     JSON,
+	Protobuf.ContentType(): Protobuf,
+}
+
+// SerializerFor content type, JSON when it is empty (message published before the envelope)
+func SerializerFor(contentType string) (Serializer, error) {
+	if contentType == "" {
+		return JSON, nil
+	}
+	serializer, ok := Serializers[contentType]
+	if !ok {
+		return nil, ErrUnknownContentType
+	}
+	return serializer, nil
+}
+
+// ContentType header of the message
+func ContentType(headers []kafka.Header) string {
+	for _, header := range headers {
+		if header.Key == HeaderContentType {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+// Publish the serialized envelope with event type, schema version and content type headers
+func Publish(ctx context.Context, publisher messaging.KafkaPublisher, topic string, env Envelope, serializer Serializer,
+	opts ...messaging.PublishOption) error {
+	body, err := serializer.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	opts = append(opts, messaging.WithEventType(env.Type, env.Version),
+		messaging.WithHeader(HeaderContentType, serializer.ContentType()))
+	return publisher.Publish(ctx, body, topic, opts...)
+}
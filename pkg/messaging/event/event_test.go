@@ -0,0 +1,141 @@
+package event
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"store-api/pkg/messaging"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type orderCreatedV1 struct {
+	TrxCode string  `json:"trx_code"`
+	Amount  float64 `json:"amount"`
+}
+
+type orderCreatedV2 struct {
+	TrxCode string `json:"trx_code"`
+	Amount  struct {
+		Value    float64 `json:"value"`
+		Currency string  `json:"currency"`
+	} `json:"amount"`
+}
+
+func TestJSON(t *testing.T) {
+	occurredAt := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	env := Envelope{ID: "1", Type: "order.created", Version: 1, OccurredAt: occurredAt, Source: "store-api",
+		Payload: orderCreatedV1{TrxCode: "TRX-1", Amount: 100}}
+
+	body, err := JSON.Marshal(env)
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"id":"1","type":"order.created","version":1,"occurred_at":"2022-01-02T03:04:05.000000006Z",
+"source":"store-api","payload":{"trx_code":"TRX-1","amount":100}}`, string(body))
+
+	decoded, payload, err := JSON.Unmarshal(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "order.created", decoded.Type)
+	assert.True(t, occurredAt.Equal(decoded.OccurredAt))
+
+	var order orderCreatedV1
+	assert.Nil(t, JSON.UnmarshalPayload(payload, &order))
+	assert.Equal(t, orderCreatedV1{TrxCode: "TRX-1", Amount: 100}, order)
+}
+
+func TestProtobuf(t *testing.T) {
+	occurredAt := time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)
+	env := Envelope{ID: "1", Type: "member.renamed", Version: 2, OccurredAt: occurredAt, Source: "store-api",
+		Payload: wrapperspb.String("Budi")}
+
+	body, err := Protobuf.Marshal(env)
+	assert.Nil(t, err)
+
+	// Field added by a newer producer is skipped
+	body = protowire.AppendTag(body, 15, protowire.BytesType)
+	body = protowire.AppendString(body, "new field")
+
+	decoded, payload, err := Protobuf.Unmarshal(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "1", decoded.ID)
+	assert.Equal(t, "member.renamed", decoded.Type)
+	assert.Equal(t, 2, decoded.Version)
+	assert.Equal(t, "store-api", decoded.Source)
+	assert.True(t, occurredAt.Equal(decoded.OccurredAt))
+
+	name := &wrapperspb.StringValue{}
+	assert.Nil(t, Protobuf.UnmarshalPayload(payload, name))
+	assert.Equal(t, "Budi", name.Value)
+
+	_, err = Protobuf.Marshal(Envelope{Type: "order.created", Payload: orderCreatedV1{}})
+	assert.ErrorIs(t, err, ErrUnsupportedPayload)
+
+	_, _, err = Protobuf.Unmarshal([]byte{0x0a, 0x05, 'a'})
+	assert.NotNil(t, err, "Truncated message")
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register("order.created", 1, func() interface{} { return &orderCreatedV1{} })
+	registry.Register("order.created", 2, func() interface{} { return &orderCreatedV2{} })
+	registry.Register("member.renamed", 1, func() interface{} { return &wrapperspb.StringValue{} })
+	assert.Equal(t, []int{1, 2}, registry.Versions("order.created"))
+
+	t.Run("Decode by version", func(t *testing.T) {
+		v1, _ := JSON.Marshal(New("order.created", 1, "store-api", orderCreatedV1{TrxCode: "TRX-1", Amount: 100}))
+		env, err := registry.Decode(JSON.ContentType(), v1)
+		assert.Nil(t, err)
+		assert.Equal(t, &orderCreatedV1{TrxCode: "TRX-1", Amount: 100}, env.Payload)
+
+		v2, _ := JSON.Marshal(New("order.created", 2, "store-api",
+			json.RawMessage(`{"trx_code":"TRX-2","amount":{"value":100,"currency":"IDR"}}`)))
+		env, err = registry.Decode(JSON.ContentType(), v2)
+		assert.Nil(t, err)
+		assert.Equal(t, "IDR", env.Payload.(*orderCreatedV2).Amount.Currency)
+	})
+
+	t.Run("Decode protobuf", func(t *testing.T) {
+		body, _ := Protobuf.Marshal(New("member.renamed", 1, "store-api", wrapperspb.String("Budi")))
+		env, err := registry.Decode(Protobuf.ContentType(), body)
+		assert.Nil(t, err)
+		assert.Equal(t, "Budi", env.Payload.(*wrapperspb.StringValue).Value)
+	})
+
+	t.Run("Unknown version", func(t *testing.T) {
+		body, _ := JSON.Marshal(New("order.created", 3, "store-api", orderCreatedV1{}))
+		env, err := registry.Decode(JSON.ContentType(), body)
+		assert.True(t, errors.Is(err, ErrUnknownEvent))
+		assert.Equal(t, 3, env.Version, "Envelope is still returned")
+	})
+
+	t.Run("Unknown content type", func(t *testing.T) {
+		_, err := registry.Decode("text/xml", []byte("<order/>"))
+		assert.ErrorIs(t, err, ErrUnknownContentType)
+	})
+}
+
+func TestPublish(t *testing.T) {
+	publisher := messaging.NewMemoryPublisher()
+	env := New("order.created", 1, "store-api", orderCreatedV1{TrxCode: "TRX-1"})
+
+	err := Publish(context.Background(), publisher, "store.orders", env, JSON, messaging.WithKey("TRX-1"))
+	assert.Nil(t, err)
+
+	messages := publisher.Messages("store.orders")
+	assert.Len(t, messages, 1)
+	assert.Equal(t, "TRX-1", messages[0].Key)
+	assert.Equal(t, map[string]string{messaging.HeaderEventType: "order.created", messaging.HeaderSchemaVersion: "1",
+		HeaderContentType: "application/json"}, messages[0].Headers)
+
+	registry := NewRegistry()
+	registry.Register("order.created", 1, func() interface{} { return &orderCreatedV1{} })
+	decoded, err := registry.Decode(ContentType([]kafka.Header{{Key: HeaderContentType, Value: []byte("application/json")}}),
+		messages[0].Data.([]byte))
+	assert.Nil(t, err)
+	assert.Equal(t, env.ID, decoded.ID)
+}
@@ -0,0 +1,48 @@
+package event
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JSON serializer, payload is embedded as json object
+var JSON Serializer = jsonSerializer{}
+
+type jsonSerializer struct{}
+
+type jsonEnvelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Source     string          `json:"source"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+func (jsonSerializer) ContentType() string {
+	return "application/json"
+}
+
+func (jsonSerializer) Marshal(env Envelope) ([]byte, error) {
+	return json.Marshal(env)
+}
+
+func (jsonSerializer) Unmarshal(data []byte) (Envelope, []byte, error) {
+	var raw jsonEnvelope
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return Envelope{}, nil, err
+	}
+
+	env := Envelope{
+		ID:         raw.ID,
+		Type:       raw.Type,
+		Version:    raw.Version,
+		OccurredAt: raw.OccurredAt,
+		Source:     raw.Source,
+	}
+	return env, raw.Payload, nil
+}
+
+func (jsonSerializer) UnmarshalPayload(payload []byte, dest interface{}) error {
+	return json.Unmarshal(payload, dest)
+}
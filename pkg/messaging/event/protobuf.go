@@ -0,0 +1,169 @@
+package event
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+)
+
+// Protobuf serializer, envelope is encoded as below, payload must be a proto.Message. Json payload, ex: the outbox
+// event of the store, is rejected by Marshal so it fails at publish and not at the consumer.
+//
+//	message Envelope {
+//	  string id = 1;
+//	  string type = 2;
+//	  int32 version = 3;
+//	  google.protobuf.Timestamp occurred_at = 4;
+//	  string source = 5;
+//	  bytes payload = 6;
+//	}
+var Protobuf Serializer = protobufSerializer{}
+
+const (
+	fieldID protowire.Number = iota + 1
+	fieldType
+	fieldVersion
+	fieldOccurredAt
+	fieldSource
+	fieldPayload
+
+	fieldSeconds = 1 // google.protobuf.Timestamp
+	fieldNanos   = 2
+)
+
+type protobufSerializer struct{}
+
+func (protobufSerializer) ContentType() string {
+	return "application/x-protobuf"
+}
+
+func (protobufSerializer) Marshal(env Envelope) ([]byte, error) {
+	var payload []byte
+	switch value := env.Payload.(type) {
+	case nil:
+	case proto.Message:
+		var err error
+		if payload, err = proto.Marshal(value); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedPayload
+	}
+
+	var b []byte
+	b = appendString(b, fieldID, env.ID)
+	b = appendString(b, fieldType, env.Type)
+	if env.Version != 0 {
+		b = protowire.AppendTag(b, fieldVersion, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(int32(env.Version)))
+	}
+	if !env.OccurredAt.IsZero() {
+		var timestamp []byte
+		timestamp = protowire.AppendTag(timestamp, fieldSeconds, protowire.VarintType)
+		timestamp = protowire.AppendVarint(timestamp, uint64(env.OccurredAt.Unix()))
+		if nanos := env.OccurredAt.Nanosecond(); nanos != 0 {
+			timestamp = protowire.AppendTag(timestamp, fieldNanos, protowire.VarintType)
+			timestamp = protowire.AppendVarint(timestamp, uint64(nanos))
+		}
+		b = protowire.AppendTag(b, fieldOccurredAt, protowire.BytesType)
+		b = protowire.AppendBytes(b, timestamp)
+	}
+	b = appendString(b, fieldSource, env.Source)
+	if len(payload) > 0 {
+		b = protowire.AppendTag(b, fieldPayload, protowire.BytesType)
+		b = protowire.AppendBytes(b, payload)
+	}
+	return b, nil
+}
+
+func (protobufSerializer) Unmarshal(data []byte) (env Envelope, payload []byte, err error) {
+	err = consumeFields(data, func(number protowire.Number, typ protowire.Type, value []byte) error {
+		switch {
+		case number == fieldID && typ == protowire.BytesType:
+			env.ID = string(value)
+		case number == fieldType && typ == protowire.BytesType:
+			env.Type = string(value)
+		case number == fieldVersion && typ == protowire.VarintType:
+			version, _ := protowire.ConsumeVarint(value)
+			env.Version = int(int32(version))
+		case number == fieldOccurredAt && typ == protowire.BytesType:
+			occurredAt, err := consumeTimestamp(value)
+			if err != nil {
+				return err
+			}
+			env.OccurredAt = occurredAt
+		case number == fieldSource && typ == protowire.BytesType:
+			env.Source = string(value)
+		case number == fieldPayload && typ == protowire.BytesType:
+			payload = append([]byte{}, value...)
+		}
+		// Unknown field is skipped, it is added by newer producer
+		return nil
+	})
+	return
+}
+
+func (protobufSerializer) UnmarshalPayload(payload []byte, dest interface{}) error {
+	message, ok := dest.(proto.Message)
+	if !ok {
+		return ErrUnsupportedPayload
+	}
+	return proto.Unmarshal(payload, message)
+}
+
+func appendString(b []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func consumeTimestamp(data []byte) (time.Time, error) {
+	var seconds, nanos int64
+	err := consumeFields(data, func(number protowire.Number, typ protowire.Type, value []byte) error {
+		if typ != protowire.VarintType {
+			return nil
+		}
+		v, _ := protowire.ConsumeVarint(value)
+		switch number {
+		case fieldSeconds:
+			seconds = int64(v)
+		case fieldNanos:
+			nanos = int64(int32(v))
+		}
+		return nil
+	})
+	return time.Unix(seconds, nanos).UTC(), err
+}
+
+// consumeFields call fn with each field, value of bytes field is its content, value of other type is its raw bytes
+func consumeFields(data []byte, fn func(number protowire.Number, typ protowire.Type, value []byte) error) error {
+	for len(data) > 0 {
+		number, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		var value []byte
+		if typ == protowire.BytesType {
+			value, n = protowire.ConsumeBytes(data)
+		} else {
+			n = protowire.ConsumeFieldValue(number, typ, data)
+			if n >= 0 {
+				value = data[:n]
+			}
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		data = data[n:]
+
+		if err := fn(number, typ, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
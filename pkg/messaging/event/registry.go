@@ -0,0 +1,69 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Registry payload type of every event type and version known by the consumer
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]map[int]func() interface{}
+}
+
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[string]map[int]func() interface{})}
+}
+
+// Register payload factory of the event version, factory must return a pointer. Register again replace it.
+func (r *Registry) Register(eventType string, version int, factory func() interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.factories[eventType] == nil {
+		r.factories[eventType] = make(map[int]func() interface{})
+	}
+	r.factories[eventType][version] = factory
+}
+
+// Versions registered for the event type, ascending
+func (r *Registry) Versions(eventType string) []int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var versions []int
+	for version := range r.factories[eventType] {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// Decode message with the serializer of the content type, payload of the returned envelope is the registered type.
+// ErrUnknownEvent is returned when the type or version is not registered, ex: event of a newer producer.
+func (r *Registry) Decode(contentType string, data []byte) (Envelope, error) {
+	serializer, err := SerializerFor(contentType)
+	if err != nil {
+		return Envelope{}, err
+	}
+
+	env, payload, err := serializer.Unmarshal(data)
+	if err != nil {
+		return env, err
+	}
+
+	r.mu.RLock()
+	factory, ok := r.factories[env.Type][env.Version]
+	r.mu.RUnlock()
+	if !ok {
+		return env, fmt.Errorf("%w: %s v%d", ErrUnknownEvent, env.Type, env.Version)
+	}
+
+	dest := factory()
+	if err = serializer.UnmarshalPayload(payload, dest); err != nil {
+		return env, err
+	}
+	env.Payload = dest
+	return env, nil
+}
@@ -0,0 +1,77 @@
+package messaging
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryMessage message kept by MemoryPublisher
+type MemoryMessage struct {
+	Topic   string
+	Key     string
+	Headers map[string]string
+	Data    interface{}
+}
+
+// MemoryPublisher KafkaPublisher keeping messages in memory, for test and local run without broker
+type MemoryPublisher struct {
+	mu       sync.Mutex
+	messages []MemoryMessage
+	err      error
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, data interface{}, topic string, opts ...PublishOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err != nil {
+		return p.err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	msg, err := newMessage(ctx, data, topic, opts...)
+	if err != nil {
+		return err
+	}
+	headers := make(map[string]string, len(msg.Headers))
+	for _, header := range msg.Headers {
+		headers[header.Key] = string(header.Value)
+	}
+	p.messages = append(p.messages, MemoryMessage{Topic: topic, Key: string(msg.Key), Headers: headers, Data: data})
+	return nil
+}
+
+func (p *MemoryPublisher) CreateTopicIfNotExist(topic string) bool {
+	return true
+}
+
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
+
+// SetError make every Publish fail with err until it is set back to nil
+func (p *MemoryPublisher) SetError(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.err = err
+}
+
+// Messages published to the topic, every topic when empty
+func (p *MemoryPublisher) Messages(topic string) []MemoryMessage {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var result []MemoryMessage
+	for _, message := range p.messages {
+		if topic == "" || message.Topic == topic {
+			result = append(result, message)
+		}
+	}
+	return result
+}
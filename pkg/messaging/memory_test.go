@@ -0,0 +1,25 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryPublisher(t *testing.T) {
+	var publisher KafkaPublisher = NewMemoryPublisher()
+	memory := publisher.(*MemoryPublisher)
+
+	assert.Nil(t, publisher.Publish(context.Background(), "created", "store.orders"))
+	assert.Nil(t, publisher.Publish(context.Background(), "changed", "store.stock"))
+	assert.Equal(t, []MemoryMessage{{Topic: "store.orders", Headers: map[string]string{}, Data: "created"}},
+		memory.Messages("store.orders"))
+	assert.Len(t, memory.Messages(""), 2)
+
+	broken := errors.New("broker down")
+	memory.SetError(broken)
+	assert.ErrorIs(t, publisher.Publish(context.Background(), "paid", "store.orders"), broken)
+	assert.Len(t, memory.Messages(""), 2)
+}
@@ -3,79 +3,214 @@ package messaging
 import (
 	"context"
 	"encoding/json"
-	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/pkg/errors"
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
-	"strings"
+
+	"store-api/pkg/tracing"
 )
 
-// Always send to partition 0
-type PartitionZero struct {
+const (
+	HeaderTraceID       = "trace_id"
+	HeaderEventType     = "event_type"
+	HeaderSchemaVersion = "schema_version"
+
+	DefaultBatchSize    = 100
+	DefaultBatchTimeout = 50 * time.Millisecond
+)
+
+// Option of NewKafkaPublisher
+type Option func(w *kafka.Writer)
+
+// WithAsync Publish return before the message is written, error is only logged. Pending messages are
+// written by Close. Don't use it when the caller must know the message is delivered, ex: outbox relay.
+func WithAsync() Option {
+	return func(w *kafka.Writer) {
+		w.Async = true
+	}
+}
+
+// WithBatch messages are written once size is reached or after timeout, value <= 0 keep the default
+func WithBatch(size int, timeout time.Duration) Option {
+	return func(w *kafka.Writer) {
+		if size > 0 {
+			w.BatchSize = size
+		}
+		if timeout > 0 {
+			w.BatchTimeout = timeout
+		}
+	}
+}
+
+// PublishOption of a message
+type PublishOption func(msg *kafka.Message)
+
+// WithKey message with the same key go to the same partition, so they are consumed in order. Ex: order or member id.
+func WithKey(key string) PublishOption {
+	return func(msg *kafka.Message) {
+		msg.Key = []byte(key)
+	}
+}
+
+// WithHeader add message header, ex: HeaderEventType
+func WithHeader(key, value string) PublishOption {
+	return func(msg *kafka.Message) {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
 }
 
-func (cp PartitionZero) Balance(msg kafka.Message, partitions ...int) (partition int) {
-	return 0
+// WithEventType add HeaderEventType and HeaderSchemaVersion
+func WithEventType(eventType string, schemaVersion int) PublishOption {
+	return func(msg *kafka.Message) {
+		WithHeader(HeaderEventType, eventType)(msg)
+		WithHeader(HeaderSchemaVersion, strconv.Itoa(schemaVersion))(msg)
+	}
 }
 
 // NewKafkaPublisher create kafka publisher. Ref: https://github.com/segmentio/kafka-go#writing-to-multiple-topics
-// Mixpanel and MoEngage as well
-func NewKafkaPublisher(brokers string, topic string) *kafkaPublisher {
+// Messages are balanced by key hash, message without key is spread over the partitions.
+func NewKafkaPublisher(brokers string, topic string, opts ...Option) *kafkaPublisher {
 	w := &kafka.Writer{
 		Addr: kafka.TCP(strings.Split(brokers, ",")...),
 		// NOTE: When Topic is not defined here, each Message must define it instead.
-		Balancer: &PartitionZero{},
+		Balancer:     &kafka.Hash{},
+		BatchSize:    DefaultBatchSize,
+		BatchTimeout: DefaultBatchTimeout,
+	}
+	for _, opt := range opts {
+		opt(w)
 	}
-	seg := &kafkaPublisher{writer: w, currentTopic: topic, brokers: brokers}
+	if w.Async {
+		w.Completion = logCompletion
+	}
+	seg := &kafkaPublisher{writer: w, brokers: brokers, dialTopic: dialTopic}
 
 	// Check topic exists before init
 	if brokers != "" {
-		logrus.Infoln("Load Kafka service - ", topic)
-		seg.CreateTopicIfNotExist(topic)
+		logrus.WithFields(logrus.Fields{"topic": topic, "async": w.Async}).Infoln("Load Kafka service")
+		seg.checkTopicExist(topic)
 	} else {
-		logrus.Warning("Brokers not set. Check ULMS_KAFKA_BROKERS .env")
+		logrus.Warning("Brokers not set. Check KAFKA_BROKERS .env")
 	}
 
 	return seg
 }
 
 type KafkaPublisher interface {
-	Publish(ctx context.Context, data interface{}, topic string) error
+	Publish(ctx context.Context, data interface{}, topic string, opts ...PublishOption) error
 	CreateTopicIfNotExist(topic string) bool
+	// Close write pending messages of async publisher
+	Close() error
 }
 
 type kafkaPublisher struct {
-	writer       *kafka.Writer
-	currentTopic string
-	brokers      string
+	writer    *kafka.Writer
+	brokers   string
+	topics    sync.Map // Topic already checked, the outbox relay publish to several topics
+	dialTopic func(host, topic string) error
 }
 
-func (p *kafkaPublisher) Publish(ctx context.Context, data interface{}, topic string) error {
+func (p *kafkaPublisher) Publish(ctx context.Context, data interface{}, topic string, opts ...PublishOption) (err error) {
+	span, ctx := tracing.StartSpan(ctx, "kafka.publish", tracing.WithKind(tracing.KindProducer),
+		tracing.WithResource(topic), tracing.WithTag(tracing.TagMessagingTopic, topic))
+	defer tracing.FinishWithError(span, &err)
+
 	p.checkTopicExist(topic)
 
-	bytes, err := json.Marshal(data)
+	msg, err := newMessage(ctx, data, topic, opts...)
 	if err != nil {
-		return errors.Wrap(err, "error on preparing kafka message")
+		return err
+	}
+
+	err = p.writer.WriteMessages(ctx, msg)
+	entry := logrus.WithFields(logrus.Fields{"topic": topic, "key": string(msg.Key)})
+	if err != nil {
+		entry.WithError(err).Errorln("Kafka publish failed")
+		return err
+	}
+	entry.Debugln("Kafka publish")
+	return nil
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// newMessage json encode data, []byte is already encoded (ex: event.Envelope) and sent as is.
+// Trace id and trace context of the span in ctx are added to the headers.
+func newMessage(ctx context.Context, data interface{}, topic string, opts ...PublishOption) (kafka.Message, error) {
+	bytes, ok := data.([]byte)
+	if !ok {
+		var err error
+		if bytes, err = json.Marshal(data); err != nil {
+			return kafka.Message{}, errors.Wrap(err, "error on preparing kafka message")
+		}
+	}
+
+	msg := kafka.Message{Topic: topic, Value: bytes}
+	if traceID := tracing.TraceID(ctx); traceID != "" {
+		WithHeader(HeaderTraceID, traceID)(&msg)
+	}
+	tracing.Inject(ctx, MessageCarrier{Message: &msg})
+	for _, opt := range opts {
+		opt(&msg)
+	}
+	return msg, nil
+}
+
+// MessageCarrier kafka message headers as tracing.Carrier, ex: traceparent header of W3C trace context
+type MessageCarrier struct {
+	Message *kafka.Message
+}
+
+func (c MessageCarrier) Get(key string) string {
+	for i := len(c.Message.Headers) - 1; i >= 0; i-- {
+		if c.Message.Headers[i].Key == key {
+			return string(c.Message.Headers[i].Value)
+		}
+	}
+	return ""
+}
+
+// Set replace the header with the same key
+func (c MessageCarrier) Set(key, value string) {
+	for i := range c.Message.Headers {
+		if c.Message.Headers[i].Key == key {
+			c.Message.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	WithHeader(key, value)(c.Message)
+}
+
+func (c MessageCarrier) Keys() []string {
+	keys := make([]string, 0, len(c.Message.Headers))
+	for _, header := range c.Message.Headers {
+		keys = append(keys, header.Key)
 	}
+	return keys
+}
 
-	err = p.writer.WriteMessages(ctx,
-		kafka.Message{
-			Topic: topic,
-			Key:   []byte("Key"),
-			Value: bytes,
-		},
-	)
-	fmt.Println("[INFO] Publish to topic:", topic, "- Data", data, err)
-	return err
+func logCompletion(messages []kafka.Message, err error) {
+	if err == nil {
+		return
+	}
+	for _, msg := range messages {
+		logrus.WithFields(logrus.Fields{"topic": msg.Topic, "key": string(msg.Key)}).WithError(err).
+			Errorln("Kafka async publish failed")
+	}
 }
 
 func (p *kafkaPublisher) CreateTopicIfNotExist(topic string) bool {
 
 	hosts := strings.Split(p.brokers, ",")
 	for _, host := range hosts {
-		_, err := kafka.DialLeader(context.Background(), "tcp", host, topic, 0)
-
-		if err != nil {
+		if err := p.dialTopic(host, topic); err != nil {
 			logrus.Error(err)
 			return false
 		}
@@ -85,8 +220,24 @@ func (p *kafkaPublisher) CreateTopicIfNotExist(topic string) bool {
 	return true
 }
 
+// checkTopicExist once per topic, a failed check is tried again by the next publish
 func (p *kafkaPublisher) checkTopicExist(topic string) {
-	if p.currentTopic != topic && p.CreateTopicIfNotExist(topic) {
-		p.currentTopic = topic
+	if p.brokers == "" {
+		return
+	}
+	if _, ok := p.topics.Load(topic); ok {
+		return
+	}
+	if p.CreateTopicIfNotExist(topic) {
+		p.topics.Store(topic, struct{}{})
+	}
+}
+
+// dialTopic connect to the leader of the topic, broker with auto create enabled create the topic
+func dialTopic(host, topic string) error {
+	conn, err := kafka.DialLeader(context.Background(), "tcp", host, topic, 0)
+	if err != nil {
+		return err
 	}
+	return conn.Close()
 }
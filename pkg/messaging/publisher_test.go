@@ -0,0 +1,105 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+
+	"store-api/pkg/tracing"
+)
+
+func TestNewMessage(t *testing.T) {
+	t.Run("Key and headers", func(t *testing.T) {
+		msg, err := newMessage(context.Background(), map[string]int{"id": 1}, "store.orders",
+			WithKey("TRX-1"), WithEventType("order.created", 1), WithHeader("source", "store-api"))
+		assert.Nil(t, err)
+		assert.Equal(t, "store.orders", msg.Topic)
+		assert.Equal(t, []byte("TRX-1"), msg.Key)
+		assert.Equal(t, []byte(`{"id":1}`), msg.Value)
+		assert.Equal(t, []kafka.Header{
+			{Key: HeaderEventType, Value: []byte("order.created")},
+			{Key: HeaderSchemaVersion, Value: []byte("1")},
+			{Key: "source", Value: []byte("store-api")},
+		}, msg.Headers)
+	})
+
+	t.Run("Trace context of the span", func(t *testing.T) {
+		tracer := tracing.NewMemoryTracer()
+		tracing.SetTracer(tracer)
+		defer tracing.SetTracer(nil)
+
+		span, ctx := tracing.StartSpan(context.Background(), "publish")
+		defer span.Finish()
+
+		msg, err := newMessage(ctx, "data", "store.orders")
+		assert.Nil(t, err)
+		assert.Len(t, msg.Headers, 2)
+		assert.Equal(t, HeaderTraceID, msg.Headers[0].Key)
+		assert.Equal(t, span.TraceID(), string(msg.Headers[0].Value))
+
+		consumed, _ := tracer.StartSpan(tracer.Extract(context.Background(), MessageCarrier{Message: &msg}), "consume")
+		assert.Equal(t, span.TraceID(), consumed.TraceID())
+	})
+
+	t.Run("Encoded data", func(t *testing.T) {
+		msg, err := newMessage(context.Background(), []byte{0x0a, 0x01}, "store.orders")
+		assert.Nil(t, err)
+		assert.Equal(t, []byte{0x0a, 0x01}, msg.Value)
+	})
+
+	t.Run("Invalid data", func(t *testing.T) {
+		_, err := newMessage(context.Background(), make(chan int), "store.orders")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestNewKafkaPublisher(t *testing.T) {
+	publisher := NewKafkaPublisher("", "store.orders", WithAsync(), WithBatch(10, 0))
+	assert.True(t, publisher.writer.Async)
+	assert.NotNil(t, publisher.writer.Completion)
+	assert.Equal(t, 10, publisher.writer.BatchSize)
+	assert.IsType(t, &kafka.Hash{}, publisher.writer.Balancer)
+
+	// Same key always go to the same partition
+	balancer := &kafka.Hash{}
+	msg := kafka.Message{Key: []byte("member-7")}
+	assert.Equal(t, balancer.Balance(msg, 0, 1, 2, 3), balancer.Balance(msg, 0, 1, 2, 3))
+	assert.Nil(t, publisher.Close())
+}
+
+func TestKafkaPublisher_CheckTopicExist(t *testing.T) {
+	var dials int32
+	publisher := &kafkaPublisher{brokers: "broker-1:9092,broker-2:9092", dialTopic: func(host, topic string) error {
+		atomic.AddInt32(&dials, 1)
+		if topic == "store.missing" {
+			return errors.New("unknown topic")
+		}
+		return nil
+	}}
+
+	// Outbox relay switch between the topics, each one is checked once on every broker
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			publisher.checkTopicExist([]string{"store.orders", "store.stock"}[i%2])
+		}(i)
+	}
+	wg.Wait()
+	before := atomic.LoadInt32(&dials)
+	assert.GreaterOrEqual(t, before, int32(4))
+
+	publisher.checkTopicExist("store.orders")
+	publisher.checkTopicExist("store.stock")
+	assert.Equal(t, before, atomic.LoadInt32(&dials), "Checked topic is not dialed again")
+
+	publisher.checkTopicExist("store.missing")
+	publisher.checkTopicExist("store.missing")
+	assert.Equal(t, before+2, atomic.LoadInt32(&dials), "Failed check is tried again")
+}
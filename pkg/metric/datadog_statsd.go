@@ -2,22 +2,31 @@ package metric
 
 import (
 	"errors"
+	"time"
 
 	"github.com/DataDog/datadog-go/statsd"
 )
 
-//NewStatsdMonitoring creates new statsd monitoring instance, datadog as default
-func NewStatsdMonitoring(host string) (StatsdMonitoring, error) {
-	client, err := statsd.New(host)
+//NewStatsdMonitoring creates new statsd monitoring instance, datadog as default. Namespace is the prefix of metric name
+func NewStatsdMonitoring(host string, namespace string) (StatsdMonitoring, error) {
+	var options []statsd.Option
+	if namespace != "" {
+		options = append(options, statsd.WithNamespace(namespace+"."))
+	}
+	client, err := statsd.New(host, options...)
 	if err != nil {
 		return nil, err
 	}
 	return &datadogStatsd{client: client}, nil
 }
 
-//StatsdMonitoring contracts
+//StatsdMonitoring contracts. Tag is "key:value", rate is the sample rate from 0 to 1
 type StatsdMonitoring interface {
 	Increment(name string, tags []string, rate float64) error
+	Count(name string, value int64, tags []string, rate float64) error
+	Gauge(name string, value float64, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+	Histogram(name string, value float64, tags []string, rate float64) error
 }
 
 type datadogStatsd struct {
@@ -30,3 +39,31 @@ func (d *datadogStatsd) Increment(name string, tags []string, rate float64) erro
 	}
 	return d.client.Incr(name, tags, rate)
 }
+
+func (d *datadogStatsd) Count(name string, value int64, tags []string, rate float64) error {
+	if d.client == nil {
+		return errors.New("client is not initialized")
+	}
+	return d.client.Count(name, value, tags, rate)
+}
+
+func (d *datadogStatsd) Gauge(name string, value float64, tags []string, rate float64) error {
+	if d.client == nil {
+		return errors.New("client is not initialized")
+	}
+	return d.client.Gauge(name, value, tags, rate)
+}
+
+func (d *datadogStatsd) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	if d.client == nil {
+		return errors.New("client is not initialized")
+	}
+	return d.client.Timing(name, value, tags, rate)
+}
+
+func (d *datadogStatsd) Histogram(name string, value float64, tags []string, rate float64) error {
+	if d.client == nil {
+		return errors.New("client is not initialized")
+	}
+	return d.client.Histogram(name, value, tags, rate)
+}
@@ -0,0 +1,74 @@
+package metric
+
+import (
+	"sync"
+	"time"
+)
+
+// MemoryMetric value recorded by MemoryMonitoring
+type MemoryMetric struct {
+	Type  string // count, gauge, timing or histogram
+	Name  string
+	Value float64 // Timing in seconds
+	Tags  []string
+}
+
+// MemoryMonitoring StatsdMonitoring keeping metrics in memory, for test
+type MemoryMonitoring struct {
+	mu      sync.Mutex
+	metrics []MemoryMetric
+}
+
+func NewMemoryMonitoring() *MemoryMonitoring {
+	return &MemoryMonitoring{}
+}
+
+func (m *MemoryMonitoring) Increment(name string, tags []string, rate float64) error {
+	return m.Count(name, 1, tags, rate)
+}
+
+func (m *MemoryMonitoring) Count(name string, value int64, tags []string, rate float64) error {
+	return m.record("count", name, float64(value), tags)
+}
+
+func (m *MemoryMonitoring) Gauge(name string, value float64, tags []string, rate float64) error {
+	return m.record("gauge", name, value, tags)
+}
+
+func (m *MemoryMonitoring) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	return m.record("timing", name, value.Seconds(), tags)
+}
+
+func (m *MemoryMonitoring) Histogram(name string, value float64, tags []string, rate float64) error {
+	return m.record("histogram", name, value, tags)
+}
+
+func (m *MemoryMonitoring) record(metricType, name string, value float64, tags []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.metrics = append(m.metrics, MemoryMetric{Type: metricType, Name: name, Value: value, Tags: tags})
+	return nil
+}
+
+// Metrics recorded with the name, all metrics when name is empty
+func (m *MemoryMonitoring) Metrics(name string) []MemoryMetric {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var metrics []MemoryMetric
+	for _, metric := range m.metrics {
+		if name == "" || metric.Name == name {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics
+}
+
+// Sum of the recorded values with the name
+func (m *MemoryMonitoring) Sum(name string) float64 {
+	var sum float64
+	for _, metric := range m.Metrics(name) {
+		sum += metric.Value
+	}
+	return sum
+}
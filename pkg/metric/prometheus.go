@@ -0,0 +1,172 @@
+package metric
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var invalidNameChar = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// PrometheusMonitoring StatsdMonitoring exposed on Handler for Prometheus scraping. The metric is created on first use,
+// tag key is the label name so every call of a metric must have the same tag keys. Sample rate is ignored
+type PrometheusMonitoring struct {
+	namespace string
+	registry  *prometheus.Registry
+
+	mu         sync.Mutex
+	counters   map[string]*prometheus.CounterVec
+	gauges     map[string]*prometheus.GaugeVec
+	histograms map[string]*prometheus.HistogramVec
+}
+
+// NewPrometheusMonitoring with go runtime and process metrics, namespace is the prefix of metric name
+func NewPrometheusMonitoring(namespace string) *PrometheusMonitoring {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return &PrometheusMonitoring{
+		namespace:  metricName(namespace),
+		registry:   registry,
+		counters:   make(map[string]*prometheus.CounterVec),
+		gauges:     make(map[string]*prometheus.GaugeVec),
+		histograms: make(map[string]*prometheus.HistogramVec),
+	}
+}
+
+// Handler /metrics in Prometheus exposition format
+func (p *PrometheusMonitoring) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{})
+}
+
+func (p *PrometheusMonitoring) Increment(name string, tags []string, rate float64) error {
+	return p.Count(name, 1, tags, rate)
+}
+
+// Count is the counter {name}_total, value must not be negative
+func (p *PrometheusMonitoring) Count(name string, value int64, tags []string, rate float64) error {
+	if value < 0 {
+		return fmt.Errorf("%s: negative count %d", name, value)
+	}
+	labels, values := parseTags(tags)
+	vec, err := p.counter(metricName(name)+"_total", labels)
+	if err != nil {
+		return err
+	}
+	counter, err := vec.GetMetricWithLabelValues(values...)
+	if err != nil {
+		return err
+	}
+	counter.Add(float64(value))
+	return nil
+}
+
+func (p *PrometheusMonitoring) Gauge(name string, value float64, tags []string, rate float64) error {
+	labels, values := parseTags(tags)
+	vec, err := p.gauge(metricName(name), labels)
+	if err != nil {
+		return err
+	}
+	gauge, err := vec.GetMetricWithLabelValues(values...)
+	if err != nil {
+		return err
+	}
+	gauge.Set(value)
+	return nil
+}
+
+// Timing is the histogram {name}_seconds
+func (p *PrometheusMonitoring) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	return p.observe(metricName(name)+"_seconds", value.Seconds(), tags)
+}
+
+func (p *PrometheusMonitoring) Histogram(name string, value float64, tags []string, rate float64) error {
+	return p.observe(metricName(name), value, tags)
+}
+
+func (p *PrometheusMonitoring) observe(name string, value float64, tags []string) error {
+	labels, values := parseTags(tags)
+	vec, err := p.histogram(name, labels)
+	if err != nil {
+		return err
+	}
+	histogram, err := vec.GetMetricWithLabelValues(values...)
+	if err != nil {
+		return err
+	}
+	histogram.Observe(value)
+	return nil
+}
+
+func (p *PrometheusMonitoring) counter(name string, labels []string) (*prometheus.CounterVec, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if vec, ok := p.counters[name]; ok {
+		return vec, nil
+	}
+	vec := prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: p.namespace, Name: name, Help: name}, labels)
+	if err := p.registry.Register(vec); err != nil {
+		return nil, err
+	}
+	p.counters[name] = vec
+	return vec, nil
+}
+
+func (p *PrometheusMonitoring) gauge(name string, labels []string) (*prometheus.GaugeVec, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if vec, ok := p.gauges[name]; ok {
+		return vec, nil
+	}
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Namespace: p.namespace, Name: name, Help: name}, labels)
+	if err := p.registry.Register(vec); err != nil {
+		return nil, err
+	}
+	p.gauges[name] = vec
+	return vec, nil
+}
+
+func (p *PrometheusMonitoring) histogram(name string, labels []string) (*prometheus.HistogramVec, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if vec, ok := p.histograms[name]; ok {
+		return vec, nil
+	}
+	vec := prometheus.NewHistogramVec(prometheus.HistogramOpts{Namespace: p.namespace, Name: name, Help: name}, labels)
+	if err := p.registry.Register(vec); err != nil {
+		return nil, err
+	}
+	p.histograms[name] = vec
+	return vec, nil
+}
+
+// parseTags "key:value" to label name and value sorted by name, tag without value is "true"
+func parseTags(tags []string) (labels []string, values []string) {
+	sorted := append([]string(nil), tags...)
+	sort.Strings(sorted)
+	for _, tag := range sorted {
+		key, value := tag, "true"
+		if i := strings.Index(tag, ":"); i >= 0 {
+			key, value = tag[:i], tag[i+1:]
+		}
+		labels = append(labels, metricName(key))
+		values = append(values, value)
+	}
+	return labels, values
+}
+
+// metricName "http.request" to "http_request"
+func metricName(name string) string {
+	return invalidNameChar.ReplaceAllString(name, "_")
+}
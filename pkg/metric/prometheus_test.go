@@ -0,0 +1,38 @@
+package metric
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMonitoring(t *testing.T) {
+	p := NewPrometheusMonitoring("store-api")
+
+	tags := []string{"route:/api/v2/products", "method:GET"}
+	assert.Nil(t, p.Increment("http.request", tags, 1))
+	assert.Nil(t, p.Increment("http.request", []string{"method:GET", "route:/api/v2/products"}, 1), "Tag order doesn't matter")
+	assert.Nil(t, p.Count("order.revenue", 150000, nil, 1))
+	assert.Nil(t, p.Gauge("notification.queue", 3, nil, 1))
+	assert.Nil(t, p.Timing("http.request.latency", 250*time.Millisecond, tags, 1))
+	assert.Nil(t, p.Histogram("order.amount", 150000, nil, 1))
+
+	assert.NotNil(t, p.Increment("http.request", []string{"method:GET"}, 1), "Tag keys must be the same")
+	assert.NotNil(t, p.Count("order.revenue", -1, nil, 1))
+
+	rw := httptest.NewRecorder()
+	p.Handler().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := ioutil.ReadAll(rw.Body)
+
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Contains(t, string(body), `store_api_http_request_total{method="GET",route="/api/v2/products"} 2`)
+	assert.Contains(t, string(body), `store_api_order_revenue_total 150000`)
+	assert.Contains(t, string(body), `store_api_notification_queue 3`)
+	assert.Contains(t, string(body), `store_api_http_request_latency_seconds_count{method="GET",route="/api/v2/products"} 1`)
+	assert.Contains(t, string(body), `store_api_order_amount_sum 150000`)
+	assert.Contains(t, string(body), `go_goroutines`)
+}
@@ -0,0 +1,205 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// Params key of CORS config, see CORSConfigFromParams
+const (
+	ParamCORSAllowedOrigins   = "cors-allowed-origins"
+	ParamCORSAllowedMethods   = "cors-allowed-methods"
+	ParamCORSAllowedHeaders   = "cors-allowed-headers"
+	ParamCORSExposedHeaders   = "cors-exposed-headers"
+	ParamCORSAllowCredentials = "cors-allow-credentials"
+	ParamCORSMaxAge           = "cors-max-age"
+)
+
+var (
+	ErrCORSAnyOriginCredentials = errors.New("cors: \"*\" origin is not allowed with credentials")
+	ErrCORSAnyOrigin            = errors.New("cors: \"*\" origin is only allowed on development, list the origins")
+)
+
+// CORSConfig of CORS middleware
+type CORSConfig struct {
+	AllowedOrigins   []string // Exact origin, "*" any origin, or "https://*.example.com" any subdomain
+	AllowedMethods   []string
+	AllowedHeaders   []string // "*" allow any requested header
+	ExposedHeaders   []string
+	AllowCredentials bool // Not allowed with "*" origin, see Validate
+	MaxAge           time.Duration
+}
+
+// DevelopmentCORSConfig allow any origin without credentials, for local storefront. The session is sent in the
+// Authorization header, it doesn't need credentials
+func DevelopmentCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins: []string{"*"},
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"*"},
+		ExposedHeaders: []string{RequestIDHeader},
+		MaxAge:         10 * time.Minute,
+	}
+}
+
+// ProductionCORSConfig allow no origin until it is configured
+func ProductionCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Accept", "Authorization", "Content-Type", RequestIDHeader},
+		ExposedHeaders: []string{RequestIDHeader},
+		MaxAge:         time.Hour,
+	}
+}
+
+// Validate reject "*" origin with credentials, any site could send credentialed request. "*" origin is rejected
+// too when development is false, the origins must be listed.
+func (c CORSConfig) Validate(development bool) error {
+	if !contains(c.AllowedOrigins, "*") {
+		return nil
+	}
+	if c.AllowCredentials {
+		return ErrCORSAnyOriginCredentials
+	}
+	if !development {
+		return ErrCORSAnyOrigin
+	}
+	return nil
+}
+
+// CORSConfigFromParams override the defaults by the non empty params, list is comma separated and max age in seconds
+func CORSConfigFromParams(params map[string]string, defaults CORSConfig) CORSConfig {
+	config := defaults
+	if value := params[ParamCORSAllowedOrigins]; value != "" {
+		config.AllowedOrigins = splitList(value)
+	}
+	if value := params[ParamCORSAllowedMethods]; value != "" {
+		config.AllowedMethods = splitList(strings.ToUpper(value))
+	}
+	if value := params[ParamCORSAllowedHeaders]; value != "" {
+		config.AllowedHeaders = splitList(value)
+	}
+	if value := params[ParamCORSExposedHeaders]; value != "" {
+		config.ExposedHeaders = splitList(value)
+	}
+	if value := params[ParamCORSAllowCredentials]; value != "" {
+		config.AllowCredentials = cast.ToBool(value)
+	}
+	if value := params[ParamCORSMaxAge]; value != "" {
+		config.MaxAge = time.Duration(cast.ToInt(value)) * time.Second
+	}
+	return config
+}
+
+// CORS set the CORS headers of allowed origin and answer the preflight request. Request from not allowed origin
+// is passed without CORS headers, the browser will block it. Preflight from not allowed origin is 403.
+// Config should be checked by Validate, credentials is never allowed for "*" origin.
+func CORS(config CORSConfig) func(http.Handler) http.Handler {
+	allowedMethods := strings.Join(config.AllowedMethods, ", ")
+	allowedHeaders := strings.Join(config.AllowedHeaders, ", ")
+	exposedHeaders := strings.Join(config.ExposedHeaders, ", ")
+	anyHeader := contains(config.AllowedHeaders, "*")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			header := rw.Header()
+			header.Add("Vary", "Origin")
+			isPreflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+			if isPreflight {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+			}
+
+			allowOrigin, allowed := config.allowOrigin(origin)
+			if !allowed {
+				if isPreflight {
+					rw.WriteHeader(http.StatusForbidden)
+					return
+				}
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			header.Set("Access-Control-Allow-Origin", allowOrigin)
+			if config.AllowCredentials && allowOrigin != "*" {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if !isPreflight {
+				if exposedHeaders != "" {
+					header.Set("Access-Control-Expose-Headers", exposedHeaders)
+				}
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			if !contains(config.AllowedMethods, strings.ToUpper(r.Header.Get("Access-Control-Request-Method"))) {
+				rw.WriteHeader(http.StatusForbidden)
+				return
+			}
+			header.Set("Access-Control-Allow-Methods", allowedMethods)
+			if anyHeader {
+				if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+					header.Set("Access-Control-Allow-Headers", requested)
+				}
+			} else if allowedHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", allowedHeaders)
+			}
+			if config.MaxAge > 0 {
+				header.Set("Access-Control-Max-Age", maxAge)
+			}
+			rw.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// allowOrigin value of Access-Control-Allow-Origin for the request origin
+func (c CORSConfig) allowOrigin(origin string) (string, bool) {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(allowed, origin) {
+			return origin, true
+		}
+		if i := strings.Index(allowed, "://*."); i >= 0 {
+			scheme, domain := allowed[:i+3], allowed[i+4:] // "https://", ".example.com"
+			if strings.HasPrefix(strings.ToLower(origin), strings.ToLower(scheme)) &&
+				strings.HasSuffix(strings.ToLower(origin), strings.ToLower(domain)) {
+				return origin, true
+			}
+		}
+	}
+	return "", false
+}
+
+func splitList(value string) []string {
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
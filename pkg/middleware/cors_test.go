@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func serveCORS(config CORSConfig, r *http.Request) (*httptest.ResponseRecorder, bool) {
+	called := false
+	next := http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		called = true
+		rw.WriteHeader(http.StatusOK)
+	})
+
+	rw := httptest.NewRecorder()
+	CORS(config)(next).ServeHTTP(rw, r)
+	return rw, called
+}
+
+func newPreflight(origin, method, headers string) *http.Request {
+	r := httptest.NewRequest(http.MethodOptions, "/api/v2/cart/items", nil)
+	r.Header.Set("Origin", origin)
+	r.Header.Set("Access-Control-Request-Method", method)
+	if headers != "" {
+		r.Header.Set("Access-Control-Request-Headers", headers)
+	}
+	return r
+}
+
+func TestCORS_Production(t *testing.T) {
+	config := CORSConfigFromParams(map[string]string{
+		ParamCORSAllowedOrigins:   "https://shop.example.com, https://*.store.example.com",
+		ParamCORSAllowCredentials: "true",
+		ParamCORSExposedHeaders:   "X-Request-ID",
+	}, ProductionCORSConfig())
+
+	t.Run("Preflight from allowed origin", func(t *testing.T) {
+		rw, called := serveCORS(config, newPreflight("https://shop.example.com", "post", "Content-Type"))
+
+		assert.False(t, called)
+		assert.Equal(t, http.StatusNoContent, rw.Code)
+		assert.Equal(t, "https://shop.example.com", rw.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", rw.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "GET, POST, PUT, DELETE", rw.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, "Accept, Authorization, Content-Type, X-Request-ID", rw.Header().Get("Access-Control-Allow-Headers"))
+		assert.Equal(t, "3600", rw.Header().Get("Access-Control-Max-Age"))
+	})
+
+	t.Run("Subdomain origin", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v2/products", nil)
+		r.Header.Set("Origin", "https://m.store.example.com")
+		rw, called := serveCORS(config, r)
+
+		assert.True(t, called)
+		assert.Equal(t, "https://m.store.example.com", rw.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "X-Request-ID", rw.Header().Get("Access-Control-Expose-Headers"))
+		assert.Equal(t, []string{"Origin"}, rw.Header().Values("Vary"))
+	})
+
+	t.Run("Not allowed origin", func(t *testing.T) {
+		r := httptest.NewRequest(http.MethodGet, "/api/v2/products", nil)
+		r.Header.Set("Origin", "https://store.example.com.evil.io")
+		rw, called := serveCORS(config, r)
+
+		assert.True(t, called)
+		assert.Empty(t, rw.Header().Get("Access-Control-Allow-Origin"))
+
+		rw, called = serveCORS(config, newPreflight("http://shop.example.com", "GET", ""))
+		assert.False(t, called)
+		assert.Equal(t, http.StatusForbidden, rw.Code)
+	})
+
+	t.Run("Not allowed method", func(t *testing.T) {
+		rw, called := serveCORS(config, newPreflight("https://shop.example.com", "PATCH", ""))
+		assert.False(t, called)
+		assert.Equal(t, http.StatusForbidden, rw.Code)
+	})
+
+	t.Run("Request without origin", func(t *testing.T) {
+		rw, called := serveCORS(config, httptest.NewRequest(http.MethodGet, "/api/v2/products", nil))
+		assert.True(t, called)
+		assert.Empty(t, rw.Header().Get("Vary"))
+	})
+
+	t.Run("No origin allowed by default", func(t *testing.T) {
+		rw, _ := serveCORS(ProductionCORSConfig(), newPreflight("https://shop.example.com", "GET", ""))
+		assert.Equal(t, http.StatusForbidden, rw.Code)
+	})
+}
+
+func TestCORS_Development(t *testing.T) {
+	config := CORSConfigFromParams(map[string]string{ParamCORSMaxAge: "60"}, DevelopmentCORSConfig())
+	assert.Equal(t, time.Minute, config.MaxAge)
+	assert.Nil(t, config.Validate(true))
+
+	rw, _ := serveCORS(config, newPreflight("http://localhost:3000", "DELETE", "Authorization, X-Custom"))
+
+	assert.Equal(t, http.StatusNoContent, rw.Code)
+	assert.Equal(t, "*", rw.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rw.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "Authorization, X-Custom", rw.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "60", rw.Header().Get("Access-Control-Max-Age"))
+
+	// Config not validated still doesn't allow credentials for any origin
+	config.AllowCredentials = true
+	r := httptest.NewRequest(http.MethodGet, "/api/v2/products", nil)
+	r.Header.Set("Origin", "https://evil.example.com")
+	rw, _ = serveCORS(config, r)
+	assert.Equal(t, "*", rw.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rw.Header().Get("Access-Control-Allow-Credentials"))
+}
+
+func TestCORSConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		development bool
+		err         error
+	}{
+		{"Listed origin with credentials", map[string]string{ParamCORSAllowedOrigins: "https://shop.example.com",
+			ParamCORSAllowCredentials: "true"}, false, nil},
+		{"Any origin on development", map[string]string{ParamCORSAllowedOrigins: "*"}, true, nil},
+		{"Any origin with credentials", map[string]string{ParamCORSAllowedOrigins: "*",
+			ParamCORSAllowCredentials: "true"}, true, ErrCORSAnyOriginCredentials},
+		{"Any origin outside development", map[string]string{ParamCORSAllowedOrigins: "https://shop.example.com, *"},
+			false, ErrCORSAnyOrigin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.err, CORSConfigFromParams(tt.params, ProductionCORSConfig()).Validate(tt.development))
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"io/ioutil"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"store-api/pkg/helper/realiphelper"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+// RequestIDHeader is taken from the request or generated, and sent back in the response
+const RequestIDHeader = "X-Request-ID"
+
+// Params key of request log config, see LoggingConfigFromParams
+const (
+	ParamLogRequestBodyLimit  = "log-request-body-limit"
+	ParamLogResponseBodyLimit = "log-response-body-limit"
+	ParamLogSampleRate        = "log-sample-rate"
+)
+
+const redacted = "[REDACTED]"
+
+type requestIDKey struct{}
+
+// RequestID of the request, empty outside RequestLogger
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID for the job started by the request, e.g. the log of async process
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// Logger entry with the request ID of ctx, log written while serving the request can be joined with its access log
+func Logger(ctx context.Context) *logrus.Entry {
+	entry := logrus.NewEntry(logrus.StandardLogger())
+	if id := RequestID(ctx); id != "" {
+		return entry.WithField("request_id", id)
+	}
+	return entry
+}
+
+// LoggingConfig of RequestLogger
+type LoggingConfig struct {
+	RequestBodyLimit  int      // Bytes of body in the log, 0 log no body
+	ResponseBodyLimit int      // Bytes of body in the log, 0 log no body
+	SampleRate        float64  // Ratio of successful request in the log, request with status >= 400 is always logged
+	RedactHeaders     []string // Header value replaced by [REDACTED]
+	RedactFields      []string // Any value of JSON field and query param containing one of it is replaced by [REDACTED], case insensitive
+	SkipPaths         []string // Probe is not logged
+}
+
+// DefaultLoggingConfig log every request with 4KB of body
+func DefaultLoggingConfig() LoggingConfig {
+	return LoggingConfig{
+		RequestBodyLimit:  4 << 10,
+		ResponseBodyLimit: 4 << 10,
+		SampleRate:        1,
+		RedactHeaders:     []string{"Authorization", "Cookie", "Set-Cookie", "X-Admin-Key"},
+		RedactFields:      []string{"password", "token", "secret"},
+		SkipPaths:         []string{"/healthz", "/readyz"},
+	}
+}
+
+// LoggingConfigFromParams override the defaults by the non empty params, limit in bytes and sample rate from 0 to 1
+func LoggingConfigFromParams(params map[string]string, defaults LoggingConfig) LoggingConfig {
+	config := defaults
+	if value := params[ParamLogRequestBodyLimit]; value != "" {
+		config.RequestBodyLimit = cast.ToInt(value)
+	}
+	if value := params[ParamLogResponseBodyLimit]; value != "" {
+		config.ResponseBodyLimit = cast.ToInt(value)
+	}
+	if value := params[ParamLogSampleRate]; value != "" {
+		config.SampleRate = cast.ToFloat64(value)
+	}
+	return config
+}
+
+// RequestLogger set the request ID to the request context and response header, then log the request with the
+// redacted header and body. The formatter and output are the logger's, e.g. GELF to graylog
+func RequestLogger(config LoggingConfig, logger *logrus.Logger) func(http.Handler) http.Handler {
+	redactField := redactFieldRegexp(config.RedactFields)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = newRequestID()
+			}
+			rw.Header().Set(RequestIDHeader, id)
+			r = r.WithContext(WithRequestID(r.Context(), id))
+
+			if contains(config.SkipPaths, r.URL.Path) {
+				next.ServeHTTP(rw, r)
+				return
+			}
+
+			var requestBody []byte
+			if config.RequestBodyLimit > 0 && r.Body != nil && r.Body != http.NoBody && isTextContent(r.Header.Get("Content-Type")) {
+				requestBody, _ = ioutil.ReadAll(io.LimitReader(r.Body, int64(config.RequestBodyLimit)))
+				r.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(requestBody), r.Body), Closer: r.Body}
+			}
+
+			recorder := &responseRecorder{ResponseWriter: rw, status: http.StatusOK, limit: config.ResponseBodyLimit}
+			next.ServeHTTP(recorder, r)
+
+			if recorder.status < http.StatusBadRequest && !sampled(config.SampleRate) {
+				return
+			}
+
+			fields := logrus.Fields{
+				"request_id":      id,
+				"clientip":        realiphelper.FromRequest(r),
+				"method":          r.Method,
+				"path":            redactQuery(r.URL, redactField),
+				"statuscode":      recorder.status,
+				"latency":         time.Since(start).Milliseconds(),
+				"bytes":           recorder.size,
+				"request_header":  redactHeader(r.Header, config.RedactHeaders),
+				"response_header": redactHeader(rw.Header(), config.RedactHeaders),
+			}
+			if len(requestBody) > 0 {
+				fields["request"] = redactBody(requestBody, redactField)
+			}
+			if recorder.body.Len() > 0 && isTextContent(rw.Header().Get("Content-Type")) {
+				fields["response"] = redactBody(recorder.body.Bytes(), redactField)
+			}
+
+			entry := logger.WithFields(fields)
+			switch {
+			case recorder.status >= http.StatusInternalServerError:
+				entry.Error("request")
+			case recorder.status >= http.StatusBadRequest:
+				entry.Warn("request")
+			default:
+				entry.Info("request")
+			}
+		})
+	}
+}
+
+// responseRecorder keep the status and the first limit bytes of the body
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	size        int
+	limit       int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	if remaining := r.limit - r.body.Len(); remaining > 0 {
+		if len(b) < remaining {
+			remaining = len(b)
+		}
+		r.body.Write(b[:remaining])
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+// Flush streamed response, e.g. product export
+func (r *responseRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return cast.ToString(time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accept the client ID when it is short and printable, it is written to the log and header
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func sampled(rate float64) bool {
+	return rate >= 1 || (rate > 0 && mathrand.Float64() < rate)
+}
+
+func isTextContent(contentType string) bool {
+	contentType = strings.ToLower(contentType)
+	return strings.Contains(contentType, "json") ||
+		strings.HasPrefix(contentType, "text/") ||
+		strings.Contains(contentType, "x-www-form-urlencoded")
+}
+
+func redactHeader(header http.Header, names []string) map[string]string {
+	result := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		for _, redact := range names {
+			if strings.EqualFold(name, redact) {
+				value = redacted
+				break
+			}
+		}
+		result[name] = value
+	}
+	return result
+}
+
+// redactFieldRegexp match the JSON field name and the form field whose name contains one of fields
+func redactFieldRegexp(fields []string) *regexp.Regexp {
+	if len(fields) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(fields))
+	for i, field := range fields {
+		quoted[i] = regexp.QuoteMeta(field)
+	}
+	name := `[^"&=:\s]*(?:` + strings.Join(quoted, "|") + `)[^"&=:\s]*`
+	return regexp.MustCompile(`(?i)("` + name + `"\s*:\s*)|((?:^|&)` + name + `=)[^&]*`)
+}
+
+// redactBody replace the value of the matched field. JSON value of any type is replaced, including number and
+// nested object, field inside the replaced value is skipped
+func redactBody(body []byte, field *regexp.Regexp) string {
+	if field == nil {
+		return string(body)
+	}
+
+	s := string(body)
+	var b strings.Builder
+	last := 0
+	for _, m := range field.FindAllStringSubmatchIndex(s, -1) {
+		if m[0] < last {
+			continue
+		}
+		if m[2] >= 0 { // JSON `"name":`
+			b.WriteString(s[last:m[3]])
+			b.WriteString(`"` + redacted + `"`)
+			last = jsonValueEnd(s, m[3])
+			continue
+		}
+		b.WriteString(s[last:m[5]]) // Form `name=`
+		b.WriteString(redacted)
+		last = m[1]
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// jsonValueEnd index after the JSON value starting at i, the value of truncated body end with the body
+func jsonValueEnd(s string, i int) int {
+	var (
+		depth    int
+		inString bool
+	)
+	for j := i; j < len(s); j++ {
+		c := s[j]
+		switch {
+		case inString:
+			if c == '\\' {
+				j++
+			} else if c == '"' {
+				inString = false
+				if depth == 0 {
+					return j + 1
+				}
+			}
+		case c == '"':
+			inString = true
+		case c == '{' || c == '[':
+			depth++
+		case c == '}' || c == ']':
+			if depth == 0 {
+				return j // Scalar value, the end of its parent
+			}
+			if depth--; depth == 0 {
+				return j + 1
+			}
+		case depth == 0 && (c == ',' || c == ' ' || c == '\t' || c == '\r' || c == '\n'):
+			return j
+		}
+	}
+	return len(s)
+}
+
+func redactQuery(u *url.URL, field *regexp.Regexp) string {
+	uri := u.RequestURI()
+	i := strings.Index(uri, "?")
+	if i < 0 {
+		return uri
+	}
+	return uri[:i+1] + redactBody([]byte(uri[i+1:]), field)
+}
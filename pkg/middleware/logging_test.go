@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveLogged(config LoggingConfig, r *http.Request, next http.HandlerFunc) (*httptest.ResponseRecorder, *test.Hook) {
+	logger, hook := test.NewNullLogger()
+	rw := httptest.NewRecorder()
+	RequestLogger(config, logger)(next).ServeHTTP(rw, r)
+	return rw, hook
+}
+
+func TestRequestLogger(t *testing.T) {
+	var (
+		handlerBody string
+		handlerID   string
+	)
+	next := func(rw http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		handlerBody = string(body)
+		handlerID = RequestID(r.Context())
+
+		rw.Header().Set("Content-Type", "application/json")
+		rw.WriteHeader(http.StatusCreated)
+		_, _ = rw.Write([]byte(`{"status":201,"data":{"access_token":"abc.def","member_id":7}}`))
+	}
+
+	body := `{"username":"ani","password":"s3cr\"et","items":[1,2,3]}`
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/login?token=xyz&page=1", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Authorization", "Bearer xyz")
+	r.Header.Set(RequestIDHeader, "req-1")
+
+	config := DefaultLoggingConfig()
+	config.RequestBodyLimit = 40
+	rw, hook := serveLogged(config, r, next)
+
+	assert.Equal(t, body, handlerBody, "Handler read the whole body")
+	assert.Equal(t, "req-1", handlerID)
+	assert.Equal(t, "req-1", rw.Header().Get(RequestIDHeader))
+
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.InfoLevel, entry.Level)
+	assert.Equal(t, "req-1", entry.Data["request_id"])
+	assert.Equal(t, http.StatusCreated, entry.Data["statuscode"])
+	assert.Equal(t, "/api/v1/login?token=[REDACTED]&page=1", entry.Data["path"])
+	assert.Equal(t, `{"username":"ani","password":"[REDACTED]",`, entry.Data["request"])
+	assert.Equal(t, `{"status":201,"data":{"access_token":"[REDACTED]","member_id":7}}`, entry.Data["response"])
+	assert.Equal(t, redacted, entry.Data["request_header"].(map[string]string)["Authorization"])
+}
+
+func TestRequestLogger_RequestID(t *testing.T) {
+	next := func(rw http.ResponseWriter, r *http.Request) {}
+
+	for _, id := range []string{"", "has space", strings.Repeat("a", 129)} {
+		r := httptest.NewRequest(http.MethodGet, "/api/v2/products", nil)
+		r.Header.Set(RequestIDHeader, id)
+		rw, _ := serveLogged(DefaultLoggingConfig(), r, next)
+
+		assert.Len(t, rw.Header().Get(RequestIDHeader), 32, "Invalid %q is replaced", id)
+	}
+}
+
+func TestRequestLogger_Sampling(t *testing.T) {
+	config := DefaultLoggingConfig()
+	config.SampleRate = 0
+
+	rw, hook := serveLogged(config, httptest.NewRequest(http.MethodGet, "/api/v2/products", nil),
+		func(rw http.ResponseWriter, r *http.Request) {})
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Empty(t, hook.Entries, "Successful request is not sampled")
+
+	_, hook = serveLogged(config, httptest.NewRequest(http.MethodGet, "/api/v2/products", nil),
+		func(rw http.ResponseWriter, r *http.Request) { rw.WriteHeader(http.StatusInternalServerError) })
+	assert.Len(t, hook.Entries, 1, "Error is always logged")
+	assert.Equal(t, logrus.ErrorLevel, hook.LastEntry().Level)
+
+	config.SampleRate = 1
+	_, hook = serveLogged(config, httptest.NewRequest(http.MethodGet, "/healthz", nil),
+		func(rw http.ResponseWriter, r *http.Request) {})
+	assert.Empty(t, hook.Entries, "Probe is skipped")
+}
+
+func TestLoggingConfigFromParams(t *testing.T) {
+	config := LoggingConfigFromParams(map[string]string{
+		ParamLogRequestBodyLimit: "0",
+		ParamLogSampleRate:       "0.25",
+	}, DefaultLoggingConfig())
+
+	assert.Equal(t, 0, config.RequestBodyLimit)
+	assert.Equal(t, 4096, config.ResponseBodyLimit)
+	assert.Equal(t, 0.25, config.SampleRate)
+}
+
+func TestRedactBody(t *testing.T) {
+	field := redactFieldRegexp(DefaultLoggingConfig().RedactFields)
+
+	tests := []struct {
+		body string
+		want string
+	}{
+		{`{"Password" : "x", "new_password":"y"}`, `{"Password" : "[REDACTED]", "new_password":"[REDACTED]"}`},
+		{`{"fcm_token":"abc`, `{"fcm_token":"[REDACTED]"`},
+		{`username=ani&password=x&remember=1`, `username=ani&password=[REDACTED]&remember=1`},
+		{`{"product_id":1,"quantity":2}`, `{"product_id":1,"quantity":2}`},
+		{`{"otp_secret":123456, "token":{"value":"a\"}","exp":1},"tokens":["a","b"],"ok":true}`,
+			`{"otp_secret":"[REDACTED]", "token":"[REDACTED]","tokens":"[REDACTED]","ok":true}`},
+		{`{"card":{"secret":null},"id":1}`, `{"card":{"secret":"[REDACTED]"},"id":1}`},
+		{`{"auth":{"token":{"refresh_token":"x"`, `{"auth":{"token":"[REDACTED]"`},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, redactBody([]byte(tt.body), field))
+	}
+}
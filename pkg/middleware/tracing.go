@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"store-api/pkg/tracing"
+)
+
+// Tracing server span of the request with the global tracer, child of the trace context in the request header.
+// Add it with mux.Router.Use, the resource is the route template to keep its cardinality low
+func Tracing() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if template, err := current.GetPathTemplate(); err == nil {
+					route = template
+				}
+			}
+
+			opts := []tracing.SpanOption{
+				tracing.WithKind(tracing.KindServer),
+				tracing.WithResource(r.Method + " " + route),
+				tracing.WithTag(tracing.TagHTTPMethod, r.Method),
+				tracing.WithTag(tracing.TagHTTPRoute, route),
+				tracing.WithTag(tracing.TagHTTPURL, r.URL.Path),
+			}
+			if id := RequestID(r.Context()); id != "" {
+				opts = append(opts, tracing.WithTag("request_id", id))
+			}
+			span, ctx := tracing.StartSpan(tracing.Extract(r.Context(), tracing.HeaderCarrier(r.Header)), "http.request", opts...)
+			defer span.Finish()
+
+			recorder := &responseRecorder{ResponseWriter: rw, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(ctx))
+
+			span.SetTag(tracing.TagHTTPStatusCode, recorder.status)
+			if recorder.status >= http.StatusInternalServerError {
+				span.SetError(fmt.Errorf("%d %s", recorder.status, http.StatusText(recorder.status)))
+			}
+		})
+	}
+}
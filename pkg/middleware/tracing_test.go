@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"store-api/pkg/tracing"
+)
+
+func TestTracing(t *testing.T) {
+	tracer := tracing.NewMemoryTracer()
+	tracing.SetTracer(tracer)
+	defer tracing.SetTracer(nil)
+
+	var handlerTraceID string
+	router := mux.NewRouter()
+	router.Use(Tracing())
+	router.HandleFunc("/api/v2/products/{id:[0-9]+}", func(rw http.ResponseWriter, r *http.Request) {
+		handlerTraceID = tracing.TraceID(r.Context())
+		rw.WriteHeader(http.StatusBadGateway)
+	})
+
+	// Span of the caller, ex: the mobile app or the other service
+	caller, ctx := tracer.StartSpan(context.Background(), "checkout")
+	r := httptest.NewRequest(http.MethodGet, "/api/v2/products/7", nil)
+	tracer.Inject(ctx, tracing.HeaderCarrier(r.Header))
+	caller.Finish()
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, r.WithContext(WithRequestID(r.Context(), "req-1")))
+	assert.Equal(t, http.StatusBadGateway, rw.Code)
+
+	spans := tracer.Spans("http.request")
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "GET /api/v2/products/{id:[0-9]+}", spans[0].Resource)
+	assert.Equal(t, tracing.KindServer, spans[0].Kind)
+	assert.Equal(t, "/api/v2/products/7", spans[0].Tags[tracing.TagHTTPURL])
+	assert.Equal(t, int64(http.StatusBadGateway), spans[0].Tags[tracing.TagHTTPStatusCode])
+	assert.Equal(t, "req-1", spans[0].Tags["request_id"])
+	assert.Equal(t, "502 Bad Gateway", spans[0].Error)
+	assert.Equal(t, caller.TraceID(), spans[0].TraceID)
+	assert.Equal(t, caller.TraceID(), handlerTraceID)
+	assert.Equal(t, tracer.Spans("checkout")[0].SpanID, spans[0].ParentID)
+}
@@ -1,6 +1,7 @@
 package response
 
 import (
+    "errors"
     "net/http"
 
     "store-api/pkg/errs"
@@ -23,6 +24,26 @@ func GetStatusCode(err error) int {
         return http.StatusBadRequest
     case errs.ErrMissingParam:
         return http.StatusUnprocessableEntity
+    }
+
+    var customErr *errs.Error
+    if errors.As(err, &customErr) {
+        return statusCodeOfType(customErr.Type)
+    }
+    return http.StatusInternalServerError
+}
+
+// statusCodeOfType http status code of errs.Error by its type
+func statusCodeOfType(errType errs.ErrorType) int {
+    switch errType {
+    case errs.ErrorTypeMissing, errs.ErrorTypeInvalid, errs.ErrorTypeUnprocesseableEntity:
+        return http.StatusUnprocessableEntity
+    case errs.ErrorTypeParse, errs.ErrorTypeBadRequest, errs.ErrorTypeFile:
+        return http.StatusBadRequest
+    case errs.ErrorTypeUnauthorized:
+        return http.StatusUnauthorized
+    case errs.ErrorTypeNotFound:
+        return http.StatusNotFound
     default:
         return http.StatusInternalServerError
     }
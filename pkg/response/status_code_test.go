@@ -1,6 +1,7 @@
 package response
 
 import (
+    "fmt"
     "testing"
 
     "store-api/pkg/errs"
@@ -43,4 +44,19 @@ func TestParams_GetStatusCode(t *testing.T) {
         err := GetStatusCode(errs.ErrMissingParam)
         assert.Equal(t, 422, err)
     })
+
+    t.Run("custom-error-by-type", func(t *testing.T) {
+        assert.Equal(t, 422, GetStatusCode(errs.NewMissingError("id")))
+        assert.Equal(t, 422, GetStatusCode(errs.NewFieldError("quantity", -1, errs.ErrorTypeInvalid, errs.ErrBadParamInput)))
+        assert.Equal(t, 400, GetStatusCode(errs.NewParseError("id")))
+        assert.Equal(t, 400, GetStatusCode(errs.NewMalformedJSONError(errs.ErrBadParamInput)))
+        assert.Equal(t, 401, GetStatusCode(errs.NewUnautorizedError(errs.ErrBadParamInput)))
+        assert.Equal(t, 404, GetStatusCode(errs.NewNotFoundError("product")))
+        assert.Equal(t, 500, GetStatusCode(errs.NewSqlError("product", errs.ErrInternalServerError)))
+    })
+
+    t.Run("wrapped-custom-error", func(t *testing.T) {
+        err := fmt.Errorf("import: %w", errs.NewMissingKeyError("sku"))
+        assert.Equal(t, 422, GetStatusCode(err))
+    })
 }
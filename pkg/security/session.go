@@ -2,6 +2,7 @@ package security
 
 import (
     "errors"
+    "os"
     "time"
 
     "github.com/go-playground/validator/v10"
@@ -18,6 +19,56 @@ type (
     }
 )
 
+// ErrNoSessionSecret SESSION_SECRET is empty, there is no default secret so the session can't be forged
+var ErrNoSessionSecret = errors.New("SESSION_SECRET is not set")
+
+// SessionSecret secret to sign and verify session token, SESSION_SECRET
+func SessionSecret() (string, error) {
+    secret := os.Getenv("SESSION_SECRET")
+    if secret == "" {
+        return "", ErrNoSessionSecret
+    }
+    return secret, nil
+}
+
+// LegacySessionSecret SESSION_LEGACY_SECRET, opt-in to keep token signed with the previous secret valid until they
+// expire. It only verify, token is never signed with it
+func LegacySessionSecret() string {
+    return os.Getenv("SESSION_LEGACY_SECRET")
+}
+
+// SignSession token signed with SessionSecret
+func SignSession(ss *Session) (string, error) {
+    secret, err := SessionSecret()
+    if err != nil {
+        return "", err
+    }
+    crypt, _ := New(secret)
+    return ss.Encrypt(crypt)
+}
+
+// ParseSession verify the token with SessionSecret, then with LegacySessionSecret when it is set.
+// Every token is rejected when SESSION_SECRET is empty
+func ParseSession(token string) (*Session, error) {
+    secret, err := SessionSecret()
+    if err != nil {
+        return nil, err
+    }
+    crypt, _ := New(secret)
+    ss, err := NewSession(crypt, token)
+    if err == nil {
+        return ss, nil
+    }
+
+    if legacy := LegacySessionSecret(); legacy != "" && legacy != secret {
+        legacyCrypt, _ := New(legacy)
+        if ss, legacyErr := NewSession(legacyCrypt, token); legacyErr == nil {
+            return ss, nil
+        }
+    }
+    return nil, err
+}
+
 func (ss *Session) IsSessionExpired() error {
     if time.Now().After(time.Unix(ss.Expired, 0)) {
         return errors.New("Expired Session")
@@ -33,7 +84,10 @@ func (ss *Session) ExtendSession(cr Crypto, duration int64) (string, error) {
 }
 
 func (ss *Session) Encrypt(cr Crypto) (string, error) {
-    enc, _ := cr.Encrypt(ss)
+    enc, err := cr.Encrypt(ss)
+    if err != nil {
+        return "", err
+    }
 
     return string(enc), nil
 }
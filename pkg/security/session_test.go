@@ -0,0 +1,60 @@
+package security
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSession() *Session {
+	return &Session{UserId: "7", Username: "ani", Name: "-", Role: "-", Iat: time.Now().Unix(),
+		Expired: time.Now().Add(time.Hour).Unix()}
+}
+
+func TestParseSession(t *testing.T) {
+	t.Run("No secret reject every token", func(t *testing.T) {
+		os.Setenv("SESSION_SECRET", "")
+		crypt, _ := New("123")
+		token, _ := newTestSession().Encrypt(crypt)
+
+		_, err := SignSession(newTestSession())
+		assert.Equal(t, ErrNoSessionSecret, err)
+		_, err = ParseSession(token)
+		assert.Equal(t, ErrNoSessionSecret, err)
+	})
+
+	t.Run("Signed with the secret", func(t *testing.T) {
+		os.Setenv("SESSION_SECRET", "s3cret")
+		defer os.Unsetenv("SESSION_SECRET")
+
+		token, err := SignSession(newTestSession())
+		assert.Nil(t, err)
+		ss, err := ParseSession(token)
+		assert.Nil(t, err)
+		assert.Equal(t, "7", ss.UserId)
+
+		forged, _ := New("123")
+		token, _ = newTestSession().Encrypt(forged)
+		_, err = ParseSession(token)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Legacy secret only verify", func(t *testing.T) {
+		os.Setenv("SESSION_SECRET", "s3cret")
+		os.Setenv("SESSION_LEGACY_SECRET", "old")
+		defer os.Unsetenv("SESSION_SECRET")
+		defer os.Unsetenv("SESSION_LEGACY_SECRET")
+
+		legacy, _ := New("old")
+		token, _ := newTestSession().Encrypt(legacy)
+		ss, err := ParseSession(token)
+		assert.Nil(t, err)
+		assert.Equal(t, "ani", ss.Username)
+
+		token, _ = SignSession(newTestSession())
+		_, err = NewSession(legacy, token)
+		assert.NotNil(t, err, "New token is signed with SESSION_SECRET")
+	})
+}
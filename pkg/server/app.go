@@ -1,11 +1,17 @@
 package server
 
-import "bytes"
+import (
+	"bytes"
+	"context"
+
+	"store-api/pkg/errs"
+)
 
 //App is a server application abstraction,
 //can be HTTP, Consumer (kafka/pub-sub), Redis, etc
 type App interface {
 	Run() error
+	Shutdown(ctx context.Context) error // Stop accepting new work and wait for the one in progress until ctx is done
 }
 
 type LogMessage struct {
@@ -27,17 +33,19 @@ const (
 
 // MobileResponse always http.StatusOK but Status field 200,403,400.
 type MobileResponse struct {
-	Message string      `json:"message"`
-	Status  int         `json:"status"`
-	Data    interface{} `json:"data"`
-	Version string      `json:"version"`
+	Message string               `json:"message"`
+	Status  int                  `json:"status"`
+	Data    interface{}          `json:"data"`
+	Errors  []errs.ErrorResponse `json:"errors,omitempty"`
+	Version string               `json:"version"`
 }
 
 // WebResponse different format with Mobile Response
 type WebResponse struct {
-	Status  int         `json:"status"`
-	Message string      `json:"message"`
-	Data    interface{} `json:"data"`
+	Status  int                  `json:"status"`
+	Message string               `json:"message"`
+	Data    interface{}          `json:"data"`
+	Errors  []errs.ErrorResponse `json:"errors,omitempty"`
 }
 
 // Yii2HTTPException for yii\web\HttpException
@@ -56,6 +64,7 @@ type Response struct {
 	Status       int
 	Message      string
 	Data         interface{}
+	Errors       []errs.ErrorResponse // Error body, the same for web and mobile
 	Version      string
 	Log          *LogMessage
 	ResponseType int
@@ -0,0 +1,132 @@
+package tracing
+
+import (
+	"context"
+	"strconv"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/ext"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// NewDatadogTracer start the global dd-trace tracer sending to the agent host:port. Trace context is propagated
+// in x-datadog-* header, this version of dd-trace has no W3C propagator
+func NewDatadogTracer(agentAddr string, config Config) Tracer {
+	opts := []tracer.StartOption{
+		tracer.WithService(config.ServiceName),
+		tracer.WithSampler(tracer.NewRateSampler(sampleRate(config.SampleRate))),
+	}
+	if agentAddr != "" {
+		opts = append(opts, tracer.WithAgentAddr(agentAddr))
+	}
+	if config.Environment != "" {
+		opts = append(opts, tracer.WithEnv(config.Environment))
+	}
+	if config.Version != "" {
+		opts = append(opts, tracer.WithServiceVersion(config.Version))
+	}
+	tracer.Start(opts...)
+	return datadogTracer{}
+}
+
+type datadogTracer struct{}
+
+// remoteSpanKey span context extracted from the inbound request, dd-trace keep only the local span in ctx
+type remoteSpanKey struct{}
+
+func (datadogTracer) StartSpan(ctx context.Context, operation string, opts ...SpanOption) (Span, context.Context) {
+	config := newSpanConfig(opts)
+
+	startOpts := []tracer.StartSpanOption{tracer.SpanType(datadogSpanType(config))}
+	if config.Resource != "" {
+		startOpts = append(startOpts, tracer.ResourceName(config.Resource))
+	}
+	for key, value := range config.Tags {
+		startOpts = append(startOpts, tracer.Tag(key, value))
+	}
+	if _, ok := tracer.SpanFromContext(ctx); !ok {
+		if remote, ok := ctx.Value(remoteSpanKey{}).(ddtrace.SpanContext); ok {
+			startOpts = append(startOpts, tracer.ChildOf(remote))
+		}
+	}
+
+	span, ctx := tracer.StartSpanFromContext(ctx, operation, startOpts...)
+	return datadogSpan{span: span}, ctx
+}
+
+func (datadogTracer) SpanFromContext(ctx context.Context) (Span, bool) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return datadogSpan{span: span}, true
+}
+
+func (datadogTracer) Inject(ctx context.Context, carrier Carrier) {
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		_ = tracer.Inject(span.Context(), datadogCarrier{carrier})
+	}
+}
+
+func (datadogTracer) Extract(ctx context.Context, carrier Carrier) context.Context {
+	remote, err := tracer.Extract(datadogCarrier{carrier})
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteSpanKey{}, remote)
+}
+
+// Shutdown dd-trace Stop flush the span, ctx is not used
+func (datadogTracer) Shutdown(ctx context.Context) error {
+	tracer.Stop()
+	return nil
+}
+
+type datadogSpan struct {
+	span ddtrace.Span
+}
+
+func (s datadogSpan) SetTag(key string, value interface{}) { s.span.SetTag(key, value) }
+func (s datadogSpan) SetError(err error)                   { s.span.SetTag(ext.Error, err) }
+func (s datadogSpan) Finish()                              { s.span.Finish() }
+func (s datadogSpan) TraceID() string {
+	return strconv.FormatUint(s.span.Context().TraceID(), 10)
+}
+
+// datadogCarrier Carrier as tracer.TextMapReader and tracer.TextMapWriter
+type datadogCarrier struct {
+	carrier Carrier
+}
+
+func (c datadogCarrier) Set(key, value string) {
+	c.carrier.Set(key, value)
+}
+
+func (c datadogCarrier) ForeachKey(handler func(key, value string) error) error {
+	for _, key := range c.carrier.Keys() {
+		if err := handler(key, c.carrier.Get(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// datadogSpanType db.system tag mark the client span as sql or redis
+func datadogSpanType(config SpanConfig) string {
+	if system, ok := config.Tags[TagDBSystem].(string); ok {
+		if system == "redis" {
+			return ext.SpanTypeRedis
+		}
+		return ext.SpanTypeSQL
+	}
+	switch config.Kind {
+	case KindServer:
+		return ext.SpanTypeWeb
+	case KindClient:
+		return ext.SpanTypeHTTP
+	case KindProducer, KindConsumer:
+		return ext.SpanTypeMessageProducer
+	default:
+		return ""
+	}
+}
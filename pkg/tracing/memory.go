@@ -0,0 +1,90 @@
+package tracing
+
+import (
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MemorySpan finished span kept by MemoryTracer
+type MemorySpan struct {
+	Operation string
+	Resource  string // Empty when the span is started without WithResource
+	Kind      SpanKind
+	Tags      map[string]interface{}
+	Error     string
+	TraceID   string
+	SpanID    string
+	ParentID  string // Empty for the root span
+}
+
+// MemoryTracer OpenTelemetry tracer keeping finished spans in memory, for test. Trace context is propagated
+// in W3C header like NewOTelTracer
+type MemoryTracer struct {
+	*otelTracer
+	exporter *tracetest.InMemoryExporter
+}
+
+func NewMemoryTracer() *MemoryTracer {
+	exporter := tracetest.NewInMemoryExporter()
+	return &MemoryTracer{
+		otelTracer: newOTelTracer(Config{ServiceName: "test"}, sdktrace.WithSyncer(exporter)),
+		exporter:   exporter,
+	}
+}
+
+// Spans finished with the operation in finish order, all spans when operation is empty
+func (t *MemoryTracer) Spans(operation string) []MemorySpan {
+	var spans []MemorySpan
+	for _, stub := range t.exporter.GetSpans() {
+		span := MemorySpan{
+			Operation: stub.Name,
+			Kind:      memoryKind(stub.SpanKind),
+			Tags:      make(map[string]interface{}, len(stub.Attributes)),
+			TraceID:   stub.SpanContext.TraceID().String(),
+			SpanID:    stub.SpanContext.SpanID().String(),
+		}
+		for _, attribute := range stub.Attributes {
+			span.Tags[string(attribute.Key)] = attribute.Value.AsInterface()
+		}
+		if op, ok := span.Tags[attributeOperation].(string); ok {
+			span.Resource, span.Operation = stub.Name, op
+			delete(span.Tags, attributeOperation)
+		}
+		if resource, ok := span.Tags[attributeResource].(string); ok {
+			span.Resource = resource
+			delete(span.Tags, attributeResource)
+		}
+		if stub.Status.Code == codes.Error {
+			span.Error = stub.Status.Description
+		}
+		if stub.Parent.IsValid() {
+			span.ParentID = stub.Parent.SpanID().String()
+		}
+		if operation == "" || span.Operation == operation {
+			spans = append(spans, span)
+		}
+	}
+	return spans
+}
+
+// Reset remove the finished spans
+func (t *MemoryTracer) Reset() {
+	t.exporter.Reset()
+}
+
+func memoryKind(kind trace.SpanKind) SpanKind {
+	switch kind {
+	case trace.SpanKindServer:
+		return KindServer
+	case trace.SpanKindClient:
+		return KindClient
+	case trace.SpanKindProducer:
+		return KindProducer
+	case trace.SpanKindConsumer:
+		return KindConsumer
+	default:
+		return KindInternal
+	}
+}
@@ -0,0 +1,171 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Protocol of NewOTLPExporter, same value as OTEL_EXPORTER_OTLP_PROTOCOL
+const (
+	ProtocolGRPC = "grpc"
+	ProtocolHTTP = "http/protobuf"
+)
+
+const instrumentationName = "store-api"
+
+// NewOTLPExporter endpoint, header and TLS are read by the exporter from OTEL_EXPORTER_OTLP_* env.
+// Protocol is ProtocolHTTP when empty
+func NewOTLPExporter(ctx context.Context, protocol string) (sdktrace.SpanExporter, error) {
+	switch protocol {
+	case "", ProtocolHTTP:
+		return otlptracehttp.New(ctx)
+	case ProtocolGRPC:
+		return otlptracegrpc.New(ctx)
+	default:
+		return nil, fmt.Errorf("unknown OTLP protocol %s", protocol)
+	}
+}
+
+// NewOTelTracer OpenTelemetry tracer, span is batched to the exporter. Trace context is propagated in W3C
+// traceparent and tracestate header, baggage in baggage header
+func NewOTelTracer(exporter sdktrace.SpanExporter, config Config) Tracer {
+	return newOTelTracer(config, sdktrace.WithBatcher(exporter))
+}
+
+func newOTelTracer(config Config, opts ...sdktrace.TracerProviderOption) *otelTracer {
+	attributes := []attribute.KeyValue{semconv.ServiceNameKey.String(config.ServiceName)}
+	if config.Environment != "" {
+		attributes = append(attributes, semconv.DeploymentEnvironmentKey.String(config.Environment))
+	}
+	if config.Version != "" {
+		attributes = append(attributes, semconv.ServiceVersionKey.String(config.Version))
+	}
+
+	opts = append(opts,
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, attributes...)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRate(config.SampleRate)))),
+	)
+	provider := sdktrace.NewTracerProvider(opts...)
+
+	return &otelTracer{
+		provider:   provider,
+		tracer:     provider.Tracer(instrumentationName),
+		propagator: propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}),
+	}
+}
+
+// Attribute of the span config without OpenTelemetry equivalent
+const (
+	attributeOperation = "operation"
+	attributeResource  = "resource.name"
+)
+
+type otelTracer struct {
+	provider   *sdktrace.TracerProvider
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// StartSpan the resource is the span name when it is set, operation is kept in "operation" attribute. Database
+// span keep the operation as name, its resource is the statement and is kept in "resource.name" attribute
+func (t *otelTracer) StartSpan(ctx context.Context, operation string, opts ...SpanOption) (Span, context.Context) {
+	config := newSpanConfig(opts)
+
+	name := operation
+	attributes := make([]attribute.KeyValue, 0, len(config.Tags)+1)
+	if _, isDB := config.Tags[TagDBSystem]; isDB && config.Resource != "" {
+		attributes = append(attributes, attribute.String(attributeResource, config.Resource))
+	} else if config.Resource != "" {
+		name = config.Resource
+		attributes = append(attributes, attribute.String(attributeOperation, operation))
+	}
+	for key, value := range config.Tags {
+		attributes = append(attributes, otelAttribute(key, value))
+	}
+
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(otelKind(config.Kind)), trace.WithAttributes(attributes...))
+	return otelSpan{span: span}, ctx
+}
+
+func (t *otelTracer) SpanFromContext(ctx context.Context) (Span, bool) {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return nil, false
+	}
+	return otelSpan{span: span}, true
+}
+
+func (t *otelTracer) Inject(ctx context.Context, carrier Carrier) {
+	t.propagator.Inject(ctx, carrier)
+}
+
+func (t *otelTracer) Extract(ctx context.Context, carrier Carrier) context.Context {
+	return t.propagator.Extract(ctx, carrier)
+}
+
+func (t *otelTracer) Shutdown(ctx context.Context) error {
+	return t.provider.Shutdown(ctx)
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetTag(key string, value interface{}) {
+	s.span.SetAttributes(otelAttribute(key, value))
+}
+
+func (s otelSpan) SetError(err error) {
+	s.span.RecordError(err)
+	s.span.SetStatus(codes.Error, err.Error())
+}
+
+func (s otelSpan) Finish() {
+	s.span.End()
+}
+
+func (s otelSpan) TraceID() string {
+	return s.span.SpanContext().TraceID().String()
+}
+
+func otelKind(kind SpanKind) trace.SpanKind {
+	switch kind {
+	case KindServer:
+		return trace.SpanKindServer
+	case KindClient:
+		return trace.SpanKindClient
+	case KindProducer:
+		return trace.SpanKindProducer
+	case KindConsumer:
+		return trace.SpanKindConsumer
+	default:
+		return trace.SpanKindInternal
+	}
+}
+
+func otelAttribute(key string, value interface{}) attribute.KeyValue {
+	switch v := value.(type) {
+	case string:
+		return attribute.String(key, v)
+	case int:
+		return attribute.Int(key, v)
+	case int64:
+		return attribute.Int64(key, v)
+	case float64:
+		return attribute.Float64(key, v)
+	case bool:
+		return attribute.Bool(key, v)
+	default:
+		return attribute.String(key, fmt.Sprint(v))
+	}
+}
@@ -0,0 +1,267 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"regexp"
+	"strings"
+)
+
+// sqlValueList "(?, ?)" of IN and VALUES, the number of value is not part of the resource
+var sqlValueList = regexp.MustCompile(`\(\s*\?(?:\s*,\s*\?)*\s*\)(?:\s*,\s*\(\s*\?(?:\s*,\s*\?)*\s*\))*`)
+
+// WrapConnector trace the query, exec, prepared statement and transaction of the connector with the global tracer.
+// Use with sql.OpenDB, the query needs the *Context method of database/sql to be child of the request span
+func WrapConnector(connector driver.Connector, system string) driver.Connector {
+	return tracedConnector{connector: connector, system: system}
+}
+
+type tracedConnector struct {
+	connector driver.Connector
+	system    string
+}
+
+func (c tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedConn{Conn: conn, system: c.system}, nil
+}
+
+func (c tracedConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+func startSQLSpan(ctx context.Context, system, operation, query string) (Span, context.Context) {
+	opts := []SpanOption{WithKind(KindClient), WithTag(TagDBSystem, system)}
+	if query != "" {
+		query = obfuscateSQL(query)
+		opts = append(opts, WithResource(query), WithTag(TagDBStatement, query))
+	}
+	return StartSpan(ctx, operation, opts...)
+}
+
+// obfuscateSQL replace the string and number literal by "?". Query built with fmt.Sprintf has the value inlined,
+// it may be PII and make every query a new resource. Identifier and placeholder are kept
+func obfuscateSQL(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i = quotedEnd(query, i)
+			b.WriteByte('?')
+		case c == '`':
+			end := quotedEnd(query, i)
+			b.WriteString(query[i:end])
+			i = end
+		case c >= '0' && c <= '9' && (i == 0 || !isIdentifier(query[i-1])):
+			for i < len(query) && (isIdentifier(query[i]) || query[i] == '.') {
+				i++
+			}
+			b.WriteByte('?')
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return sqlValueList.ReplaceAllString(b.String(), "(?)")
+}
+
+// quotedEnd index after the closing quote of the literal starting at i, doubled or escaped quote is in the literal
+func quotedEnd(query string, i int) int {
+	quote := query[i]
+	for j := i + 1; j < len(query); j++ {
+		switch query[j] {
+		case '\\':
+			if quote != '`' {
+				j++
+			}
+		case quote:
+			if j+1 < len(query) && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(query)
+}
+
+func isIdentifier(c byte) bool {
+	return c == '_' || c == '$' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// finishSQLSpan driver.ErrSkip is not an error, database/sql falls back to the prepared statement
+func finishSQLSpan(span Span, err error) {
+	if err != nil && err != driver.ErrSkip {
+		span.SetError(err)
+	}
+	span.Finish()
+}
+
+// tracedConn the query with args skips to the traced prepared statement, the same path go-sql-driver/mysql
+// takes without interpolateParams. It avoids an empty span when the driver returns driver.ErrSkip
+type tracedConn struct {
+	driver.Conn
+	system string
+}
+
+func (c *tracedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok || len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	span, ctx := startSQLSpan(ctx, c.system, "sql.exec", query)
+	result, err := execer.ExecContext(ctx, query, args)
+	finishSQLSpan(span, err)
+	return result, err
+}
+
+func (c *tracedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok || len(args) > 0 {
+		return nil, driver.ErrSkip
+	}
+	span, ctx := startSQLSpan(ctx, c.system, "sql.query", query)
+	rows, err := queryer.QueryContext(ctx, query, args)
+	finishSQLSpan(span, err)
+	return rows, err
+}
+
+func (c *tracedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	span, ctx := startSQLSpan(ctx, c.system, "sql.prepare", query)
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	finishSQLSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedStmt{Stmt: stmt, system: c.system, query: query}, nil
+}
+
+func (c *tracedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	span, spanCtx := startSQLSpan(ctx, c.system, "sql.begin", "")
+	var tx driver.Tx
+	var err error
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		tx, err = beginner.BeginTx(spanCtx, opts)
+	} else {
+		tx, err = c.Conn.Begin()
+	}
+	finishSQLSpan(span, err)
+	if err != nil {
+		return nil, err
+	}
+	return &tracedTx{Tx: tx, ctx: ctx, system: c.system}, nil
+}
+
+func (c *tracedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *tracedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+func (c *tracedConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+type tracedStmt struct {
+	driver.Stmt
+	system string
+	query  string
+}
+
+func (s *tracedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	span, ctx := startSQLSpan(ctx, s.system, "sql.exec", s.query)
+	var result driver.Result
+	var err error
+	if execer, ok := s.Stmt.(driver.StmtExecContext); ok {
+		result, err = execer.ExecContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValueToValue(args); err == nil {
+			result, err = s.Stmt.Exec(values)
+		}
+	}
+	finishSQLSpan(span, err)
+	return result, err
+}
+
+func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	span, ctx := startSQLSpan(ctx, s.system, "sql.query", s.query)
+	var rows driver.Rows
+	var err error
+	if queryer, ok := s.Stmt.(driver.StmtQueryContext); ok {
+		rows, err = queryer.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = namedValueToValue(args); err == nil {
+			rows, err = s.Stmt.Query(values)
+		}
+	}
+	finishSQLSpan(span, err)
+	return rows, err
+}
+
+func (s *tracedStmt) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := s.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
+
+// tracedTx commit and rollback span are sibling of the begin span
+type tracedTx struct {
+	driver.Tx
+	ctx    context.Context
+	system string
+}
+
+func (t *tracedTx) Commit() (err error) {
+	span, _ := startSQLSpan(t.ctx, t.system, "sql.commit", "")
+	defer FinishWithError(span, &err)
+	return t.Tx.Commit()
+}
+
+func (t *tracedTx) Rollback() (err error) {
+	span, _ := startSQLSpan(t.ctx, t.system, "sql.rollback", "")
+	defer FinishWithError(span, &err)
+	return t.Tx.Rollback()
+}
+
+func namedValueToValue(named []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(named))
+	for i, arg := range named {
+		if arg.Name != "" {
+			return nil, driver.ErrSkip
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
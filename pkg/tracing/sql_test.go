@@ -0,0 +1,108 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(ctx context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+func (fakeConnector) Driver() driver.Driver                            { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if query == "SELECT broken" {
+		return nil, errors.New("syntax error")
+	}
+	return fakeStmt{}, nil
+}
+func (fakeConn) Close() error              { return nil }
+func (fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+type fakeStmt struct{}
+
+func (fakeStmt) Close() error                                    { return nil }
+func (fakeStmt) NumInput() int                                   { return -1 }
+func (fakeStmt) Exec(args []driver.Value) (driver.Result, error) { return driver.RowsAffected(1), nil }
+func (fakeStmt) Query(args []driver.Value) (driver.Rows, error)  { return fakeRows{}, nil }
+
+type fakeRows struct{}
+
+func (fakeRows) Columns() []string              { return []string{"id"} }
+func (fakeRows) Close() error                   { return nil }
+func (fakeRows) Next(dest []driver.Value) error { return io.EOF }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+func TestWrapConnector(t *testing.T) {
+	tracer := NewMemoryTracer()
+	SetTracer(tracer)
+	defer SetTracer(nil)
+
+	db := sql.OpenDB(WrapConnector(fakeConnector{}, "mysql"))
+	defer db.Close()
+
+	parent, ctx := StartSpan(context.Background(), "http.request")
+	rows, err := db.QueryContext(ctx, "SELECT id FROM product WHERE id = ?", 7)
+	assert.Nil(t, err)
+	assert.Nil(t, rows.Close())
+
+	tx, err := db.BeginTx(ctx, nil)
+	assert.Nil(t, err)
+	_, err = tx.ExecContext(ctx, "UPDATE product SET stock = stock - 1")
+	assert.Nil(t, err)
+	assert.Nil(t, tx.Commit())
+
+	_, err = db.ExecContext(ctx, "SELECT broken")
+	assert.NotNil(t, err)
+
+	_, err = db.ExecContext(ctx, "UPDATE member SET name = 'Ani' WHERE username = 'ani@example.com'")
+	assert.Nil(t, err)
+	parent.Finish()
+
+	query := tracer.Spans("sql.query")
+	assert.Len(t, query, 1, "Query with args go through the prepared statement, without span of driver.ErrSkip")
+	assert.Equal(t, "SELECT id FROM product WHERE id = ?", query[0].Resource)
+	assert.Equal(t, "mysql", query[0].Tags[TagDBSystem])
+	assert.Equal(t, KindClient, query[0].Kind)
+	assert.Equal(t, tracer.Spans("http.request")[0].SpanID, query[0].ParentID)
+
+	exec := tracer.Spans("sql.exec")
+	assert.Len(t, exec, 2, "Span is named by the operation, not the statement")
+	assert.Equal(t, "UPDATE member SET name = ? WHERE username = ?", exec[1].Resource)
+	assert.Equal(t, exec[1].Resource, exec[1].Tags[TagDBStatement])
+	assert.Len(t, tracer.Spans("sql.begin"), 1)
+	assert.Len(t, tracer.Spans("sql.commit"), 1)
+
+	prepare := tracer.Spans("sql.prepare")
+	assert.Len(t, prepare, 4)
+	assert.Equal(t, "syntax error", prepare[2].Error)
+}
+
+func TestObfuscateSQL(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"SELECT id FROM member WHERE username = 'ani' AND id = 7", "SELECT id FROM member WHERE username = ? AND id = ?"},
+		{`SELECT * FROM t WHERE note = 'it''s' OR note = "a\"b" OR note = 'c\'d'`, "SELECT * FROM t WHERE note = ? OR note = ? OR note = ?"},
+		{"SELECT `table2`.col1, x_1 FROM `table2` LIMIT 10 OFFSET 20", "SELECT `table2`.col1, x_1 FROM `table2` LIMIT ? OFFSET ?"},
+		{"SELECT * FROM product WHERE id IN (1, 2, 3) AND price > 1.5e3", "SELECT * FROM product WHERE id IN (?) AND price > ?"},
+		{"INSERT INTO cart (a, b) VALUES (?, ?), (?, ?), (1, 'x')", "INSERT INTO cart (a, b) VALUES (?)"},
+		{"SELECT * FROM member WHERE token = 'not closed", "SELECT * FROM member WHERE token = ?"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, obfuscateSQL(tt.query))
+	}
+}
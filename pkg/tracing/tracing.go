@@ -0,0 +1,207 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+// SpanKind role of the span in the trace
+type SpanKind int
+
+const (
+	KindInternal SpanKind = iota
+	KindServer
+	KindClient
+	KindProducer
+	KindConsumer
+)
+
+// Span of an operation, Finish must be called once
+type Span interface {
+	SetTag(key string, value interface{})
+	SetError(err error)
+	Finish()
+	TraceID() string
+}
+
+// Carrier of the trace context, ex: http header or kafka message header
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+	Keys() []string
+}
+
+// Tracer backend, see NewDatadogTracer and NewOTelTracer
+type Tracer interface {
+	// StartSpan child of the span in ctx, or of the remote span extracted to ctx
+	StartSpan(ctx context.Context, operation string, opts ...SpanOption) (Span, context.Context)
+	SpanFromContext(ctx context.Context) (Span, bool)
+	// Inject the trace context of the span in ctx to the outbound request
+	Inject(ctx context.Context, carrier Carrier)
+	// Extract the trace context of the inbound request, the next span is its child
+	Extract(ctx context.Context, carrier Carrier) context.Context
+	// Shutdown flush the finished span
+	Shutdown(ctx context.Context) error
+}
+
+// Config of the tracer backend
+type Config struct {
+	ServiceName string
+	Environment string
+	Version     string
+	SampleRate  float64 // Ratio of trace sampled, 0 sample every trace. The remote parent decision is kept
+}
+
+// Tag key shared by the instrumentation, OpenTelemetry semantic convention name
+const (
+	TagDBSystem       = "db.system"
+	TagDBStatement    = "db.statement"
+	TagHTTPMethod     = "http.method"
+	TagHTTPRoute      = "http.route"
+	TagHTTPURL        = "http.url"
+	TagHTTPStatusCode = "http.status_code"
+	TagMessagingTopic = "messaging.destination"
+)
+
+// SpanConfig set by SpanOption
+type SpanConfig struct {
+	Kind     SpanKind
+	Resource string // Low cardinality name, ex: "GET /api/v2/products/{id}" or the SQL query without literal
+	Tags     map[string]interface{}
+}
+
+type SpanOption func(config *SpanConfig)
+
+func WithKind(kind SpanKind) SpanOption {
+	return func(config *SpanConfig) {
+		config.Kind = kind
+	}
+}
+
+func WithResource(resource string) SpanOption {
+	return func(config *SpanConfig) {
+		config.Resource = resource
+	}
+}
+
+func WithTag(key string, value interface{}) SpanOption {
+	return func(config *SpanConfig) {
+		if config.Tags == nil {
+			config.Tags = make(map[string]interface{})
+		}
+		config.Tags[key] = value
+	}
+}
+
+func sampleRate(rate float64) float64 {
+	if rate <= 0 || rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+func newSpanConfig(opts []SpanOption) SpanConfig {
+	config := SpanConfig{}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return config
+}
+
+var (
+	mu     sync.RWMutex
+	global Tracer = noopTracer{}
+)
+
+// SetTracer used by StartSpan, Inject and Extract. The default is a no-op tracer, nil set it back
+func SetTracer(tracer Tracer) {
+	mu.Lock()
+	defer mu.Unlock()
+	if tracer == nil {
+		tracer = noopTracer{}
+	}
+	global = tracer
+}
+
+// GetTracer set by SetTracer
+func GetTracer() Tracer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return global
+}
+
+func StartSpan(ctx context.Context, operation string, opts ...SpanOption) (Span, context.Context) {
+	return GetTracer().StartSpan(ctx, operation, opts...)
+}
+
+func SpanFromContext(ctx context.Context) (Span, bool) {
+	return GetTracer().SpanFromContext(ctx)
+}
+
+func Inject(ctx context.Context, carrier Carrier) {
+	GetTracer().Inject(ctx, carrier)
+}
+
+func Extract(ctx context.Context, carrier Carrier) context.Context {
+	return GetTracer().Extract(ctx, carrier)
+}
+
+// TraceID of the span in ctx, empty without span
+func TraceID(ctx context.Context) string {
+	if span, ok := SpanFromContext(ctx); ok {
+		return span.TraceID()
+	}
+	return ""
+}
+
+// FinishWithError set the error when it is not nil and finish the span, ex: defer FinishWithError(span, &err)
+func FinishWithError(span Span, err *error) {
+	if err != nil && *err != nil {
+		span.SetError(*err)
+	}
+	span.Finish()
+}
+
+// HeaderCarrier http.Header as Carrier
+type HeaderCarrier http.Header
+
+func (c HeaderCarrier) Get(key string) string { return http.Header(c).Get(key) }
+func (c HeaderCarrier) Set(key, value string) { http.Header(c).Set(key, value) }
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// MapCarrier map as Carrier, ex: headers of httpclient.Client
+type MapCarrier map[string]string
+
+func (c MapCarrier) Get(key string) string { return c[key] }
+func (c MapCarrier) Set(key, value string) { c[key] = value }
+func (c MapCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+type noopTracer struct{}
+
+func (noopTracer) StartSpan(ctx context.Context, operation string, opts ...SpanOption) (Span, context.Context) {
+	return noopSpan{}, ctx
+}
+func (noopTracer) SpanFromContext(ctx context.Context) (Span, bool)             { return nil, false }
+func (noopTracer) Inject(ctx context.Context, carrier Carrier)                  {}
+func (noopTracer) Extract(ctx context.Context, carrier Carrier) context.Context { return ctx }
+func (noopTracer) Shutdown(ctx context.Context) error                           { return nil }
+
+type noopSpan struct{}
+
+func (noopSpan) SetTag(key string, value interface{}) {}
+func (noopSpan) SetError(err error)                   {}
+func (noopSpan) Finish()                              {}
+func (noopSpan) TraceID() string                      { return "" }
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryTracer(t *testing.T) {
+	t.Run("Child span", func(t *testing.T) {
+		tracer := NewMemoryTracer()
+
+		parent, ctx := tracer.StartSpan(context.Background(), "http.request", WithKind(KindServer),
+			WithResource("GET /api/v2/products/{id}"), WithTag(TagHTTPStatusCode, 200))
+		child, _ := tracer.StartSpan(ctx, "sql.query", WithKind(KindClient), WithTag(TagDBSystem, "mysql"))
+		child.SetError(errors.New("connection refused"))
+		child.Finish()
+		parent.Finish()
+
+		spans := tracer.Spans("")
+		assert.Len(t, spans, 2)
+		assert.Equal(t, "sql.query", spans[0].Operation)
+		assert.Equal(t, KindClient, spans[0].Kind)
+		assert.Equal(t, "connection refused", spans[0].Error)
+		assert.Equal(t, "mysql", spans[0].Tags[TagDBSystem])
+		assert.Equal(t, spans[1].SpanID, spans[0].ParentID)
+		assert.Equal(t, spans[1].TraceID, spans[0].TraceID)
+
+		assert.Equal(t, "GET /api/v2/products/{id}", spans[1].Resource)
+		assert.Equal(t, KindServer, spans[1].Kind)
+		assert.Equal(t, int64(200), spans[1].Tags[TagHTTPStatusCode])
+		assert.Empty(t, spans[1].ParentID)
+		assert.Empty(t, spans[1].Error)
+
+		span, ok := tracer.SpanFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, spans[1].TraceID, span.TraceID())
+
+		tracer.Reset()
+		assert.Empty(t, tracer.Spans(""))
+	})
+
+	t.Run("W3C propagation", func(t *testing.T) {
+		tracer := NewMemoryTracer()
+
+		span, ctx := tracer.StartSpan(context.Background(), "kafka.publish", WithKind(KindProducer))
+		header := http.Header{}
+		tracer.Inject(ctx, HeaderCarrier(header))
+		span.Finish()
+		assert.Regexp(t, `^00-`+span.TraceID()+`-[0-9a-f]{16}-01$`, header.Get("traceparent"))
+
+		remote, _ := tracer.StartSpan(tracer.Extract(context.Background(), HeaderCarrier(header)), "kafka.consume")
+		remote.Finish()
+		spans := tracer.Spans("kafka.consume")
+		assert.Len(t, spans, 1)
+		assert.Equal(t, span.TraceID(), spans[0].TraceID)
+		assert.Equal(t, tracer.Spans("kafka.publish")[0].SpanID, spans[0].ParentID)
+	})
+
+	t.Run("No span", func(t *testing.T) {
+		tracer := NewMemoryTracer()
+		_, ok := tracer.SpanFromContext(context.Background())
+		assert.False(t, ok)
+
+		carrier := MapCarrier{}
+		tracer.Inject(context.Background(), carrier)
+		assert.Empty(t, carrier)
+	})
+}
+
+func TestGlobalTracer(t *testing.T) {
+	defer SetTracer(nil)
+
+	span, ctx := StartSpan(context.Background(), "noop")
+	assert.Empty(t, span.TraceID())
+	assert.Empty(t, TraceID(ctx))
+
+	tracer := NewMemoryTracer()
+	SetTracer(tracer)
+	func() (err error) {
+		span, ctx = StartSpan(context.Background(), "global")
+		assert.NotEmpty(t, TraceID(ctx))
+		defer FinishWithError(span, &err)
+		return errors.New("failed")
+	}()
+	assert.Equal(t, "failed", tracer.Spans("global")[0].Error)
+}
+
+func TestSampleRate(t *testing.T) {
+	assert.Equal(t, 1.0, sampleRate(0))
+	assert.Equal(t, 1.0, sampleRate(2))
+	assert.Equal(t, 0.25, sampleRate(0.25))
+}